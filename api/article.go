@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JonathanWThom/feedme/markup"
+	"golang.org/x/net/html/charset"
+)
+
+// FetchArticle downloads the page at pageURL and extracts its main content
+func FetchArticle(pageURL string) (*markup.Document, error) {
+	client := &http.Client{Timeout: httpTimeout(15 * time.Second)}
+	resp, err := doRequest(client, pageURL, rssUserAgent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return markup.ExtractArticle(body, resp.Request.URL.String())
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchArticle(t *testing.T) {
+	// "Café" in ISO-8859-1
+	page := "<html><head><title>Caf\xe9 review</title></head><body><article>" +
+		"<p>The coffee was excellent, the pastries were fresh, and the staff were friendly.</p>" +
+		"<p>See <a href=\"/menu\">the menu</a>.</p></article></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/review" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	doc, err := FetchArticle(server.URL + "/review")
+	if err != nil {
+		t.Fatalf("FetchArticle: %v", err)
+	}
+	if doc.Title != "Café review" {
+		t.Errorf("title = %q, want %q", doc.Title, "Café review")
+	}
+	if len(doc.Blocks) != 2 || !strings.HasPrefix(doc.Blocks[0].Spans[0].Text, "The coffee") {
+		t.Fatalf("blocks = %+v", doc.Blocks)
+	}
+	if url := doc.Blocks[1].Spans[1].URL; url != server.URL+"/menu" {
+		t.Errorf("link = %q, want %q", url, server.URL+"/menu")
+	}
+
+	if _, err := FetchArticle(server.URL + "/missing"); err == nil {
+		t.Error("FetchArticle of a missing page succeeded")
+	}
+}
@@ -1,17 +1,26 @@
 package api
 
 import (
+	"container/list"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 )
 
+// defaultCacheCapacity bounds how many stories a CachedSource remembers
+// across feeds before evicting the least recently used.
+const defaultCacheCapacity = 1000
+
 // CachedSource provides throttling and in-memory story caching
 // shared by sources that fetch stories in bulk (Lobsters, Reddit).
+// Items are keyed by Item.Key and accumulate across feeds, so a key
+// obtained from one feed stays valid after switching to another.
 type CachedSource struct {
-	storyCache  map[int]*Item
-	cacheMu     sync.RWMutex
+	storyCache  map[string]*list.Element
+	recency     *list.List // front is most recently stored or fetched
+	capacity    int
+	cacheMu     sync.Mutex
 	lastRequest time.Time
 	requestMu   sync.Mutex
 	minDelay    time.Duration
@@ -20,7 +29,9 @@ type CachedSource struct {
 // NewCachedSource creates a CachedSource with the given throttle delay.
 func NewCachedSource(minDelay time.Duration) CachedSource {
 	return CachedSource{
-		storyCache: make(map[int]*Item),
+		storyCache: make(map[string]*list.Element),
+		recency:    list.New(),
+		capacity:   defaultCacheCapacity,
 		minDelay:   minDelay,
 	}
 }
@@ -37,43 +48,57 @@ func (c *CachedSource) Throttle() {
 	c.lastRequest = time.Now()
 }
 
-// StoreItems clears the cache and stores items with 1-indexed pseudo-IDs.
-// Returns the generated IDs.
-func (c *CachedSource) StoreItems(items []*Item) []int {
-	ids := make([]int, len(items))
+// StoreItems adds items to the cache under their keys, replacing stale
+// copies of items seen before and evicting the least recently used items
+// beyond capacity. Returns the keys in order.
+func (c *CachedSource) StoreItems(items []*Item) []string {
+	keys := make([]string, len(items))
 	c.cacheMu.Lock()
-	c.storyCache = make(map[int]*Item)
+	defer c.cacheMu.Unlock()
+
 	for i, item := range items {
-		id := i + 1
-		c.storyCache[id] = item
-		ids[i] = id
+		keys[i] = item.Key
+		if elem, ok := c.storyCache[item.Key]; ok {
+			elem.Value = item
+			c.recency.MoveToFront(elem)
+			continue
+		}
+		c.storyCache[item.Key] = c.recency.PushFront(item)
+	}
+
+	for c.recency.Len() > max(c.capacity, len(items)) {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.storyCache, oldest.Value.(*Item).Key)
 	}
-	c.cacheMu.Unlock()
-	return ids
+	return keys
 }
 
-// FetchItem fetches a cached item by pseudo-ID.
-func (c *CachedSource) FetchItem(id int) (*Item, error) {
-	c.cacheMu.RLock()
-	item, ok := c.storyCache[id]
-	c.cacheMu.RUnlock()
+// FetchItem fetches a cached item by key.
+func (c *CachedSource) FetchItem(key string) (*Item, error) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
 
+	elem, ok := c.storyCache[key]
 	if !ok {
-		return nil, fmt.Errorf("item %d not found in cache", id)
+		return nil, fmt.Errorf("item %s not found in cache", key)
 	}
-	return item, nil
+	c.recency.MoveToFront(elem)
+	return elem.Value.(*Item), nil
 }
 
-// FetchItems fetches multiple cached items by pseudo-ID.
-func (c *CachedSource) FetchItems(ids []int) ([]*Item, error) {
-	items := make([]*Item, len(ids))
-	c.cacheMu.RLock()
-	for i, id := range ids {
-		if item, ok := c.storyCache[id]; ok {
-			items[i] = item
+// FetchItems fetches multiple cached items by key. Missing items are nil.
+func (c *CachedSource) FetchItems(keys []string) ([]*Item, error) {
+	items := make([]*Item, len(keys))
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	for i, key := range keys {
+		if elem, ok := c.storyCache[key]; ok {
+			c.recency.MoveToFront(elem)
+			items[i] = elem.Value.(*Item)
 		}
 	}
-	c.cacheMu.RUnlock()
 	return items, nil
 }
 
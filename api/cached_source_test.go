@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -8,24 +9,24 @@ import (
 func TestCachedSource_StoreAndFetchItem(t *testing.T) {
 	cs := NewCachedSource(500 * time.Millisecond)
 
-	item := &Item{ID: 42, Title: "Test Story"}
+	item := &Item{Key: "abc123", Title: "Test Story"}
 	cs.StoreItems([]*Item{item})
 
-	got, err := cs.FetchItem(1)
+	got, err := cs.FetchItem("abc123")
 	if err != nil {
-		t.Fatalf("FetchItem(1) unexpected error: %v", err)
+		t.Fatalf("FetchItem(abc123) unexpected error: %v", err)
 	}
 	if got.Title != "Test Story" {
-		t.Errorf("FetchItem(1).Title = %q, want %q", got.Title, "Test Story")
+		t.Errorf("FetchItem(abc123).Title = %q, want %q", got.Title, "Test Story")
 	}
 }
 
 func TestCachedSource_FetchItemNotFound(t *testing.T) {
 	cs := NewCachedSource(500 * time.Millisecond)
 
-	_, err := cs.FetchItem(999)
+	_, err := cs.FetchItem("missing")
 	if err == nil {
-		t.Error("FetchItem(999) expected error, got nil")
+		t.Error("FetchItem(missing) expected error, got nil")
 	}
 }
 
@@ -33,18 +34,18 @@ func TestCachedSource_FetchItems(t *testing.T) {
 	cs := NewCachedSource(500 * time.Millisecond)
 
 	items := []*Item{
-		{ID: 1, Title: "First"},
-		{ID: 2, Title: "Second"},
-		{ID: 3, Title: "Third"},
+		{Key: "a", Title: "First"},
+		{Key: "b", Title: "Second"},
+		{Key: "c", Title: "Third"},
 	}
 	cs.StoreItems(items)
 
-	got, err := cs.FetchItems([]int{1, 3})
+	got, err := cs.FetchItems([]string{"a", "c", "zzz"})
 	if err != nil {
 		t.Fatalf("FetchItems unexpected error: %v", err)
 	}
-	if len(got) != 2 {
-		t.Fatalf("FetchItems returned %d items, want 2", len(got))
+	if len(got) != 3 {
+		t.Fatalf("FetchItems returned %d items, want 3", len(got))
 	}
 	if got[0].Title != "First" {
 		t.Errorf("got[0].Title = %q, want %q", got[0].Title, "First")
@@ -52,36 +53,105 @@ func TestCachedSource_FetchItems(t *testing.T) {
 	if got[1].Title != "Third" {
 		t.Errorf("got[1].Title = %q, want %q", got[1].Title, "Third")
 	}
+	if got[2] != nil {
+		t.Errorf("got[2] = %v, want nil for unknown key", got[2])
+	}
 }
 
-func TestCachedSource_StoreItemsReturnsIDs(t *testing.T) {
+func TestCachedSource_StoreItemsReturnsKeys(t *testing.T) {
 	cs := NewCachedSource(500 * time.Millisecond)
 
 	items := []*Item{
-		{Title: "A"},
-		{Title: "B"},
+		{Key: "t3_x", Title: "A"},
+		{Key: "t3_y", Title: "B"},
 	}
 	ids := cs.StoreItems(items)
 
 	if len(ids) != 2 {
 		t.Fatalf("StoreItems returned %d ids, want 2", len(ids))
 	}
-	if ids[0] != 1 || ids[1] != 2 {
-		t.Errorf("StoreItems ids = %v, want [1 2]", ids)
+	if ids[0] != "t3_x" || ids[1] != "t3_y" {
+		t.Errorf("StoreItems ids = %v, want [t3_x t3_y]", ids)
+	}
+}
+
+func TestCachedSource_StoreItemsReplacesStaleCopy(t *testing.T) {
+	cs := NewCachedSource(500 * time.Millisecond)
+
+	cs.StoreItems([]*Item{{Key: "a", Title: "Old", Score: 1}})
+	cs.StoreItems([]*Item{{Key: "a", Title: "New", Score: 5}})
+
+	got, err := cs.FetchItem("a")
+	if err != nil {
+		t.Fatalf("FetchItem(a) unexpected error: %v", err)
+	}
+	if got.Title != "New" || got.Score != 5 {
+		t.Errorf("FetchItem(a) = %q/%d, want refreshed copy New/5", got.Title, got.Score)
 	}
 }
 
-func TestCachedSource_StoreItemsClearsOldCache(t *testing.T) {
+func TestCachedSource_CrossFeedLookup(t *testing.T) {
 	cs := NewCachedSource(500 * time.Millisecond)
 
-	cs.StoreItems([]*Item{{Title: "Old"}})
-	cs.StoreItems([]*Item{{Title: "New"}})
+	hot := cs.StoreItems([]*Item{{Key: "hot1", Title: "Hot 1"}, {Key: "shared", Title: "Shared"}})
+	newest := cs.StoreItems([]*Item{{Key: "new1", Title: "New 1"}, {Key: "shared", Title: "Shared"}})
 
-	got, err := cs.FetchItem(1)
+	// A key from the Hot feed still refers to the same story after
+	// switching to New, rather than whatever story is now at that position
+	got, err := cs.FetchItem(hot[0])
 	if err != nil {
-		t.Fatalf("FetchItem(1) unexpected error: %v", err)
+		t.Fatalf("FetchItem(%s) after feed switch unexpected error: %v", hot[0], err)
+	}
+	if got.Title != "Hot 1" {
+		t.Errorf("FetchItem(%s).Title = %q, want %q", hot[0], got.Title, "Hot 1")
+	}
+	if hot[1] != newest[1] {
+		t.Errorf("shared story keys differ across feeds: %q vs %q", hot[1], newest[1])
 	}
-	if got.Title != "New" {
-		t.Errorf("FetchItem(1).Title = %q, want %q (old cache not cleared)", got.Title, "New")
+
+	items, _ := cs.FetchItems(append(hot, newest...))
+	for i, item := range items {
+		if item == nil {
+			t.Errorf("FetchItems: item %d missing after feed switch", i)
+		}
+	}
+}
+
+func TestCachedSource_EvictsLeastRecentlyUsed(t *testing.T) {
+	cs := NewCachedSource(500 * time.Millisecond)
+	cs.capacity = 3
+
+	cs.StoreItems([]*Item{{Key: "a"}, {Key: "b"}, {Key: "c"}})
+	// Touch "a" so "b" becomes the least recently used
+	if _, err := cs.FetchItem("a"); err != nil {
+		t.Fatalf("FetchItem(a) unexpected error: %v", err)
+	}
+	cs.StoreItems([]*Item{{Key: "d"}})
+
+	if _, err := cs.FetchItem("b"); err == nil {
+		t.Error("FetchItem(b) expected eviction, still cached")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, err := cs.FetchItem(key); err != nil {
+			t.Errorf("FetchItem(%s) unexpected error: %v", key, err)
+		}
+	}
+}
+
+func TestCachedSource_KeepsOversizedBatch(t *testing.T) {
+	cs := NewCachedSource(500 * time.Millisecond)
+	cs.capacity = 2
+
+	var items []*Item
+	for i := 0; i < 5; i++ {
+		items = append(items, &Item{Key: fmt.Sprint(i)})
+	}
+	keys := cs.StoreItems(items)
+
+	got, _ := cs.FetchItems(keys)
+	for i, item := range got {
+		if item == nil {
+			t.Errorf("item %d evicted from the batch that was just stored", i)
+		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -23,15 +24,21 @@ var FeedNames = []string{FeedTop, FeedNew, FeedBest, FeedAsk, FeedShow}
 
 // Client is the HN API client
 type Client struct {
-	http *http.Client
+	http          *http.Client
+	baseURL       string
+	algoliaURL    string
+	commentLoader CommentLoader
 }
 
 // NewClient creates a new HN API client
 func NewClient() *Client {
 	return &Client{
 		http: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: httpTimeout(10 * time.Second),
 		},
+		baseURL:       baseURL,
+		algoliaURL:    algoliaBaseURL,
+		commentLoader: CommentLoaderFirebase,
 	}
 }
 
@@ -40,6 +47,11 @@ func (c *Client) Name() string {
 	return "HN"
 }
 
+// Spec returns the source specification for ParseSource
+func (c *Client) Spec() string {
+	return "hn"
+}
+
 // FeedNames returns the available feed names
 func (c *Client) FeedNames() []string {
 	return FeedNames
@@ -56,8 +68,8 @@ func (c *Client) StoryURL(item *Item) string {
 }
 
 // FetchStoryIDs fetches the list of story IDs for a given feed
-func (c *Client) FetchStoryIDs(feed string) ([]int, error) {
-	url := fmt.Sprintf("%s/%s.json", baseURL, feed)
+func (c *Client) FetchStoryIDs(feed string) ([]string, error) {
+	url := fmt.Sprintf("%s/%s.json", c.baseURL, feed)
 	resp, err := c.http.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch %s: %w", feed, err)
@@ -69,12 +81,37 @@ func (c *Client) FetchStoryIDs(feed string) ([]int, error) {
 		return nil, fmt.Errorf("failed to decode %s: %w", feed, err)
 	}
 
-	return ids, nil
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = strconv.Itoa(id)
+	}
+	return keys, nil
 }
 
-// FetchItem fetches a single item by ID
-func (c *Client) FetchItem(id int) (*Item, error) {
-	url := fmt.Sprintf("%s/item/%d.json", baseURL, id)
+// FetchItem fetches a single item by its numeric ID key
+func (c *Client) FetchItem(key string) (*Item, error) {
+	id, err := strconv.Atoi(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HN item ID %q", key)
+	}
+	return c.fetchItem(id)
+}
+
+// FetchItems fetches multiple items concurrently by their numeric ID keys
+func (c *Client) FetchItems(keys []string) ([]*Item, error) {
+	ids := make([]int, len(keys))
+	for i, key := range keys {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HN item ID %q", key)
+		}
+		ids[i] = id
+	}
+	return c.fetchItems(ids)
+}
+
+func (c *Client) fetchItem(id int) (*Item, error) {
+	url := fmt.Sprintf("%s/item/%d.json", c.baseURL, id)
 	resp, err := c.http.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch item %d: %w", id, err)
@@ -85,12 +122,12 @@ func (c *Client) FetchItem(id int) (*Item, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
 		return nil, fmt.Errorf("failed to decode item %d: %w", id, err)
 	}
+	item.Key = strconv.Itoa(id)
 
 	return &item, nil
 }
 
-// FetchItems fetches multiple items concurrently
-func (c *Client) FetchItems(ids []int) ([]*Item, error) {
+func (c *Client) fetchItems(ids []int) ([]*Item, error) {
 	items := make([]*Item, len(ids))
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -106,7 +143,7 @@ func (c *Client) FetchItems(ids []int) ([]*Item, error) {
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			item, err := c.FetchItem(itemID)
+			item, err := c.fetchItem(itemID)
 			mu.Lock()
 			if err != nil && firstErr == nil {
 				firstErr = err
@@ -129,7 +166,7 @@ func (c *Client) FetchComments(item *Item) ([]*Item, error) {
 	}
 
 	var allComments []*Item
-	comments, err := c.FetchItems(item.Kids)
+	comments, err := c.fetchItems(item.Kids)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +183,18 @@ func (c *Client) FetchComments(item *Item) ([]*Item, error) {
 
 // FetchCommentTree fetches the full comment tree for a story
 func (c *Client) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
-	return c.fetchCommentsRecursive(item.Kids, 0, maxDepth)
+	if c.commentLoader != CommentLoaderAlgolia {
+		return c.fetchCommentsRecursive(item.Kids, 0, maxDepth)
+	}
+	comments, algoliaErr := c.fetchAlgoliaCommentTree(item, maxDepth)
+	if algoliaErr == nil {
+		return comments, nil
+	}
+	comments, err := c.fetchCommentsRecursive(item.Kids, 0, maxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("%w (after Algolia failed: %v)", err, algoliaErr)
+	}
+	return comments, nil
 }
 
 func (c *Client) fetchCommentsRecursive(ids []int, depth, maxDepth int) ([]*Comment, error) {
@@ -154,7 +202,7 @@ func (c *Client) fetchCommentsRecursive(ids []int, depth, maxDepth int) ([]*Comm
 		return nil, nil
 	}
 
-	items, err := c.FetchItems(ids)
+	items, err := c.fetchItems(ids)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,18 @@ import (
 	"time"
 )
 
+// HTTPTimeout overrides the request timeout of clients created after it
+// is set. Zero keeps each source's default.
+var HTTPTimeout time.Duration
+
+// httpTimeout returns HTTPTimeout if set, or the source's default
+func httpTimeout(def time.Duration) time.Duration {
+	if HTTPTimeout > 0 {
+		return HTTPTimeout
+	}
+	return def
+}
+
 // hashShortID creates a pseudo-numeric ID from a short ID string
 func hashShortID(shortID string) int {
 	hash := 0
@@ -23,7 +35,13 @@ func parseTime(s string) (time.Time, error) {
 		"2006-01-02 15:04:05 -0700",
 		"2006-01-02T15:04:05Z",
 		"2006-01-02T15:04:05-07:00",
+		"2006-01-02T15:04:05",
 		time.RFC3339,
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
 	}
 	for _, format := range formats {
 		if t, err := time.Parse(format, s); err == nil {
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CommentLoader selects how the HN client fetches comment trees
+type CommentLoader string
+
+const (
+	// CommentLoaderFirebase walks the tree one item request at a time
+	CommentLoaderFirebase CommentLoader = "firebase"
+	// CommentLoaderAlgolia fetches the whole tree in a single request,
+	// falling back to Firebase on failure
+	CommentLoaderAlgolia CommentLoader = "algolia"
+)
+
+// ParseCommentLoader validates a comment loader name
+func ParseCommentLoader(name string) (CommentLoader, error) {
+	switch loader := CommentLoader(strings.ToLower(name)); loader {
+	case CommentLoaderFirebase, CommentLoaderAlgolia:
+		return loader, nil
+	}
+	return "", fmt.Errorf("unknown comment loader %q (want firebase or algolia)", name)
+}
+
+// SetCommentLoader changes how this client fetches comment trees
+func (c *Client) SetCommentLoader(loader CommentLoader) {
+	c.commentLoader = loader
+}
+
+// SetCommentLoader changes how source fetches comment trees if it loads
+// them from HN, and leaves other sources alone
+func SetCommentLoader(source Source, loader CommentLoader) {
+	if s, ok := source.(interface{ SetCommentLoader(CommentLoader) }); ok {
+		s.SetCommentLoader(loader)
+	}
+}
+
+// algoliaItem represents a node of the Algolia items/<id> tree
+type algoliaItem struct {
+	ID         int            `json:"id"`
+	Type       string         `json:"type"`
+	Author     string         `json:"author"`
+	Text       string         `json:"text"`
+	Points     int            `json:"points"`
+	CreatedAtI int64          `json:"created_at_i"`
+	ParentID   int            `json:"parent_id"`
+	Children   []*algoliaItem `json:"children"`
+}
+
+// fetchAlgoliaCommentTree fetches a story's entire comment tree at once
+func (c *Client) fetchAlgoliaCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
+	url := fmt.Sprintf("%s/items/%d", c.algoliaURL, item.ID)
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comment tree %d: %w", item.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch comment tree %d: HTTP %d", item.ID, resp.StatusCode)
+	}
+
+	var root algoliaItem
+	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to decode comment tree %d: %w", item.ID, err)
+	}
+
+	comments := algoliaToComments(root.Children, 0, maxDepth)
+	sortByKids(comments, item.Kids)
+	return comments, nil
+}
+
+// algoliaToComments converts Algolia nodes, dropping deleted comments
+// and their replies the same way the Firebase recursion does
+func algoliaToComments(nodes []*algoliaItem, depth, maxDepth int) []*Comment {
+	if len(nodes) == 0 || (maxDepth > 0 && depth >= maxDepth) {
+		return nil
+	}
+
+	var comments []*Comment
+	for _, node := range nodes {
+		if node == nil || node.Author == "" {
+			continue
+		}
+		comments = append(comments, &Comment{
+			Item: &Item{
+				Key:    strconv.Itoa(node.ID),
+				ID:     node.ID,
+				Type:   "comment",
+				By:     node.Author,
+				Text:   node.Text,
+				Score:  node.Points,
+				Time:   node.CreatedAtI,
+				Parent: node.ParentID,
+			},
+			Depth:    depth,
+			Children: algoliaToComments(node.Children, depth+1, maxDepth),
+		})
+	}
+	return comments
+}
+
+// sortByKids orders top-level comments to match HN's ranking in kids.
+// Algolia returns them in its own order.
+func sortByKids(comments []*Comment, kids []int) {
+	if len(kids) == 0 {
+		return
+	}
+	rank := make(map[int]int, len(kids))
+	for i, id := range kids {
+		rank[id] = i
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		ri, ok := rank[comments[i].ID]
+		if !ok {
+			ri = len(kids)
+		}
+		rj, ok := rank[comments[j].ID]
+		if !ok {
+			rj = len(kids)
+		}
+		return ri < rj
+	})
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// hnTree is a synthetic HN story served through both the Firebase and
+// Algolia endpoints
+type hnTree struct {
+	items    map[int]map[string]any
+	algolia  *algoliaItem
+	requests atomic.Int64
+}
+
+// newHNTree builds a story with fanout replies per comment down to depth
+func newHNTree(fanout, depth int) *hnTree {
+	tree := &hnTree{items: make(map[int]map[string]any)}
+	nextID := 2
+
+	var build func(parent, level int) ([]int, []*algoliaItem)
+	build = func(parent, level int) ([]int, []*algoliaItem) {
+		if level >= depth {
+			return nil, nil
+		}
+		var kids []int
+		var nodes []*algoliaItem
+		for i := 0; i < fanout; i++ {
+			id := nextID
+			nextID++
+			childKids, childNodes := build(id, level+1)
+			author := "user" + strconv.Itoa(id)
+			tree.items[id] = map[string]any{
+				"id": id, "type": "comment", "by": author, "text": "comment " + strconv.Itoa(id),
+				"parent": parent, "kids": childKids, "time": 1700000000 + id,
+			}
+			kids = append(kids, id)
+			nodes = append(nodes, &algoliaItem{
+				ID: id, Type: "comment", Author: author, Text: "comment " + strconv.Itoa(id),
+				ParentID: parent, CreatedAtI: int64(1700000000 + id), Children: childNodes,
+			})
+		}
+		return kids, nodes
+	}
+
+	kids, nodes := build(1, 0)
+	tree.items[1] = map[string]any{"id": 1, "type": "story", "title": "story", "kids": kids}
+	tree.algolia = &algoliaItem{ID: 1, Type: "story", Children: nodes}
+	return tree
+}
+
+func (tree *hnTree) serve(t testing.TB, algoliaStatus int) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tree.requests.Add(1)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/item/"):
+			id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/item/"), ".json"))
+			json.NewEncoder(w).Encode(tree.items[id])
+		case strings.HasPrefix(r.URL.Path, "/items/"):
+			if algoliaStatus != http.StatusOK {
+				w.WriteHeader(algoliaStatus)
+				return
+			}
+			json.NewEncoder(w).Encode(tree.algolia)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	c.baseURL = srv.URL
+	c.algoliaURL = srv.URL
+	return c
+}
+
+func (tree *hnTree) story() *Item {
+	kids, _ := tree.items[1]["kids"].([]int)
+	return &Item{ID: 1, Type: "story", Kids: kids}
+}
+
+func countComments(comments []*Comment) int {
+	n := len(comments)
+	for _, c := range comments {
+		n += countComments(c.Children)
+	}
+	return n
+}
+
+func TestParseCommentLoader(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    CommentLoader
+		wantErr bool
+	}{
+		{"firebase", CommentLoaderFirebase, false},
+		{"Algolia", CommentLoaderAlgolia, false},
+		{"", "", true},
+		{"graphql", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCommentLoader(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCommentLoader(%q) = %q, %v; want %q, err %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestClient_AlgoliaCommentTree(t *testing.T) {
+	tree := newHNTree(3, 3)
+	c := tree.serve(t, http.StatusOK)
+	c.SetCommentLoader(CommentLoaderAlgolia)
+
+	comments, err := c.FetchCommentTree(tree.story(), 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if got := countComments(comments); got != 39 {
+		t.Errorf("got %d comments, want 39", got)
+	}
+	if got := tree.requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+
+	leaf := comments[0].Children[0].Children[0]
+	if leaf.Depth != 2 || leaf.By == "" || leaf.Time == 0 {
+		t.Errorf("leaf = %+v depth %d", leaf.Item, leaf.Depth)
+	}
+}
+
+func TestClient_AlgoliaCommentTreeMatchesFirebase(t *testing.T) {
+	tree := newHNTree(2, 3)
+	algolia := tree.serve(t, http.StatusOK)
+	algolia.SetCommentLoader(CommentLoaderAlgolia)
+	firebase := tree.serve(t, http.StatusOK)
+
+	want, err := firebase.FetchCommentTree(tree.story(), 0)
+	if err != nil {
+		t.Fatalf("firebase FetchCommentTree unexpected error: %v", err)
+	}
+	got, err := algolia.FetchCommentTree(tree.story(), 0)
+	if err != nil {
+		t.Fatalf("algolia FetchCommentTree unexpected error: %v", err)
+	}
+
+	var compare func(got, want []*Comment)
+	compare = func(got, want []*Comment) {
+		if len(got) != len(want) {
+			t.Fatalf("got %d comments, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i].ID != want[i].ID || got[i].By != want[i].By || got[i].Depth != want[i].Depth {
+				t.Errorf("comment %d = %d/%s/%d, want %d/%s/%d", i,
+					got[i].ID, got[i].By, got[i].Depth, want[i].ID, want[i].By, want[i].Depth)
+			}
+			compare(got[i].Children, want[i].Children)
+		}
+	}
+	compare(got, want)
+}
+
+func TestClient_AlgoliaCommentTreeFallback(t *testing.T) {
+	tree := newHNTree(2, 2)
+	c := tree.serve(t, http.StatusInternalServerError)
+	c.SetCommentLoader(CommentLoaderAlgolia)
+
+	comments, err := c.FetchCommentTree(tree.story(), 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if got := countComments(comments); got != 6 {
+		t.Errorf("got %d comments after fallback, want 6", got)
+	}
+}
+
+func TestClient_AlgoliaCommentTreeFallbackError(t *testing.T) {
+	tree := newHNTree(2, 1)
+	c := tree.serve(t, http.StatusServiceUnavailable)
+	c.SetCommentLoader(CommentLoaderAlgolia)
+	c.baseURL = "http://127.0.0.1:1"
+
+	_, err := c.FetchCommentTree(tree.story(), 0)
+	if err == nil || !strings.Contains(err.Error(), "Algolia failed") || !strings.Contains(err.Error(), "HTTP 503") {
+		t.Errorf("err = %v, want the Firebase error with Algolia's", err)
+	}
+}
+
+func TestSetCommentLoader(t *testing.T) {
+	search := NewHNSearchSource("go")
+	SetCommentLoader(search, CommentLoaderAlgolia)
+	if search.hn.commentLoader != CommentLoaderAlgolia {
+		t.Errorf("search source loader = %q, want algolia", search.hn.commentLoader)
+	}
+	if NewClient().commentLoader != CommentLoaderFirebase {
+		t.Error("new clients don't default to the Firebase loader")
+	}
+	// Sources that don't load from HN are left alone
+	SetCommentLoader(NewLobstersClient(), CommentLoaderAlgolia)
+}
+
+func TestAlgoliaToComments(t *testing.T) {
+	nodes := []*algoliaItem{
+		{ID: 3, Author: "b", Text: "second", Children: []*algoliaItem{{ID: 5, Author: "c"}}},
+		{ID: 2, Author: "a", Text: "first"},
+		{ID: 4, Text: "", Children: []*algoliaItem{{ID: 6, Author: "orphan"}}},
+	}
+
+	comments := algoliaToComments(nodes, 0, 0)
+	sortByKids(comments, []int{2, 3, 4})
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2 (deleted dropped)", len(comments))
+	}
+	if comments[0].ID != 2 || comments[1].ID != 3 {
+		t.Errorf("order = %d,%d; want 2,3 from kids", comments[0].ID, comments[1].ID)
+	}
+	if len(comments[1].Children) != 1 || comments[1].Children[0].Depth != 1 {
+		t.Errorf("children = %v", comments[1].Children)
+	}
+
+	if limited := algoliaToComments(nodes, 0, 1); len(limited[0].Children) != 0 {
+		t.Errorf("maxDepth 1 kept children: %v", limited[0].Children)
+	}
+}
+
+func benchmarkCommentTree(b *testing.B, loader CommentLoader) {
+	// 5 + 25 + 125 + 625 = 780 comments
+	tree := newHNTree(5, 4)
+	c := tree.serve(b, http.StatusOK)
+	c.SetCommentLoader(loader)
+	story := tree.story()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.FetchCommentTree(story, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCommentTreeFirebase(b *testing.B) {
+	benchmarkCommentTree(b, CommentLoaderFirebase)
+}
+
+func BenchmarkCommentTreeAlgolia(b *testing.B) {
+	benchmarkCommentTree(b, CommentLoaderAlgolia)
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const algoliaBaseURL = "https://hn.algolia.com/api/v1"
+
+// HN search feed types
+const (
+	HNSearchFeedRelevance = "relevance"
+	HNSearchFeedDate      = "date"
+	HNSearchFeedDay       = "day"
+	HNSearchFeedWeek      = "week"
+	HNSearchFeedMonth     = "month"
+)
+
+var HNSearchFeedNames = []string{HNSearchFeedRelevance, HNSearchFeedDate, HNSearchFeedDay, HNSearchFeedWeek, HNSearchFeedMonth}
+var HNSearchFeedLabels = []string{"Relevance", "Date", "Past day", "Past week", "Past month"}
+
+// hnSearchWindows maps time-window feeds to how far back they reach
+var hnSearchWindows = map[string]time.Duration{
+	HNSearchFeedDay:   24 * time.Hour,
+	HNSearchFeedWeek:  7 * 24 * time.Hour,
+	HNSearchFeedMonth: 30 * 24 * time.Hour,
+}
+
+// HNSearchSource searches HN stories through the Algolia API. Items keep
+// their real HN IDs so comments come from the regular HN client.
+type HNSearchSource struct {
+	CachedSource
+	http       *http.Client
+	hn         *Client
+	algoliaURL string
+	query      string
+}
+
+// NewHNSearchSource creates a search source for the given query
+func NewHNSearchSource(query string) *HNSearchSource {
+	return &HNSearchSource{
+		CachedSource: NewCachedSource(0),
+		http: &http.Client{
+			Timeout: httpTimeout(10 * time.Second),
+		},
+		hn:         NewClient(),
+		algoliaURL: algoliaBaseURL,
+		query:      query,
+	}
+}
+
+// Name returns the display name of the source
+func (s *HNSearchSource) Name() string {
+	return fmt.Sprintf("HN: %s", s.query)
+}
+
+// Spec returns the source specification for ParseSource
+func (s *HNSearchSource) Spec() string {
+	return "hn?" + url.Values{"q": {s.query}}.Encode()
+}
+
+// FeedNames returns the available feed names
+func (s *HNSearchSource) FeedNames() []string {
+	return HNSearchFeedNames
+}
+
+// FeedLabels returns the display labels for feeds
+func (s *HNSearchSource) FeedLabels() []string {
+	return HNSearchFeedLabels
+}
+
+// StoryURL returns the URL for viewing a story on HN
+func (s *HNSearchSource) StoryURL(item *Item) string {
+	return s.hn.StoryURL(item)
+}
+
+// FetchStoryIDs runs the search and returns the matching HN story IDs
+func (s *HNSearchSource) FetchStoryIDs(feed string) ([]string, error) {
+	resp, err := s.search(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	stories := parseAlgoliaHits(resp)
+	if len(stories) == 0 {
+		return nil, fmt.Errorf("no stories found for %q", s.query)
+	}
+	return s.StoreItems(stories), nil
+}
+
+// FetchItem returns a search result, falling back to the HN API for
+// stories that were never part of a search
+func (s *HNSearchSource) FetchItem(id string) (*Item, error) {
+	if item, err := s.CachedSource.FetchItem(id); err == nil {
+		return item, nil
+	}
+	return s.hn.FetchItem(id)
+}
+
+// FetchItems returns search results by ID, fetching any that were never
+// part of a search from the HN API
+func (s *HNSearchSource) FetchItems(ids []string) ([]*Item, error) {
+	items, _ := s.CachedSource.FetchItems(ids)
+
+	var missing []string
+	for i, item := range items {
+		if item == nil {
+			missing = append(missing, ids[i])
+		}
+	}
+	if len(missing) == 0 {
+		return items, nil
+	}
+
+	fetched, err := s.hn.FetchItems(missing)
+	for i, j := 0, 0; i < len(items) && j < len(fetched); i++ {
+		if items[i] == nil {
+			items[i] = fetched[j]
+			j++
+		}
+	}
+	return items, err
+}
+
+// SetCommentLoader changes how comment trees are fetched from HN
+func (s *HNSearchSource) SetCommentLoader(loader CommentLoader) {
+	s.hn.SetCommentLoader(loader)
+}
+
+// FetchCommentTree loads the story from the HN API, since search hits do
+// not list their kids, and walks its comments
+func (s *HNSearchSource) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
+	story, err := s.hn.FetchItem(item.Key)
+	if err != nil {
+		return nil, err
+	}
+	return s.hn.FetchCommentTree(story, maxDepth)
+}
+
+func (s *HNSearchSource) search(feed string) (*algoliaSearchResponse, error) {
+	endpoint := "search"
+	if feed == HNSearchFeedDate {
+		endpoint = "search_by_date"
+	}
+
+	query := url.Values{}
+	query.Set("query", s.query)
+	query.Set("tags", "story")
+	query.Set("hitsPerPage", "100")
+	if window, ok := hnSearchWindows[feed]; ok {
+		since := time.Now().Add(-window).Unix()
+		query.Set("numericFilters", "created_at_i>"+strconv.FormatInt(since, 10))
+	}
+
+	u := fmt.Sprintf("%s/%s?%s", s.algoliaURL, endpoint, query.Encode())
+	resp, err := s.http.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search HN: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search HN: HTTP %d", resp.StatusCode)
+	}
+
+	var result algoliaSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+	return &result, nil
+}
+
+// algoliaSearchResponse represents the Algolia search response
+type algoliaSearchResponse struct {
+	Hits []algoliaHit `json:"hits"`
+}
+
+// algoliaHit represents a single story search hit
+type algoliaHit struct {
+	ObjectID    string `json:"objectID"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Author      string `json:"author"`
+	Points      int    `json:"points"`
+	NumComments int    `json:"num_comments"`
+	CreatedAtI  int64  `json:"created_at_i"`
+	StoryText   string `json:"story_text"`
+}
+
+// parseAlgoliaHits converts search hits to Items with real HN IDs
+func parseAlgoliaHits(resp *algoliaSearchResponse) []*Item {
+	var stories []*Item
+	for _, hit := range resp.Hits {
+		id, err := strconv.Atoi(hit.ObjectID)
+		if err != nil || hit.Title == "" {
+			continue
+		}
+		stories = append(stories, &Item{
+			Key:         hit.ObjectID,
+			ID:          id,
+			Type:        "story",
+			Title:       hit.Title,
+			URL:         hit.URL,
+			By:          hit.Author,
+			Score:       hit.Points,
+			Descendants: hit.NumComments,
+			Time:        hit.CreatedAtI,
+			Text:        hit.StoryText,
+		})
+	}
+	return stories
+}
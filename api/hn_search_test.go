@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHNSearchSource(t *testing.T, query string, requests chan<- *url.URL) *HNSearchSource {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", "algolia_search.json"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	items := map[string]any{
+		"39001234": map[string]any{"id": 39001234, "type": "story", "kids": []int{1, 2}},
+		"1":        map[string]any{"id": 1, "type": "comment", "by": "a", "text": "first", "kids": []int{3}},
+		"2":        map[string]any{"id": 2, "type": "comment", "deleted": true},
+		"3":        map[string]any{"id": 3, "type": "comment", "by": "b", "text": "reply"},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/search" || r.URL.Path == "/search_by_date":
+			if requests != nil {
+				requests <- r.URL
+			}
+			w.Write(fixture)
+		case strings.HasPrefix(r.URL.Path, "/item/"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/item/"), ".json")
+			json.NewEncoder(w).Encode(items[id])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewHNSearchSource(query)
+	s.algoliaURL = srv.URL
+	s.hn.baseURL = srv.URL
+	return s
+}
+
+func TestHNSearchSource_FetchStoryIDs(t *testing.T) {
+	s := newTestHNSearchSource(t, "golang generics", nil)
+
+	ids, err := s.FetchStoryIDs(HNSearchFeedRelevance)
+	if err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "39001234" || ids[1] != "39000001" {
+		t.Fatalf("FetchStoryIDs = %v, want real HN IDs [39001234 39000001]", ids)
+	}
+
+	items, _ := s.FetchItems(ids)
+	first := items[0]
+	if first.ID != 39001234 || first.Title != "Go generics: a retrospective" || first.By != "gopher" {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Score != 321 || first.Descendants != 2 || first.Time != 1705314600 {
+		t.Errorf("first score/comments/time = %d/%d/%d", first.Score, first.Descendants, first.Time)
+	}
+	if got := s.StoryURL(first); got != "https://news.ycombinator.com/item?id=39001234" {
+		t.Errorf("StoryURL = %q", got)
+	}
+	if items[1].URL != "" || items[1].Text != "Curious what people think." {
+		t.Errorf("ask story URL/Text = %q/%q", items[1].URL, items[1].Text)
+	}
+}
+
+func TestHNSearchSource_FeedParameters(t *testing.T) {
+	requests := make(chan *url.URL, 1)
+	s := newTestHNSearchSource(t, "golang generics", requests)
+
+	tests := []struct {
+		feed   string
+		path   string
+		window time.Duration
+	}{
+		{HNSearchFeedRelevance, "/search", 0},
+		{HNSearchFeedDate, "/search_by_date", 0},
+		{HNSearchFeedDay, "/search", 24 * time.Hour},
+		{HNSearchFeedWeek, "/search", 7 * 24 * time.Hour},
+		{HNSearchFeedMonth, "/search", 30 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.feed, func(t *testing.T) {
+			if _, err := s.FetchStoryIDs(tt.feed); err != nil {
+				t.Fatalf("FetchStoryIDs(%q) unexpected error: %v", tt.feed, err)
+			}
+			u := <-requests
+			if u.Path != tt.path {
+				t.Errorf("path = %q, want %q", u.Path, tt.path)
+			}
+			q := u.Query()
+			if q.Get("query") != "golang generics" || q.Get("tags") != "story" {
+				t.Errorf("query params = %v", q)
+			}
+
+			filter := q.Get("numericFilters")
+			if tt.window == 0 {
+				if filter != "" {
+					t.Errorf("numericFilters = %q, want none", filter)
+				}
+				return
+			}
+			since, err := strconv.ParseInt(strings.TrimPrefix(filter, "created_at_i>"), 10, 64)
+			if err != nil {
+				t.Fatalf("numericFilters = %q, want created_at_i>N", filter)
+			}
+			want := time.Now().Add(-tt.window).Unix()
+			if diff := want - since; diff < 0 || diff > 5 {
+				t.Errorf("numericFilters since = %d, want ~%d", since, want)
+			}
+		})
+	}
+}
+
+func TestHNSearchSource_FetchCommentTree(t *testing.T) {
+	s := newTestHNSearchSource(t, "golang", nil)
+	ids, err := s.FetchStoryIDs(HNSearchFeedRelevance)
+	if err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+	story, _ := s.FetchItem(ids[0])
+
+	comments, err := s.FetchCommentTree(story, 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if len(comments) != 1 || comments[0].By != "a" {
+		t.Fatalf("FetchCommentTree = %v, want one live top-level comment", comments)
+	}
+	if len(comments[0].Children) != 1 || comments[0].Children[0].Depth != 1 {
+		t.Errorf("children = %v, want one reply at depth 1", comments[0].Children)
+	}
+}
+
+func TestHNSearchSource_FetchItemsFallsBackToHN(t *testing.T) {
+	s := newTestHNSearchSource(t, "golang", nil)
+	if _, err := s.FetchStoryIDs(HNSearchFeedRelevance); err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+
+	items, err := s.FetchItems([]string{"39001234", "1"})
+	if err != nil {
+		t.Fatalf("FetchItems unexpected error: %v", err)
+	}
+	if items[0].Title != "Go generics: a retrospective" {
+		t.Errorf("items[0] = %+v, want cached search hit", items[0])
+	}
+	if items[1] == nil || items[1].Key != "1" || items[1].By != "a" {
+		t.Errorf("items[1] = %+v, want item 1 from the HN API", items[1])
+	}
+}
+
+func TestHNSearchSource_FetchItemsInvalidKey(t *testing.T) {
+	s := newTestHNSearchSource(t, "golang", nil)
+	if _, err := s.FetchStoryIDs(HNSearchFeedRelevance); err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+
+	items, err := s.FetchItems([]string{"39001234", "not-a-number"})
+	if err == nil {
+		t.Fatal("FetchItems with an invalid key returned no error")
+	}
+	if len(items) != 2 || items[0] == nil || items[1] != nil {
+		t.Errorf("items = %v, want the cached hit and a nil for the invalid key", items)
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 // Item represents a news item (story, comment, job, poll)
 type Item struct {
+	// Key is the item's stable, source-native identifier: the HN numeric
+	// ID, Lobsters short ID, Reddit fullname, and so on
+	Key         string `json:"key,omitempty"`
 	ID          int    `json:"id"`
 	Type        string `json:"type"`
 	By          string `json:"by"`
@@ -20,8 +23,33 @@ type Item struct {
 	Descendants int    `json:"descendants"`
 	Deleted     bool   `json:"deleted"`
 	Dead        bool   `json:"dead"`
+	// Format is the markup Text is written in
+	Format TextFormat `json:"format,omitempty"`
+	// Tags are the story's tags or flair
+	Tags []string `json:"tags,omitempty"`
+
+	// commentFeed is the URL of a feed entry's comment feed
+	commentFeed string
+}
+
+// HasCommentFeed reports whether the item is a feed entry advertising a
+// comment feed, which may have comments even when Descendants is 0
+func (i *Item) HasCommentFeed() bool {
+	return i.commentFeed != ""
 }
 
+// TextFormat is the markup language of an item's text
+type TextFormat int
+
+const (
+	// FormatHTML is used by Hacker News, Lobsters and feeds
+	FormatHTML TextFormat = iota
+	// FormatMarkdown is used by Reddit and Lemmy
+	FormatMarkdown
+	// FormatPlain is text without markup
+	FormatPlain
+)
+
 // TimeAgo returns a human-readable time ago string
 func (i *Item) TimeAgo() string {
 	d := time.Since(time.Unix(i.Time, 0))
@@ -86,4 +114,39 @@ type Comment struct {
 	*Item
 	Depth    int
 	Children []*Comment
+	// OP is set when the comment was written by the story's submitter
+	OP bool
+	// More is set on placeholder comments standing in for replies the
+	// source left out of the tree (see MoreCommentsLoader)
+	More *MoreComments
+}
+
+// MoreComments describes replies omitted from a comment tree
+type MoreComments struct {
+	// Count is the number of omitted comments, or 0 when unknown
+	Count int
+	// ParentKey is the key of the comment the replies belong under, or the
+	// story's key for top-level comments
+	ParentKey string
+	// Keys lists the omitted comments when the source provides them
+	Keys []string
+}
+
+// ReplaceComment swaps target for replacements wherever it appears in the
+// tree, returning the updated top-level comments and whether target was
+// found
+func ReplaceComment(roots []*Comment, target *Comment, replacements []*Comment) ([]*Comment, bool) {
+	for i, c := range roots {
+		if c == target {
+			spliced := make([]*Comment, 0, len(roots)-1+len(replacements))
+			spliced = append(spliced, roots[:i]...)
+			spliced = append(spliced, replacements...)
+			return append(spliced, roots[i+1:]...), true
+		}
+		if children, ok := ReplaceComment(c.Children, target, replacements); ok {
+			c.Children = children
+			return roots, true
+		}
+	}
+	return roots, false
 }
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const lemmyUserAgent = "feedme:v1.0 (terminal news reader)"
+const lemmyDefaultInstance = "lemmy.ml"
+
+// lemmyMaxDepth bounds comment fetches; Lemmy ignores limit when
+// max_depth is set and returns every comment down to that depth.
+const lemmyMaxDepth = 8
+
+// Lemmy feed types (correspond to the API's sort parameter)
+const (
+	LemmyFeedHot    = "Hot"
+	LemmyFeedNew    = "New"
+	LemmyFeedActive = "Active"
+	LemmyFeedTop    = "TopDay"
+)
+
+var LemmyFeedNames = []string{LemmyFeedHot, LemmyFeedNew, LemmyFeedActive, LemmyFeedTop}
+var LemmyFeedLabels = []string{"Hot", "New", "Active", "Top"}
+
+// LemmyClient fetches a community from a Lemmy instance's JSON API
+type LemmyClient struct {
+	CachedSource
+	http      *http.Client
+	baseURL   string
+	instance  string
+	community string
+}
+
+// NewLemmyClient creates a client for a community given as
+// "community@instance" (e.g., programming@lemmy.ml). The instance
+// defaults to lemmy.ml when omitted.
+func NewLemmyClient(community string) *LemmyClient {
+	community = strings.TrimPrefix(community, "!")
+	instance := lemmyDefaultInstance
+	if name, host, ok := strings.Cut(community, "@"); ok {
+		community, instance = name, host
+	}
+
+	baseURL := instance
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+
+	return &LemmyClient{
+		CachedSource: NewCachedSource(500 * time.Millisecond),
+		http: &http.Client{
+			Timeout: httpTimeout(15 * time.Second),
+		},
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		instance:  extractHost(instance),
+		community: community,
+	}
+}
+
+// Name returns the display name of the source
+func (c *LemmyClient) Name() string {
+	return fmt.Sprintf("!%s@%s", c.community, c.instance)
+}
+
+// Spec returns the source specification for ParseSource
+func (c *LemmyClient) Spec() string {
+	instance := c.instance
+	if c.baseURL != "https://"+c.instance {
+		instance = c.baseURL
+	}
+	return fmt.Sprintf("lemmy:%s@%s", c.community, instance)
+}
+
+// FeedNames returns the available feed names
+func (c *LemmyClient) FeedNames() []string {
+	return LemmyFeedNames
+}
+
+// FeedLabels returns the display labels for feeds
+func (c *LemmyClient) FeedLabels() []string {
+	return LemmyFeedLabels
+}
+
+// StoryURL returns the URL for viewing a post on the instance
+func (c *LemmyClient) StoryURL(item *Item) string {
+	return fmt.Sprintf("%s/post/%d", c.baseURL, item.ID)
+}
+
+// FetchStoryIDs fetches post IDs for a feed
+func (c *LemmyClient) FetchStoryIDs(feed string) ([]string, error) {
+	c.Throttle()
+
+	query := url.Values{}
+	query.Set("community_name", c.community)
+	query.Set("sort", feed)
+	query.Set("limit", "50")
+
+	var resp lemmyPostsResponse
+	if err := c.getJSON("/api/v3/post/list", query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", c.Name(), err)
+	}
+
+	stories := parseLemmyPosts(resp)
+	if len(stories) == 0 {
+		return nil, fmt.Errorf("no posts found for %s", c.Name())
+	}
+	return c.StoreItems(stories), nil
+}
+
+// FetchItem returns a post seen in a feed, or fetches it by ID for posts
+// that never were
+func (c *LemmyClient) FetchItem(key string) (*Item, error) {
+	if item, err := c.CachedSource.FetchItem(key); err == nil {
+		return item, nil
+	}
+	c.Throttle()
+
+	query := url.Values{}
+	query.Set("id", key)
+
+	var resp struct {
+		PostView lemmyPostView `json:"post_view"`
+	}
+	if err := c.getJSON("/api/v3/post", query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch post %s: %w", key, err)
+	}
+	posts := parseLemmyPosts(lemmyPostsResponse{Posts: []lemmyPostView{resp.PostView}})
+	if len(posts) == 0 || posts[0].Key != key {
+		return nil, fmt.Errorf("post %s not found", key)
+	}
+	c.StoreItems(posts)
+	return posts[0], nil
+}
+
+// FetchCommentTree fetches comments for a post and nests them by path
+func (c *LemmyClient) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
+	c.Throttle()
+
+	depth := lemmyMaxDepth
+	if maxDepth > 0 && maxDepth < depth {
+		depth = maxDepth
+	}
+
+	query := url.Values{}
+	query.Set("post_id", fmt.Sprint(item.ID))
+	query.Set("sort", "Hot")
+	query.Set("type_", "All")
+	query.Set("max_depth", fmt.Sprint(depth))
+
+	var resp lemmyCommentsResponse
+	if err := c.getJSON("/api/v3/comment/list", query, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	return buildLemmyCommentTree(resp.Comments), nil
+}
+
+func (c *LemmyClient) getJSON(path string, query url.Values, v any) error {
+	endpoint := c.baseURL + path + "?" + query.Encode()
+	resp, err := doWithRetry(c.http, endpoint, lemmyUserAgent, &c.CachedSource)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode lemmy response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+)
+
+// lemmyPostsResponse represents the /api/v3/post/list response
+type lemmyPostsResponse struct {
+	Posts []lemmyPostView `json:"posts"`
+}
+
+// lemmyPostView represents a post with its creator and counts
+type lemmyPostView struct {
+	Post struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		URL       string `json:"url"`
+		Body      string `json:"body"`
+		Published string `json:"published"`
+		Deleted   bool   `json:"deleted"`
+		Removed   bool   `json:"removed"`
+	} `json:"post"`
+	Creator lemmyPerson `json:"creator"`
+	Counts  struct {
+		Score    int `json:"score"`
+		Comments int `json:"comments"`
+	} `json:"counts"`
+}
+
+// lemmyCommentsResponse represents the /api/v3/comment/list response
+type lemmyCommentsResponse struct {
+	Comments []lemmyCommentView `json:"comments"`
+}
+
+// lemmyCommentView represents a comment with its creator and counts
+type lemmyCommentView struct {
+	Comment struct {
+		ID        int    `json:"id"`
+		Content   string `json:"content"`
+		Path      string `json:"path"`
+		Published string `json:"published"`
+		Deleted   bool   `json:"deleted"`
+		Removed   bool   `json:"removed"`
+	} `json:"comment"`
+	Creator lemmyPerson `json:"creator"`
+	Counts  struct {
+		Score int `json:"score"`
+	} `json:"counts"`
+}
+
+// lemmyPerson represents a Lemmy user
+type lemmyPerson struct {
+	Name string `json:"name"`
+}
+
+// parseLemmyPosts converts a post listing to Items
+func parseLemmyPosts(resp lemmyPostsResponse) []*Item {
+	var stories []*Item
+	for _, pv := range resp.Posts {
+		if pv.Post.Deleted || pv.Post.Removed {
+			continue
+		}
+		stories = append(stories, &Item{
+			Key:         strconv.Itoa(pv.Post.ID),
+			ID:          pv.Post.ID,
+			Type:        "story",
+			Title:       pv.Post.Name,
+			By:          pv.Creator.Name,
+			Score:       pv.Counts.Score,
+			URL:         pv.Post.URL,
+			Text:        pv.Post.Body,
+			Format:      FormatMarkdown,
+			Time:        parseFeedTime(pv.Post.Published),
+			Descendants: pv.Counts.Comments,
+		})
+	}
+	return stories
+}
+
+// buildLemmyCommentTree nests the flat comment list Lemmy returns using
+// each comment's path ("0.<root id>.<child id>..."). Comments whose parent
+// was not returned are promoted to the top level.
+func buildLemmyCommentTree(views []lemmyCommentView) []*Comment {
+	byID := make(map[int]*Comment, len(views))
+	var roots []*Comment
+
+	for _, cv := range views {
+		comment := lemmyCommentToComment(cv)
+		byID[comment.ID] = comment
+
+		parent, ok := byID[lemmyParentID(cv.Comment.Path)]
+		if !ok {
+			roots = append(roots, comment)
+			continue
+		}
+		parent.Children = append(parent.Children, comment)
+	}
+
+	setCommentDepths(roots, 0)
+	return roots
+}
+
+func lemmyCommentToComment(cv lemmyCommentView) *Comment {
+	item := &Item{
+		Key:    strconv.Itoa(cv.Comment.ID),
+		ID:     cv.Comment.ID,
+		Type:   "comment",
+		By:     cv.Creator.Name,
+		Text:   cv.Comment.Content,
+		Format: FormatMarkdown,
+		Score:  cv.Counts.Score,
+		Time:   parseFeedTime(cv.Comment.Published),
+	}
+	switch {
+	case cv.Comment.Removed:
+		item.Deleted = true
+		item.Text = "[removed by moderator]"
+		item.Format = FormatPlain
+	case cv.Comment.Deleted:
+		item.Deleted = true
+		item.Text = "[deleted]"
+		item.Format = FormatPlain
+	}
+	return &Comment{Item: item}
+}
+
+// lemmyParentID returns the parent comment ID encoded in a path, or 0
+// for top-level comments
+func lemmyParentID(path string) int {
+	parts := strings.Split(path, ".")
+	if len(parts) < 3 {
+		return 0
+	}
+	id, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// setCommentDepths assigns Depth from each comment's position in the tree
+func setCommentDepths(comments []*Comment, depth int) {
+	for _, c := range comments {
+		c.Depth = depth
+		setCommentDepths(c.Children, depth+1)
+	}
+}
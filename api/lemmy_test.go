@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadJSONFixture decodes a recorded API response from testdata
+func loadJSONFixture(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decoding fixture %s: %v", name, err)
+	}
+}
+
+func TestNewLemmyClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		community string
+		baseURL   string
+		display   string
+	}{
+		{"community at instance", "programming@lemmy.ml", "programming", "https://lemmy.ml", "!programming@lemmy.ml"},
+		{"bang prefix", "!rust@programming.dev", "rust", "https://programming.dev", "!rust@programming.dev"},
+		{"default instance", "linux", "linux", "https://lemmy.ml", "!linux@lemmy.ml"},
+		{"explicit scheme", "go@http://localhost:8536", "go", "http://localhost:8536", "!go@localhost:8536"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLemmyClient(tt.spec)
+			if c.community != tt.community {
+				t.Errorf("community = %q, want %q", c.community, tt.community)
+			}
+			if c.baseURL != tt.baseURL {
+				t.Errorf("baseURL = %q, want %q", c.baseURL, tt.baseURL)
+			}
+			if got := c.Name(); got != tt.display {
+				t.Errorf("Name() = %q, want %q", got, tt.display)
+			}
+		})
+	}
+}
+
+func TestParseLemmyPosts(t *testing.T) {
+	var resp lemmyPostsResponse
+	loadJSONFixture(t, "lemmy_posts.json", &resp)
+
+	stories := parseLemmyPosts(resp)
+	if len(stories) != 2 {
+		t.Fatalf("parseLemmyPosts returned %d stories, want 2 (removed post skipped)", len(stories))
+	}
+
+	first := stories[0]
+	if first.ID != 1001 || first.Title != "Rust 1.75 released" || first.By != "ferris" {
+		t.Errorf("first = %+v", first)
+	}
+	if first.Score != 212 || first.Descendants != 4 {
+		t.Errorf("first score/comments = %d/%d, want 212/4", first.Score, first.Descendants)
+	}
+	if want := time.Date(2023, 12, 28, 16, 40, 1, 0, time.UTC).Unix(); first.Time != want {
+		t.Errorf("first.Time = %d, want %d", first.Time, want)
+	}
+
+	second := stories[1]
+	if second.URL != "" || second.Text != "Curious what **everyone** is using these days." {
+		t.Errorf("self post URL/Text = %q/%q", second.URL, second.Text)
+	}
+	if second.Time == 0 {
+		t.Error("second.Time not parsed from timestamp without zone")
+	}
+}
+
+func TestBuildLemmyCommentTree(t *testing.T) {
+	var resp lemmyCommentsResponse
+	loadJSONFixture(t, "lemmy_comments.json", &resp)
+
+	roots := buildLemmyCommentTree(resp.Comments)
+	if len(roots) != 3 {
+		t.Fatalf("got %d top-level comments, want 3", len(roots))
+	}
+
+	alice := roots[0]
+	if alice.By != "alice" || alice.Depth != 0 || len(alice.Children) != 1 {
+		t.Fatalf("roots[0] = %+v with %d children", alice.Item, len(alice.Children))
+	}
+	bob := alice.Children[0]
+	if bob.By != "bob" || bob.Depth != 1 || len(bob.Children) != 1 {
+		t.Fatalf("bob = %+v with %d children", bob.Item, len(bob.Children))
+	}
+	deleted := bob.Children[0]
+	if !deleted.Deleted || deleted.Text != "[deleted]" || deleted.Depth != 2 {
+		t.Errorf("deleted comment = %+v depth %d", deleted.Item, deleted.Depth)
+	}
+
+	if roots[1].By != "carol" {
+		t.Errorf("roots[1].By = %q, want carol", roots[1].By)
+	}
+	orphan := roots[2]
+	if orphan.By != "dave" || orphan.Depth != 0 {
+		t.Errorf("orphan = %+v depth %d, want promoted to top level", orphan.Item, orphan.Depth)
+	}
+}
+
+func TestLemmyParentID(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"0.11", 0},
+		{"0.11.12", 11},
+		{"0.11.12.14", 12},
+		{"", 0},
+		{"0.x.12", 0},
+	}
+
+	for _, tt := range tests {
+		if got := lemmyParentID(tt.path); got != tt.want {
+			t.Errorf("lemmyParentID(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLemmyClient_Fetch(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/api/v3/post/list":    "lemmy_posts.json",
+		"/api/v3/comment/list": "lemmy_comments.json",
+	})
+	c := NewLemmyClient("programming@" + srv.URL)
+	c.minDelay = 0
+
+	ids, err := c.FetchStoryIDs(LemmyFeedHot)
+	if err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+	item, err := c.FetchItem(ids[0])
+	if err != nil {
+		t.Fatalf("FetchItem unexpected error: %v", err)
+	}
+	if got, want := c.StoryURL(item), srv.URL+"/post/1001"; got != want {
+		t.Errorf("StoryURL = %q, want %q", got, want)
+	}
+
+	comments, err := c.FetchCommentTree(item, 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if len(comments) != 3 {
+		t.Errorf("FetchCommentTree returned %d roots, want 3", len(comments))
+	}
+}
+
+func TestLemmyClient_FetchItemNotInFeed(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/api/v3/post": "lemmy_post.json",
+	})
+	c := NewLemmyClient("programming@" + srv.URL)
+	c.minDelay = 0
+
+	item, err := c.FetchItem("1001")
+	if err != nil {
+		t.Fatalf("FetchItem unexpected error: %v", err)
+	}
+	if item.Title != "Rust 1.75 released" || item.By != "ferris" || item.ID != 1001 {
+		t.Errorf("item = %+v", item)
+	}
+	if _, err := c.FetchItem("1002"); err == nil {
+		t.Error("FetchItem returned a post with a different ID")
+	}
+}
@@ -32,7 +32,7 @@ func NewLobstersClient() *LobstersClient {
 	return &LobstersClient{
 		CachedSource: NewCachedSource(500 * time.Millisecond),
 		http: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout: httpTimeout(15 * time.Second),
 		},
 	}
 }
@@ -42,6 +42,11 @@ func (c *LobstersClient) Name() string {
 	return "Lobsters"
 }
 
+// Spec returns the source specification for ParseSource
+func (c *LobstersClient) Spec() string {
+	return "lobsters"
+}
+
 // FeedNames returns the available feed names
 func (c *LobstersClient) FeedNames() []string {
 	return LobstersFeedNames
@@ -54,14 +59,14 @@ func (c *LobstersClient) FeedLabels() []string {
 
 // StoryURL returns the URL for viewing a story on Lobste.rs
 func (c *LobstersClient) StoryURL(item *Item) string {
-	if item.Type != "" && item.Type != "story" {
-		return fmt.Sprintf("%s/s/%s", lobstersBaseURL, item.Type)
+	if item.Key != "" {
+		return fmt.Sprintf("%s/s/%s", lobstersBaseURL, item.Key)
 	}
 	return item.URL
 }
 
-// FetchStoryIDs fetches story "IDs" for a feed
-func (c *LobstersClient) FetchStoryIDs(feed string) ([]int, error) {
+// FetchStoryIDs fetches story short IDs for a feed
+func (c *LobstersClient) FetchStoryIDs(feed string) ([]string, error) {
 	var allStories []*Item
 	for page := 1; page <= 2; page++ {
 		stories, err := c.fetchStoriesPage(feed, page)
@@ -104,12 +109,34 @@ func lobstersPageURL(feed string, page int) string {
 	return fmt.Sprintf("%s/%s/page/%d", lobstersBaseURL, feed, page)
 }
 
+// FetchItem returns a story seen in a feed, or scrapes it from its own
+// page for stories that never were
+func (c *LobstersClient) FetchItem(key string) (*Item, error) {
+	if item, err := c.CachedSource.FetchItem(key); err == nil {
+		return item, nil
+	}
+	c.Throttle()
+
+	doc, err := c.fetchDocument(fmt.Sprintf("%s/s/%s", lobstersBaseURL, key))
+	if err != nil {
+		return nil, err
+	}
+	stories, _ := parseLobstersStories(doc)
+	for _, story := range stories {
+		if story.Key == key {
+			c.StoreItems([]*Item{story})
+			return story, nil
+		}
+	}
+	return nil, fmt.Errorf("story %s not found", key)
+}
+
 // FetchCommentTree fetches comments for a story
 func (c *LobstersClient) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
 	c.Throttle()
 
-	shortID := item.Type
-	if shortID == "" || shortID == "story" {
+	shortID := item.Key
+	if shortID == "" {
 		return nil, fmt.Errorf("no story ID available")
 	}
 
@@ -57,7 +57,7 @@ func parseLobstersTitle(s *goquery.Selection, item *Item) {
 
 func parseLobstersShortID(s *goquery.Selection, item *Item) {
 	if shortID, exists := s.Attr("data-shortid"); exists {
-		item.Type = shortID
+		item.Key = shortID
 		item.ID = hashShortID(shortID)
 	}
 }
@@ -105,62 +105,103 @@ func parseLobstersTags(s *goquery.Selection, item *Item) {
 			tags = append(tags, tag)
 		}
 	})
-	if len(tags) > 0 {
-		item.Text = "[" + strings.Join(tags, ", ") + "]"
-	}
+	item.Tags = tags
 }
 
-// parseLobstersComments extracts comments from a story page
+// parseLobstersComments builds the comment tree from a story page. Each
+// li.comments_subtree holds one div.comment and an ol.comments of replies.
 func parseLobstersComments(doc *goquery.Document) ([]*Comment, error) {
+	root := doc.Find("ol.comments").First()
+	if root.Length() == 0 {
+		return nil, nil
+	}
+	op := parseLobstersStoryAuthor(doc)
+	return parseLobstersThread(root, 0, op), nil
+}
+
+// parseLobstersStoryAuthor returns the submitter of the story on the page
+func parseLobstersStoryAuthor(doc *goquery.Document) string {
+	story := &Item{}
+	parseLobstersAuthor(doc.Find("li.story").First(), story)
+	return story.By
+}
+
+// parseLobstersThread parses the direct subtrees of an ol.comments list
+func parseLobstersThread(list *goquery.Selection, depth int, op string) []*Comment {
 	var comments []*Comment
 
-	doc.Find("div.comment[data-shortid]").Each(func(i int, s *goquery.Selection) {
-		comment := parseLobstersComment(s)
-		if comment != nil {
-			comments = append(comments, comment)
+	list.ChildrenFiltered("li.comments_subtree").Each(func(i int, li *goquery.Selection) {
+		replies := parseLobstersThread(li.ChildrenFiltered("ol.comments"), depth+1, op)
+		comment := parseLobstersComment(li.ChildrenFiltered("div.comment[data-shortid]"), depth, op)
+		if comment == nil {
+			if len(replies) == 0 {
+				return
+			}
+			// Keep replies attached to a placeholder for the missing parent
+			comment = &Comment{
+				Item:  &Item{Type: "comment", Deleted: true, Text: "[missing comment]"},
+				Depth: depth,
+			}
 		}
+		comment.Children = replies
+		comments = append(comments, comment)
 	})
 
-	return comments, nil
+	return comments
 }
 
 // parseLobstersComment extracts a single comment
-func parseLobstersComment(s *goquery.Selection) *Comment {
+func parseLobstersComment(s *goquery.Selection, depth int, op string) *Comment {
+	if s.Length() == 0 {
+		return nil
+	}
 	item := &Item{Type: "comment"}
 
+	parseLobstersCommentShortID(s, item)
 	parseLobstersAuthor(s, item)
 	parseLobstersCommentText(s, item)
 	parseLobstersTime(s.Find(".byline time"), item)
-	parseLobstersScore(s, item)
+	parseLobstersCommentScore(s, item)
+	item.Deleted = s.HasClass("deleted") || s.Find(".comment_text .deleted").Length() > 0
 
-	if item.By == "" && item.Text == "" {
+	if item.Key == "" && item.By == "" && item.Text == "" {
 		return nil
 	}
+
+	isOP := s.Find(".byline a.user_is_author").Length() > 0 || (op != "" && item.By == op)
 	return &Comment{
 		Item:  item,
-		Depth: parseLobstersDepth(s),
+		Depth: depth,
+		OP:    isOP && !item.Deleted,
 	}
 }
 
-func parseLobstersCommentText(s *goquery.Selection, item *Item) {
-	textSel := s.Find(".comment_text")
-	if textSel.Length() > 0 {
-		html, _ := textSel.Html()
-		item.Text = html
+func parseLobstersCommentShortID(s *goquery.Selection, item *Item) {
+	if shortID, exists := s.Attr("data-shortid"); exists {
+		item.Key = shortID
+		item.ID = hashShortID(shortID)
 	}
 }
 
-func parseLobstersDepth(s *goquery.Selection) int {
-	depth := 0
-	s.Parents().Each(func(i int, parent *goquery.Selection) {
-		if parent.Is("ol.comments") {
-			depth++
-		}
-	})
-	if depth > 0 {
-		depth--
+// parseLobstersCommentScore reads the score from .score, falling back
+// to the story-style upvoter link text
+func parseLobstersCommentScore(s *goquery.Selection, item *Item) {
+	scoreSel := s.ChildrenFiltered(".voters").Find(".score")
+	if scoreSel.Length() == 0 {
+		parseLobstersScore(s, item)
+		return
+	}
+	if score, err := strconv.Atoi(strings.TrimSpace(scoreSel.First().Text())); err == nil {
+		item.Score = score
+	}
+}
+
+func parseLobstersCommentText(s *goquery.Selection, item *Item) {
+	textSel := s.Find(".comment_text").First()
+	if textSel.Length() > 0 {
+		html, _ := textSel.Html()
+		item.Text = strings.TrimSpace(html)
 	}
-	return depth
 }
 
 // parseLobstersTime extracts time from a Lobsters time element into an Item
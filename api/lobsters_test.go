@@ -1,8 +1,13 @@
 package api
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 func TestHashShortID(t *testing.T) {
@@ -104,3 +109,130 @@ func TestParseRelativeTime(t *testing.T) {
 		})
 	}
 }
+
+func loadLobstersFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("opening fixture %s: %v", name, err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatalf("parsing fixture %s: %v", name, err)
+	}
+	return doc
+}
+
+func TestParseLobstersComments_Tree(t *testing.T) {
+	comments, err := parseLobstersComments(loadLobstersFixture(t, "lobsters_story.html"))
+	if err != nil {
+		t.Fatalf("parseLobstersComments unexpected error: %v", err)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("got %d top-level comments, want 3", len(comments))
+	}
+
+	// Walk the deepest chain: alice > gopher > bob > carol > dave
+	chain := []struct {
+		key   string
+		by    string
+		score int
+		op    bool
+	}{
+		{"c1aaaa", "alice", 17, false},
+		{"c2bbbb", "gopher", 5, true},
+		{"c3cccc", "bob", 3, false},
+		{"c4dddd", "carol", 1, false},
+		{"c5eeee", "dave", 2, false},
+	}
+	c := comments[0]
+	for depth, want := range chain {
+		if c.Key != want.key || c.By != want.by || c.Score != want.score {
+			t.Errorf("depth %d = %s/%s/%d, want %s/%s/%d",
+				depth, c.Key, c.By, c.Score, want.key, want.by, want.score)
+		}
+		if c.Depth != depth {
+			t.Errorf("%s.Depth = %d, want %d", c.Key, c.Depth, depth)
+		}
+		if c.OP != want.op {
+			t.Errorf("%s.OP = %v, want %v", c.Key, c.OP, want.op)
+		}
+		if c.Time == 0 {
+			t.Errorf("%s.Time not parsed", c.Key)
+		}
+		if depth < len(chain)-1 {
+			if len(c.Children) == 0 {
+				t.Fatalf("%s has no children, want %s", c.Key, chain[depth+1].key)
+			}
+			c = c.Children[0]
+		}
+	}
+	if len(c.Children) != 0 {
+		t.Errorf("leaf %s has %d children, want 0", c.Key, len(c.Children))
+	}
+
+	if len(comments[0].Children) != 2 {
+		t.Fatalf("alice has %d replies, want 2", len(comments[0].Children))
+	}
+	if !strings.Contains(comments[0].Text, `<a href="https://example.com">a link</a>`) {
+		t.Errorf("comment text = %q, want inner HTML preserved", comments[0].Text)
+	}
+	if comments[2].Key != "c9iiii" || comments[2].By != "grace" || comments[2].Score != 8 {
+		t.Errorf("comments[2] = %s/%s/%d", comments[2].Key, comments[2].By, comments[2].Score)
+	}
+}
+
+func TestParseLobstersComments_DeletedAndModerated(t *testing.T) {
+	comments, err := parseLobstersComments(loadLobstersFixture(t, "lobsters_story.html"))
+	if err != nil {
+		t.Fatalf("parseLobstersComments unexpected error: %v", err)
+	}
+
+	deleted := comments[0].Children[1]
+	if deleted.Key != "c6ffff" || !deleted.Deleted {
+		t.Fatalf("deleted comment = %+v, want c6ffff marked deleted", deleted.Item)
+	}
+	if !strings.Contains(deleted.Text, "removed by author") {
+		t.Errorf("deleted.Text = %q", deleted.Text)
+	}
+	if len(deleted.Children) != 1 || deleted.Children[0].By != "frank" || deleted.Children[0].Depth != 2 {
+		t.Errorf("replies to deleted comment = %v, want frank at depth 2", deleted.Children)
+	}
+
+	moderated := comments[1]
+	if moderated.Key != "c8hhhh" || !moderated.Deleted || moderated.By != "" {
+		t.Errorf("moderated comment = %+v", moderated.Item)
+	}
+	if moderated.Score != -3 {
+		t.Errorf("moderated.Score = %d, want -3", moderated.Score)
+	}
+	if !strings.Contains(moderated.Text, "removed by moderator") {
+		t.Errorf("moderated.Text = %q", moderated.Text)
+	}
+}
+
+func TestParseLobstersComments_NoComments(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><ol class="stories"></ol></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments, err := parseLobstersComments(doc)
+	if err != nil || len(comments) != 0 {
+		t.Errorf("parseLobstersComments = %v, %v; want empty", comments, err)
+	}
+}
+
+func TestParseLobstersStories(t *testing.T) {
+	stories, err := parseLobstersStories(loadLobstersFixture(t, "lobsters_story.html"))
+	if err != nil {
+		t.Fatalf("parseLobstersStories unexpected error: %v", err)
+	}
+	if len(stories) != 1 {
+		t.Fatalf("got %d stories, want 1", len(stories))
+	}
+	story := stories[0]
+	if story.Key != "abc123" || story.By != "gopher" || story.Score != 42 || len(story.Tags) != 1 || story.Tags[0] != "go" {
+		t.Errorf("story = %+v", story)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -20,12 +21,17 @@ const (
 var RedditFeedNames = []string{RedditFeedHot, RedditFeedNew, RedditFeedTop, RedditFeedRising, RedditFeedBest}
 var RedditFeedLabels = []string{"Hot", "New", "Top", "Rising", "Best"}
 
+const redditBaseURL = "https://www.reddit.com"
+
+// redditMoreChildrenLimit is the most comments morechildren expands per call
+const redditMoreChildrenLimit = 100
+
 // RedditClient fetches data from Reddit's JSON API
 type RedditClient struct {
 	CachedSource
-	http       *http.Client
-	subreddit  string
-	idToReddit map[int]string // Maps pseudo-ID to Reddit post ID
+	http      *http.Client
+	baseURL   string
+	subreddit string
 }
 
 // NewRedditClient creates a new Reddit API client for a subreddit
@@ -36,10 +42,10 @@ func NewRedditClient(subreddit string) *RedditClient {
 	return &RedditClient{
 		CachedSource: NewCachedSource(1 * time.Second),
 		http: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout: httpTimeout(15 * time.Second),
 		},
-		subreddit:  subreddit,
-		idToReddit: make(map[int]string),
+		baseURL:   redditBaseURL,
+		subreddit: subreddit,
 	}
 }
 
@@ -48,6 +54,11 @@ func (c *RedditClient) Name() string {
 	return fmt.Sprintf("r/%s", c.subreddit)
 }
 
+// Spec returns the source specification for ParseSource
+func (c *RedditClient) Spec() string {
+	return c.Name()
+}
+
 // FeedNames returns the available feed names
 func (c *RedditClient) FeedNames() []string {
 	return RedditFeedNames
@@ -66,8 +77,8 @@ func (c *RedditClient) StoryURL(item *Item) string {
 	return item.URL
 }
 
-// FetchStoryIDs fetches story "IDs" for a feed
-func (c *RedditClient) FetchStoryIDs(feed string) ([]int, error) {
+// FetchStoryIDs fetches story fullnames (t3_...) for a feed
+func (c *RedditClient) FetchStoryIDs(feed string) ([]string, error) {
 	stories, err := c.fetchStories(feed)
 	if err != nil {
 		return nil, err
@@ -77,14 +88,7 @@ func (c *RedditClient) FetchStoryIDs(feed string) ([]int, error) {
 		return nil, fmt.Errorf("no stories found for r/%s/%s", c.subreddit, feed)
 	}
 
-	ids := c.StoreItems(stories)
-
-	c.idToReddit = make(map[int]string)
-	for i, story := range stories {
-		c.idToReddit[i+1] = story.Type
-	}
-
-	return ids, nil
+	return c.StoreItems(stories), nil
 }
 
 const redditUserAgent = "feedme:v1.0 (terminal news reader)"
@@ -93,7 +97,7 @@ const redditUserAgent = "feedme:v1.0 (terminal news reader)"
 func (c *RedditClient) fetchStories(feed string) ([]*Item, error) {
 	c.Throttle()
 
-	url := fmt.Sprintf("https://www.reddit.com/r/%s/%s.json?limit=100", c.subreddit, feed)
+	url := fmt.Sprintf("%s/r/%s/%s.json?limit=100", c.baseURL, c.subreddit, feed)
 	resp, err := doWithRetry(c.http, url, redditUserAgent, &c.CachedSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch r/%s: %w", c.subreddit, err)
@@ -108,6 +112,34 @@ func (c *RedditClient) fetchStories(feed string) ([]*Item, error) {
 	return parseRedditStories(listing), nil
 }
 
+// FetchItem returns a post seen in a feed, or fetches it by fullname
+// (t3_...) or ID for posts that never were
+func (c *RedditClient) FetchItem(key string) (*Item, error) {
+	if !strings.HasPrefix(key, "t3_") {
+		key = "t3_" + key
+	}
+	if item, err := c.CachedSource.FetchItem(key); err == nil {
+		return item, nil
+	}
+	c.Throttle()
+
+	listings, err := c.fetchCommentListings("/comments/" + strings.TrimPrefix(key, "t3_"))
+	if err != nil {
+		return nil, err
+	}
+	posts := listings[0].Data.Children
+	if len(posts) == 0 || posts[0].Kind != "t3" {
+		return nil, fmt.Errorf("post %s not found", key)
+	}
+	var post redditPost
+	if err := json.Unmarshal(posts[0].Data, &post); err != nil {
+		return nil, fmt.Errorf("failed to decode post: %w", err)
+	}
+	item := redditPostToItem(post)
+	c.StoreItems([]*Item{item})
+	return item, nil
+}
+
 // FetchCommentTree fetches comments for a story
 func (c *RedditClient) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
 	c.Throttle()
@@ -125,7 +157,7 @@ func (c *RedditClient) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, e
 }
 
 func (c *RedditClient) fetchCommentListings(permalink string) ([]redditCommentListing, error) {
-	url := fmt.Sprintf("https://www.reddit.com%s.json?limit=200", permalink)
+	url := fmt.Sprintf("%s%s.json?limit=200", c.baseURL, permalink)
 	resp, err := doWithRetry(c.http, url, redditUserAgent, &c.CachedSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch comments: %w", err)
@@ -141,3 +173,92 @@ func (c *RedditClient) fetchCommentListings(permalink string) ([]redditCommentLi
 	}
 	return listings, nil
 }
+
+// LoadMoreComments expands a "load more comments" or "continue this
+// thread" placeholder from FetchCommentTree
+func (c *RedditClient) LoadMoreComments(story *Item, more *Comment) ([]*Comment, error) {
+	if more.More == nil {
+		return nil, fmt.Errorf("comment %s is not a placeholder", more.Key)
+	}
+	c.Throttle()
+
+	var comments []*Comment
+	var err error
+	if len(more.More.Keys) == 0 {
+		comments, err = c.continueThread(story, more.More.ParentKey)
+	} else {
+		comments, err = c.moreChildren(story, more.More)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setCommentDepths(comments, more.Depth)
+	return comments, nil
+}
+
+// continueThread fetches the replies under parentKey from the parent
+// comment's own page
+func (c *RedditClient) continueThread(story *Item, parentKey string) ([]*Comment, error) {
+	permalink := story.Type
+	if !strings.HasPrefix(permalink, "/r/") {
+		return nil, fmt.Errorf("no permalink available")
+	}
+	permalink = strings.TrimSuffix(permalink, "/") + "/" + strings.TrimPrefix(parentKey, "t1_")
+
+	listings, err := c.fetchCommentListings(permalink)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := parseRedditComments(listings[1], 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		if comment.Key == parentKey {
+			return comment.Children, nil
+		}
+	}
+	return nil, fmt.Errorf("comment %s not found", parentKey)
+}
+
+// moreChildren expands up to redditMoreChildrenLimit of the placeholder's
+// comments, leaving a new placeholder for any that remain
+func (c *RedditClient) moreChildren(story *Item, more *MoreComments) ([]*Comment, error) {
+	batch := more.Keys[:min(len(more.Keys), redditMoreChildrenLimit)]
+	ids := make([]string, len(batch))
+	for i, key := range batch {
+		ids[i] = strings.TrimPrefix(key, "t1_")
+	}
+
+	query := url.Values{}
+	query.Set("api_type", "json")
+	query.Set("link_id", story.Key)
+	query.Set("children", strings.Join(ids, ","))
+	query.Set("limit_children", "false")
+
+	endpoint := c.baseURL + "/api/morechildren.json?" + query.Encode()
+	resp, err := doWithRetry(c.http, endpoint, redditUserAgent, &c.CachedSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch more comments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result redditMoreChildrenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode more comments: %w", err)
+	}
+	if len(result.JSON.Errors) > 0 {
+		return nil, fmt.Errorf("reddit returned errors: %s", result.JSON.Errors[0])
+	}
+
+	comments := buildRedditCommentTree(result.JSON.Data.Things)
+	if rest := more.Keys[len(batch):]; len(rest) > 0 {
+		comments = append(comments, newRedditMoreComment(rest[0], 0, MoreComments{
+			Count:     max(more.Count-len(batch), len(rest)),
+			ParentKey: more.ParentKey,
+			Keys:      rest,
+		}))
+	}
+	return comments, nil
+}
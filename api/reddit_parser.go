@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 )
 
 // redditListing represents the top-level JSON structure
@@ -34,22 +35,48 @@ type redditPost struct {
 // redditCommentListing represents the comments JSON structure
 type redditCommentListing struct {
 	Data struct {
-		Children []struct {
-			Kind string          `json:"kind"`
-			Data json.RawMessage `json:"data"`
-		} `json:"children"`
+		Children []redditThing `json:"children"`
 	} `json:"data"`
 }
 
+// redditThing is a listing child: a comment ("t1") or a "more" stub
+// standing in for replies Reddit left out of the response
+type redditThing struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
 // redditComment represents a Reddit comment
 type redditComment struct {
-	ID         string  `json:"id"`
-	Author     string  `json:"author"`
-	Body       string  `json:"body"`
-	Score      int     `json:"score"`
-	CreatedUTC float64 `json:"created_utc"`
-	Depth      int     `json:"depth"`
-	Replies    any     `json:"replies"` // Can be "" or a listing
+	ID         string          `json:"id"`
+	ParentID   string          `json:"parent_id"`
+	Author     string          `json:"author"`
+	Body       string          `json:"body"`
+	Score      int             `json:"score"`
+	CreatedUTC float64         `json:"created_utc"`
+	Depth      int             `json:"depth"`
+	Replies    json.RawMessage `json:"replies"` // Can be "" or a listing
+}
+
+// redditMore represents a "more" stub. A stub with no children and the
+// ID "_" is a "continue this thread" link to a deeper part of the tree.
+type redditMore struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	ParentID string   `json:"parent_id"`
+	Count    int      `json:"count"`
+	Depth    int      `json:"depth"`
+	Children []string `json:"children"`
+}
+
+// redditMoreChildrenResponse represents the /api/morechildren response
+type redditMoreChildrenResponse struct {
+	JSON struct {
+		Errors []json.RawMessage `json:"errors"`
+		Data   struct {
+			Things []redditThing `json:"things"`
+		} `json:"data"`
+	} `json:"json"`
 }
 
 // parseRedditStories converts a listing of Reddit posts to Items
@@ -63,6 +90,7 @@ func parseRedditStories(listing redditListing) []*Item {
 
 func redditPostToItem(post redditPost) *Item {
 	item := &Item{
+		Key:         "t3_" + post.ID,
 		ID:          hashShortID(post.ID),
 		Type:        post.Permalink,
 		Title:       post.Title,
@@ -71,9 +99,11 @@ func redditPostToItem(post redditPost) *Item {
 		URL:         post.URL,
 		Time:        int64(post.CreatedUTC),
 		Descendants: post.NumComments,
+		Text:        html.UnescapeString(post.Selftext),
+		Format:      FormatMarkdown,
 	}
 	if post.LinkFlairText != "" {
-		item.Text = "[" + post.LinkFlairText + "]"
+		item.Tags = []string{html.UnescapeString(post.LinkFlairText)}
 	}
 	if post.IsSelf {
 		item.URL = fmt.Sprintf("https://www.reddit.com%s", post.Permalink)
@@ -83,25 +113,37 @@ func redditPostToItem(post redditPost) *Item {
 
 // parseRedditComments extracts comments from the listing
 func parseRedditComments(listing redditCommentListing, maxDepth int) ([]*Comment, error) {
-	var comments []*Comment
+	return parseRedditChildren(listing.Data.Children, maxDepth), nil
+}
 
-	for _, child := range listing.Data.Children {
-		if child.Kind != "t1" {
-			continue
+// parseRedditChildren converts listing children to comments, keeping
+// "more" stubs as placeholders
+func parseRedditChildren(children []redditThing, maxDepth int) []*Comment {
+	var comments []*Comment
+	for _, child := range children {
+		if c := parseRedditThing(child, maxDepth); c != nil {
+			comments = append(comments, c)
 		}
+	}
+	return comments
+}
 
+func parseRedditThing(thing redditThing, maxDepth int) *Comment {
+	switch thing.Kind {
+	case "t1":
 		var rc redditComment
-		if err := json.Unmarshal(child.Data, &rc); err != nil {
-			continue
+		if err := json.Unmarshal(thing.Data, &rc); err != nil {
+			return nil
 		}
-
-		comment := parseRedditComment(rc, maxDepth)
-		if comment != nil {
-			comments = append(comments, comment)
+		return parseRedditComment(rc, maxDepth)
+	case "more":
+		var rm redditMore
+		if err := json.Unmarshal(thing.Data, &rm); err != nil {
+			return nil
 		}
+		return parseRedditMore(rm)
 	}
-
-	return comments, nil
+	return nil
 }
 
 // parseRedditComment converts a Reddit comment to our Comment type
@@ -111,12 +153,14 @@ func parseRedditComment(rc redditComment, maxDepth int) *Comment {
 	}
 
 	item := &Item{
-		ID:    hashShortID(rc.ID),
-		Type:  "comment",
-		By:    rc.Author,
-		Text:  rc.Body,
-		Score: rc.Score,
-		Time:  int64(rc.CreatedUTC),
+		Key:    "t1_" + rc.ID,
+		ID:     hashShortID(rc.ID),
+		Type:   "comment",
+		By:     rc.Author,
+		Text:   html.UnescapeString(rc.Body),
+		Format: FormatMarkdown,
+		Score:  rc.Score,
+		Time:   int64(rc.CreatedUTC),
 	}
 
 	comment := &Comment{
@@ -132,38 +176,70 @@ func parseRedditComment(rc redditComment, maxDepth int) *Comment {
 }
 
 // parseRedditReplies extracts child comments from the replies field
-func parseRedditReplies(replies any, maxDepth int) []*Comment {
-	repliesMap, ok := replies.(map[string]any)
-	if !ok {
+func parseRedditReplies(replies json.RawMessage, maxDepth int) []*Comment {
+	if len(replies) == 0 || replies[0] != '{' {
 		return nil
 	}
-	data, ok := repliesMap["data"].(map[string]any)
-	if !ok {
+	var listing redditCommentListing
+	if err := json.Unmarshal(replies, &listing); err != nil {
 		return nil
 	}
-	children, ok := data["children"].([]any)
-	if !ok {
+	return parseRedditChildren(listing.Data.Children, maxDepth)
+}
+
+// parseRedditMore converts a "more" stub to a placeholder comment
+func parseRedditMore(rm redditMore) *Comment {
+	if len(rm.Children) == 0 && rm.ID != "_" {
 		return nil
 	}
+	keys := make([]string, len(rm.Children))
+	for i, id := range rm.Children {
+		keys[i] = "t1_" + id
+	}
+	return newRedditMoreComment(rm.Name, rm.Depth, MoreComments{
+		Count:     rm.Count,
+		ParentKey: rm.ParentID,
+		Keys:      keys,
+	})
+}
 
-	var result []*Comment
-	for _, child := range children {
-		childMap, ok := child.(map[string]any)
-		if !ok || childMap["kind"] != "t1" {
-			continue
+func newRedditMoreComment(key string, depth int, more MoreComments) *Comment {
+	return &Comment{
+		Item: &Item{
+			Key:  key,
+			ID:   hashShortID(key),
+			Type: "more",
+		},
+		Depth: depth,
+		More:  &more,
+	}
+}
+
+// buildRedditCommentTree nests the flat list of things morechildren
+// returns using each thing's parent_id. Things whose parent is not in the
+// list (the placeholder's own parent) become the top level.
+func buildRedditCommentTree(things []redditThing) []*Comment {
+	byKey := make(map[string]*Comment, len(things))
+	var roots []*Comment
+
+	for _, thing := range things {
+		var parent struct {
+			ParentID string `json:"parent_id"`
 		}
-		childData, ok := childMap["data"].(map[string]any)
-		if !ok {
+		if err := json.Unmarshal(thing.Data, &parent); err != nil {
 			continue
 		}
-		childJSON, _ := json.Marshal(childData)
-		var childRC redditComment
-		if err := json.Unmarshal(childJSON, &childRC); err != nil {
+		comment := parseRedditThing(thing, 0)
+		if comment == nil {
 			continue
 		}
-		if c := parseRedditComment(childRC, maxDepth); c != nil {
-			result = append(result, c)
+		byKey[comment.Key] = comment
+
+		if p, ok := byKey[parent.ParentID]; ok {
+			p.Children = append(p.Children, comment)
+		} else {
+			roots = append(roots, comment)
 		}
 	}
-	return result
+	return roots
 }
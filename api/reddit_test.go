@@ -0,0 +1,234 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestRedditClient(baseURL string) *RedditClient {
+	c := NewRedditClient("golang")
+	c.baseURL = baseURL
+	c.minDelay = 0
+	return c
+}
+
+// parseRedditFixture parses the comment listing from a comments page fixture
+func parseRedditFixture(t *testing.T, name string) []*Comment {
+	t.Helper()
+	var listings []redditCommentListing
+	loadJSONFixture(t, name, &listings)
+	comments, err := parseRedditComments(listings[1], 0)
+	if err != nil {
+		t.Fatalf("parseRedditComments: %v", err)
+	}
+	return comments
+}
+
+func TestParseRedditComments_MoreStubs(t *testing.T) {
+	comments := parseRedditFixture(t, "reddit_comments.json")
+
+	// c3 was deleted, so: alice, dave, and the top-level stub
+	if len(comments) != 3 {
+		t.Fatalf("got %d top-level comments, want 3", len(comments))
+	}
+
+	alice := comments[0]
+	if len(alice.Children) != 2 {
+		t.Fatalf("alice has %d children, want reply and stub", len(alice.Children))
+	}
+
+	tests := []struct {
+		name      string
+		comment   *Comment
+		depth     int
+		count     int
+		parentKey string
+		keys      []string
+	}{
+		{"nested load more", alice.Children[1], 1, 3, "t1_c1", []string{"t1_c5", "t1_c6"}},
+		{"continue this thread", alice.Children[0].Children[0], 2, 0, "t1_c2", nil},
+		{"top-level load more", comments[2], 0, 120, "t3_p1", []string{"t1_c7", "t1_c8", "t1_c9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.comment
+			if c.More == nil {
+				t.Fatalf("comment %s (%q) is not a placeholder", c.Key, c.By)
+			}
+			if c.Type != "more" {
+				t.Errorf("Type = %q, want more", c.Type)
+			}
+			if c.Depth != tt.depth {
+				t.Errorf("Depth = %d, want %d", c.Depth, tt.depth)
+			}
+			if c.More.Count != tt.count {
+				t.Errorf("Count = %d, want %d", c.More.Count, tt.count)
+			}
+			if c.More.ParentKey != tt.parentKey {
+				t.Errorf("ParentKey = %q, want %q", c.More.ParentKey, tt.parentKey)
+			}
+			if strings.Join(c.More.Keys, ",") != strings.Join(tt.keys, ",") {
+				t.Errorf("Keys = %v, want %v", c.More.Keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestRedditClient_LoadMoreComments(t *testing.T) {
+	var gotQuery map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/morechildren.json" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		gotQuery = map[string]string{"link_id": q.Get("link_id"), "children": q.Get("children")}
+		http.ServeFile(w, r, "testdata/reddit_morechildren.json")
+	}))
+	defer srv.Close()
+
+	c := newTestRedditClient(srv.URL)
+	story := &Item{Key: "t3_p1", Type: "/r/golang/comments/p1/a_thread/"}
+	comments := parseRedditFixture(t, "reddit_comments.json")
+	stub := comments[2]
+
+	loaded, err := c.LoadMoreComments(story, stub)
+	if err != nil {
+		t.Fatalf("LoadMoreComments: %v", err)
+	}
+	if gotQuery["link_id"] != "t3_p1" || gotQuery["children"] != "c7,c8,c9" {
+		t.Errorf("morechildren query = %v, want link_id=t3_p1 children=c7,c8,c9", gotQuery)
+	}
+
+	comments, ok := ReplaceComment(comments, stub, loaded)
+	if !ok {
+		t.Fatal("ReplaceComment did not find the placeholder")
+	}
+
+	// alice, dave, then erin and grace (c9 was deleted) in place of the stub
+	var authors []string
+	for _, c := range comments {
+		authors = append(authors, c.By)
+	}
+	if got := strings.Join(authors, ","); got != "alice,dave,erin,grace" {
+		t.Fatalf("top-level authors after splice = %s, want alice,dave,erin,grace", got)
+	}
+
+	erin, grace := comments[2], comments[3]
+	if len(erin.Children) != 1 || erin.Children[0].By != "frank" || erin.Children[0].Depth != 1 {
+		t.Errorf("erin's reply not nested under her at depth 1: %+v", erin.Children)
+	}
+	if len(grace.Children) != 1 || grace.Children[0].More == nil {
+		t.Fatalf("grace should have a nested placeholder, got %+v", grace.Children)
+	}
+	if nested := grace.Children[0]; nested.Depth != 1 || nested.More.Count != 4 {
+		t.Errorf("nested placeholder depth/count = %d/%d, want 1/4", nested.Depth, nested.More.Count)
+	}
+}
+
+func TestRedditClient_LoadMoreCommentsBatches(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = strings.Split(r.URL.Query().Get("children"), ",")
+		fmt.Fprint(w, `{"json": {"errors": [], "data": {"things": []}}}`)
+	}))
+	defer srv.Close()
+
+	var keys []string
+	for i := 0; i < redditMoreChildrenLimit+20; i++ {
+		keys = append(keys, fmt.Sprintf("t1_k%d", i))
+	}
+	stub := newRedditMoreComment(keys[0], 3, MoreComments{Count: 150, ParentKey: "t1_parent", Keys: keys})
+
+	c := newTestRedditClient(srv.URL)
+	loaded, err := c.LoadMoreComments(&Item{Key: "t3_p1"}, stub)
+	if err != nil {
+		t.Fatalf("LoadMoreComments: %v", err)
+	}
+	if len(requested) != redditMoreChildrenLimit {
+		t.Errorf("requested %d children, want %d", len(requested), redditMoreChildrenLimit)
+	}
+	if len(loaded) != 1 || loaded[0].More == nil {
+		t.Fatalf("expected a single placeholder for the remainder, got %+v", loaded)
+	}
+	rest := loaded[0]
+	if len(rest.More.Keys) != 20 || rest.More.Count != 50 || rest.Depth != 3 {
+		t.Errorf("remainder keys/count/depth = %d/%d/%d, want 20/50/3",
+			len(rest.More.Keys), rest.More.Count, rest.Depth)
+	}
+}
+
+func TestRedditClient_ContinueThread(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/r/golang/comments/p1/a_thread/c2.json": "reddit_continue.json",
+	})
+
+	c := newTestRedditClient(srv.URL)
+	story := &Item{Key: "t3_p1", Type: "/r/golang/comments/p1/a_thread/"}
+	comments := parseRedditFixture(t, "reddit_comments.json")
+	stub := comments[0].Children[0].Children[0]
+
+	loaded, err := c.LoadMoreComments(story, stub)
+	if err != nil {
+		t.Fatalf("LoadMoreComments: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].By != "heidi" {
+		t.Fatalf("loaded = %+v, want heidi's reply", loaded)
+	}
+	if loaded[0].Depth != 2 {
+		t.Errorf("heidi depth = %d, want 2 (the placeholder's depth)", loaded[0].Depth)
+	}
+	if deeper := loaded[0].Children; len(deeper) != 1 || deeper[0].Depth != 3 {
+		t.Errorf("ivan's reply not nested at depth 3: %+v", deeper)
+	}
+}
+
+func TestRedditPostToItem_SelfPost(t *testing.T) {
+	item := redditPostToItem(redditPost{
+		ID:            "p2",
+		Title:         "Ask: favourite tools?",
+		Author:        "asker",
+		Permalink:     "/r/golang/comments/p2/ask/",
+		IsSelf:        true,
+		Selftext:      "What do you use for **profiling** &amp; tracing?\n\n&gt; quoted",
+		LinkFlairText: "discussion",
+	})
+
+	if item.Text != "What do you use for **profiling** & tracing?\n\n> quoted" {
+		t.Errorf("Text = %q, want unescaped selftext", item.Text)
+	}
+	if item.Format != FormatMarkdown {
+		t.Errorf("Format = %v, want Markdown", item.Format)
+	}
+	if len(item.Tags) != 1 || item.Tags[0] != "discussion" {
+		t.Errorf("Tags = %v, want the flair", item.Tags)
+	}
+	if item.URL != "https://www.reddit.com/r/golang/comments/p2/ask/" {
+		t.Errorf("URL = %q, want the permalink", item.URL)
+	}
+
+	comment := parseRedditComment(redditComment{ID: "c1", Author: "alice", Body: "a &lt; b"}, 0)
+	if comment.Text != "a < b" || comment.Format != FormatMarkdown {
+		t.Errorf("comment Text/Format = %q/%v", comment.Text, comment.Format)
+	}
+}
+
+func TestRedditClient_FetchItemNotInFeed(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/comments/p1.json": "reddit_comments.json",
+	})
+	c := newTestRedditClient(srv.URL)
+
+	for _, key := range []string{"p1", "t3_p1"} {
+		item, err := c.FetchItem(key)
+		if err != nil {
+			t.Fatalf("FetchItem(%q) unexpected error: %v", key, err)
+		}
+		if item.Key != "t3_p1" || item.By != "submitter" || c.StoryURL(item) != "https://www.reddit.com/r/golang/comments/p1/a_thread/" {
+			t.Errorf("FetchItem(%q) = %+v", key, item)
+		}
+	}
+}
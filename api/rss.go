@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const rssUserAgent = "feedme:v1.0 (terminal news reader)"
+
+// RSS feed types. A feed URL only ever has a single feed.
+const RSSFeedLatest = ""
+
+var RSSFeedNames = []string{RSSFeedLatest}
+var RSSFeedLabels = []string{"Latest"}
+
+// RSSClient reads an arbitrary RSS 2.0 or Atom feed
+type RSSClient struct {
+	CachedSource
+	http    *http.Client
+	feedURL string
+
+	titleMu sync.RWMutex
+	title   string
+}
+
+// NewRSSClient creates a client for the feed at feedURL
+func NewRSSClient(feedURL string) *RSSClient {
+	return &RSSClient{
+		CachedSource: NewCachedSource(500 * time.Millisecond),
+		http: &http.Client{
+			Timeout: httpTimeout(15 * time.Second),
+		},
+		feedURL: feedURL,
+	}
+}
+
+// Name returns the feed title once known, otherwise the feed's host
+func (c *RSSClient) Name() string {
+	c.titleMu.RLock()
+	defer c.titleMu.RUnlock()
+	if c.title != "" {
+		return c.title
+	}
+	return extractHost(c.feedURL)
+}
+
+// Spec returns the source specification for ParseSource
+func (c *RSSClient) Spec() string {
+	return "rss:" + c.feedURL
+}
+
+// FeedNames returns the available feed names
+func (c *RSSClient) FeedNames() []string {
+	return RSSFeedNames
+}
+
+// FeedLabels returns the display labels for feeds
+func (c *RSSClient) FeedLabels() []string {
+	return RSSFeedLabels
+}
+
+// StoryURL returns the URL for viewing a story
+func (c *RSSClient) StoryURL(item *Item) string {
+	return item.URL
+}
+
+// FetchStoryIDs fetches the feed and returns keys for its entries
+func (c *RSSClient) FetchStoryIDs(feed string) ([]string, error) {
+	parsed, err := c.fetchFeed(c.feedURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.items) == 0 {
+		return nil, fmt.Errorf("no entries found in %s", c.feedURL)
+	}
+
+	c.titleMu.Lock()
+	c.title = parsed.title
+	c.titleMu.Unlock()
+
+	return c.StoreItems(parsed.items), nil
+}
+
+// FetchCommentTree follows the entry's comment feed when it advertises one
+// (wfw:commentRss or an Atom replies link). Entries are returned as a flat,
+// top-level list since feeds carry no threading information.
+func (c *RSSClient) FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error) {
+	if item.commentFeed == "" {
+		return nil, nil
+	}
+
+	parsed, err := c.fetchFeed(item.commentFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*Comment, 0, len(parsed.items))
+	for _, entry := range parsed.items {
+		entry.Type = "comment"
+		comments = append(comments, &Comment{Item: entry})
+	}
+	return comments, nil
+}
+
+func (c *RSSClient) fetchFeed(url string) (*rssFeed, error) {
+	c.Throttle()
+	resp, err := doWithRetry(c.http, url, rssUserAgent, &c.CachedSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	return parseFeed(resp.Body)
+}
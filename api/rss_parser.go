@@ -0,0 +1,233 @@
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// rssFeed is the normalized result of parsing an RSS or Atom document
+type rssFeed struct {
+	title string
+	items []*Item
+}
+
+// rss2Document represents an RSS 2.0 document
+type rss2Document struct {
+	Channel struct {
+		Title string     `xml:"title"`
+		Items []rss2Item `xml:"item"`
+	} `xml:"channel"`
+}
+
+// rss2Item represents an RSS 2.0 item, including the common
+// dc, content, wfw and slash extensions
+type rss2Item struct {
+	Title        string `xml:"title"`
+	Link         string `xml:"link"`
+	GUID         string `xml:"guid"`
+	PubDate      string `xml:"pubDate"`
+	Author       string `xml:"author"`
+	Creator      string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Date         string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description  string `xml:"description"`
+	Content      string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	CommentRSS   string `xml:"http://wellformedweb.org/CommentAPI/ commentRss"`
+	CommentCount string `xml:"http://purl.org/rss/1.0/modules/slash/ comments"`
+}
+
+// atomDocument represents an Atom feed
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry represents an Atom entry
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Links     []atomLink `xml:"link"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Summary string `xml:"summary"`
+	Content string `xml:"content"`
+}
+
+// atomLink represents an Atom link, including the thr:count extension
+// used on replies links
+type atomLink struct {
+	Rel   string `xml:"rel,attr"`
+	Type  string `xml:"type,attr"`
+	Href  string `xml:"href,attr"`
+	Count string `xml:"http://purl.org/syndication/thread/1.0 count,attr"`
+}
+
+// parseFeed detects whether r holds RSS 2.0 or Atom and parses it
+func parseFeed(r io.Reader) (*rssFeed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	root, err := feedRootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		var doc rss2Document
+		if err := decodeFeedXML(data, &doc); err != nil {
+			return nil, err
+		}
+		return parseRSS2(doc), nil
+	case "feed":
+		var doc atomDocument
+		if err := decodeFeedXML(data, &doc); err != nil {
+			return nil, err
+		}
+		return parseAtom(doc), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed format <%s>", root)
+	}
+}
+
+// feedRootElement returns the local name of the document's root element
+func feedRootElement(data []byte) (string, error) {
+	dec := newFeedDecoder(data)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to decode feed: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func decodeFeedXML(data []byte, v any) error {
+	if err := newFeedDecoder(data).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode feed: %w", err)
+	}
+	return nil
+}
+
+func newFeedDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel
+	dec.Strict = false
+	return dec
+}
+
+func parseRSS2(doc rss2Document) *rssFeed {
+	feed := &rssFeed{title: strings.TrimSpace(doc.Channel.Title)}
+	for _, entry := range doc.Channel.Items {
+		if item := rss2ItemToItem(entry); item != nil {
+			feed.items = append(feed.items, item)
+		}
+	}
+	return feed
+}
+
+func rss2ItemToItem(entry rss2Item) *Item {
+	item := &Item{
+		Type:  "story",
+		Title: strings.TrimSpace(entry.Title),
+		URL:   strings.TrimSpace(entry.Link),
+		By:    firstNonEmpty(entry.Creator, entry.Author),
+		Text:  firstNonEmpty(entry.Description, entry.Content),
+	}
+	if item.Title == "" && item.URL == "" {
+		return nil
+	}
+	if item.Title == "" {
+		item.Title = item.URL
+	}
+
+	item.Key = firstNonEmpty(entry.GUID, entry.Link, entry.Title)
+	item.ID = hashShortID(item.Key)
+	item.Time = parseFeedTime(entry.PubDate, entry.Date)
+
+	if commentFeed := strings.TrimSpace(entry.CommentRSS); commentFeed != "" {
+		item.commentFeed = commentFeed
+	}
+	if count, err := strconv.Atoi(strings.TrimSpace(entry.CommentCount)); err == nil {
+		item.Descendants = count
+	}
+	return item
+}
+
+func parseAtom(doc atomDocument) *rssFeed {
+	feed := &rssFeed{title: strings.TrimSpace(doc.Title)}
+	for _, entry := range doc.Entries {
+		if item := atomEntryToItem(entry); item != nil {
+			feed.items = append(feed.items, item)
+		}
+	}
+	return feed
+}
+
+func atomEntryToItem(entry atomEntry) *Item {
+	item := &Item{
+		Type:  "story",
+		Title: strings.TrimSpace(entry.Title),
+		By:    strings.TrimSpace(entry.Author.Name),
+		Text:  firstNonEmpty(entry.Summary, entry.Content),
+	}
+
+	for _, link := range entry.Links {
+		switch link.Rel {
+		case "", "alternate":
+			if item.URL == "" {
+				item.URL = strings.TrimSpace(link.Href)
+			}
+		case "replies":
+			if link.Type == "" || strings.Contains(link.Type, "xml") {
+				item.commentFeed = strings.TrimSpace(link.Href)
+			}
+			if count, err := strconv.Atoi(link.Count); err == nil {
+				item.Descendants = count
+			}
+		}
+	}
+
+	if item.Title == "" && item.URL == "" {
+		return nil
+	}
+	if item.Title == "" {
+		item.Title = item.URL
+	}
+
+	item.Key = firstNonEmpty(entry.ID, item.URL, item.Title)
+	item.ID = hashShortID(item.Key)
+	item.Time = parseFeedTime(entry.Published, entry.Updated)
+	return item
+}
+
+// parseFeedTime returns the first parseable timestamp as a Unix time
+func parseFeedTime(candidates ...string) int64 {
+	for _, s := range candidates {
+		if t, err := parseTime(strings.TrimSpace(s)); err == nil {
+			return t.Unix()
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
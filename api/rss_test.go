@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFixtureServer serves files from testdata, replacing {{server}} with
+// the server's own URL so fixtures can link to each other.
+func newFixtureServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("reading fixture %s: %v", fixture, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(string(data), "{{server}}", srv.URL)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestRSSClient(feedURL string) *RSSClient {
+	c := NewRSSClient(feedURL)
+	c.minDelay = 0
+	return c
+}
+
+func TestRSSClient_RSS2(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/feed.xml":         "rss2.xml",
+		"/comments/101.xml": "rss2_comments.xml",
+	})
+	c := newTestRSSClient(srv.URL + "/feed.xml")
+
+	ids, err := c.FetchStoryIDs(RSSFeedLatest)
+	if err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("FetchStoryIDs returned %d ids, want 2", len(ids))
+	}
+	if got := c.Name(); got != "Example Blog" {
+		t.Errorf("Name() = %q, want %q", got, "Example Blog")
+	}
+
+	items, _ := c.FetchItems(ids)
+	first := items[0]
+	if first.Title != "Generics in Go, one year later" {
+		t.Errorf("Title = %q", first.Title)
+	}
+	if first.URL != "https://blog.example.com/posts/generics" {
+		t.Errorf("URL = %q", first.URL)
+	}
+	if first.By != "Alice" {
+		t.Errorf("By = %q, want %q", first.By, "Alice")
+	}
+	wantTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC).Unix()
+	if first.Time != wantTime {
+		t.Errorf("Time = %d, want %d", first.Time, wantTime)
+	}
+	if !strings.Contains(first.Text, "type parameters") {
+		t.Errorf("Text = %q, want summary", first.Text)
+	}
+	if first.Descendants != 2 {
+		t.Errorf("Descendants = %d, want 2", first.Descendants)
+	}
+	if first.Type != "story" || !first.HasCommentFeed() {
+		t.Errorf("Type = %q, HasCommentFeed = %v; want story with a comment feed", first.Type, first.HasCommentFeed())
+	}
+
+	second := items[1]
+	if second.By != "bob@example.com (Bob)" {
+		t.Errorf("second.By = %q", second.By)
+	}
+	if second.Text != "<p>Full release notes.</p>" {
+		t.Errorf("second.Text = %q, want content:encoded fallback", second.Text)
+	}
+	if second.HasCommentFeed() {
+		t.Error("second.HasCommentFeed() = true, want false")
+	}
+
+	comments, err := c.FetchCommentTree(first, 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("FetchCommentTree returned %d comments, want 2", len(comments))
+	}
+	if comments[1].By != "Dave" || comments[1].Text != "I still miss <i>sum types</i>." {
+		t.Errorf("comments[1] = %+v", comments[1].Item)
+	}
+	if comments[0].Depth != 0 || comments[0].Type != "comment" {
+		t.Errorf("comments[0] depth/type = %d/%q", comments[0].Depth, comments[0].Type)
+	}
+
+	none, err := c.FetchCommentTree(second, 0)
+	if err != nil || len(none) != 0 {
+		t.Errorf("FetchCommentTree without comment feed = %v, %v; want empty", none, err)
+	}
+}
+
+func TestRSSClient_Atom(t *testing.T) {
+	srv := newFixtureServer(t, map[string]string{
+		"/atom.xml":           "atom.xml",
+		"/replies/robots.xml": "atom_replies.xml",
+	})
+	c := newTestRSSClient(srv.URL + "/atom.xml")
+
+	ids, err := c.FetchStoryIDs(RSSFeedLatest)
+	if err != nil {
+		t.Fatalf("FetchStoryIDs unexpected error: %v", err)
+	}
+	items, _ := c.FetchItems(ids)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	first := items[0]
+	if first.URL != "https://atom.example.com/2024/01/robots" {
+		t.Errorf("URL = %q", first.URL)
+	}
+	if first.By != "Erin" || first.Text != "Some text." || first.Descendants != 1 {
+		t.Errorf("first = %+v", first)
+	}
+	if want := time.Date(2024, 1, 15, 18, 30, 2, 0, time.UTC).Unix(); first.Time != want {
+		t.Errorf("Time = %d, want published %d", first.Time, want)
+	}
+
+	second := items[1]
+	if second.Text != "<p>Content only.</p>" {
+		t.Errorf("second.Text = %q", second.Text)
+	}
+	if want := time.Date(2024, 1, 14, 10, 0, 0, 0, time.UTC).Unix(); second.Time != want {
+		t.Errorf("second.Time = %d, want updated %d", second.Time, want)
+	}
+
+	comments, err := c.FetchCommentTree(first, 0)
+	if err != nil {
+		t.Fatalf("FetchCommentTree unexpected error: %v", err)
+	}
+	if len(comments) != 1 || comments[0].By != "Frank" {
+		t.Errorf("FetchCommentTree = %v, want one reply by Frank", comments)
+	}
+}
+
+func TestRSSClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/html":
+			w.Write([]byte("<html><body>not a feed</body></html>"))
+		case "/empty":
+			w.Write([]byte(`<rss version="2.0"><channel><title>Empty</title></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	for _, path := range []string{"/html", "/empty", "/missing"} {
+		c := newTestRSSClient(srv.URL + path)
+		if _, err := c.FetchStoryIDs(RSSFeedLatest); err == nil {
+			t.Errorf("FetchStoryIDs(%s) expected error, got nil", path)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package api
 
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
 // Source represents a news source (HN, Lobste.rs, etc.)
 type Source interface {
 	// Name returns the display name of the source
@@ -11,19 +17,64 @@ type Source interface {
 	// FeedLabels returns the display labels for feeds (for UI tabs)
 	FeedLabels() []string
 
-	// FetchStoryIDs fetches the list of story IDs for a given feed
-	// For sources without IDs (like Lobste.rs), this returns page-based pseudo-IDs
-	FetchStoryIDs(feed string) ([]int, error)
+	// FetchStoryIDs fetches the list of story keys for a given feed.
+	// Keys are the source's own stable identifiers (see Item.Key) and stay
+	// valid across feeds and refreshes.
+	FetchStoryIDs(feed string) ([]string, error)
 
-	// FetchItem fetches a single item by ID
-	FetchItem(id int) (*Item, error)
+	// FetchItem fetches a single item by key
+	FetchItem(id string) (*Item, error)
 
-	// FetchItems fetches multiple items by ID
-	FetchItems(ids []int) ([]*Item, error)
+	// FetchItems fetches multiple items by key
+	FetchItems(ids []string) ([]*Item, error)
 
 	// FetchCommentTree fetches the comment tree for a story
 	FetchCommentTree(item *Item, maxDepth int) ([]*Comment, error)
 
 	// StoryURL returns the URL for viewing a story on the source's website
 	StoryURL(item *Item) string
+
+	// Spec returns the source specification ParseSource accepts to
+	// recreate this source (e.g., "hn", "r/golang")
+	Spec() string
+}
+
+// MoreCommentsLoader is implemented by sources whose comment trees can
+// contain placeholders (see Comment.More) for replies that were not
+// returned with the initial fetch
+type MoreCommentsLoader interface {
+	// LoadMoreComments fetches the replies a placeholder stands for. The
+	// result replaces the placeholder in the tree and may itself contain
+	// further placeholders.
+	LoadMoreComments(story *Item, more *Comment) ([]*Comment, error)
+}
+
+// SourceSpecs describes the source specifications ParseSource accepts
+const SourceSpecs = "hn, hn?q=query, lobsters, r/subreddit, rss:URL, lemmy:community@instance"
+
+// ParseSource builds a Source from a specification such as "hn",
+// "hn?q=rust", "lobsters", "r/golang", "rss:https://example.com/feed.xml"
+// or "lemmy:programming@lemmy.ml"
+func ParseSource(spec string) (Source, error) {
+	lower := strings.ToLower(spec)
+
+	switch {
+	case lower == "hn" || lower == "hackernews" || lower == "hacker-news":
+		return NewClient(), nil
+	case strings.HasPrefix(lower, "hn?"):
+		query, err := url.ParseQuery(spec[len("hn?"):])
+		if err != nil || query.Get("q") == "" {
+			return nil, fmt.Errorf("invalid HN search: %s (expected hn?q=terms)", spec)
+		}
+		return NewHNSearchSource(query.Get("q")), nil
+	case lower == "lobsters" || lower == "lobste.rs" || lower == "l":
+		return NewLobstersClient(), nil
+	case strings.HasPrefix(lower, "r/") || strings.HasPrefix(lower, "/r/"):
+		return NewRedditClient(spec), nil
+	case strings.HasPrefix(lower, "rss:"):
+		return NewRSSClient(spec[len("rss:"):]), nil
+	case strings.HasPrefix(lower, "lemmy:"):
+		return NewLemmyClient(spec[len("lemmy:"):]), nil
+	}
+	return nil, fmt.Errorf("unknown source: %s (valid sources: %s)", spec, SourceSpecs)
 }
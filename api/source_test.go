@@ -0,0 +1,55 @@
+package api
+
+import "testing"
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		spec     string
+		name     string
+		wantSpec string
+	}{
+		{"hn", "HN", "hn"},
+		{"HackerNews", "HN", "hn"},
+		{"hn?q=rust+async", "HN: rust async", "hn?q=rust+async"},
+		{"lobsters", "Lobsters", "lobsters"},
+		{"l", "Lobsters", "lobsters"},
+		{"r/golang", "r/golang", "r/golang"},
+		{"/r/rust", "r/rust", "r/rust"},
+		{"rss:https://example.com/feed.xml", "example.com", "rss:https://example.com/feed.xml"},
+		{"lemmy:programming@lemmy.ml", "!programming@lemmy.ml", "lemmy:programming@lemmy.ml"},
+		{"lemmy:technology", "!technology@lemmy.ml", "lemmy:technology@lemmy.ml"},
+		{"lemmy:local@http://localhost:8536", "!local@localhost:8536", "lemmy:local@http://localhost:8536"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			source, err := ParseSource(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSource(%q): %v", tt.spec, err)
+			}
+			if got := source.Name(); got != tt.name {
+				t.Errorf("Name() = %q, want %q", got, tt.name)
+			}
+			if got := source.Spec(); got != tt.wantSpec {
+				t.Errorf("Spec() = %q, want %q", got, tt.wantSpec)
+			}
+
+			// The spec must recreate an equivalent source
+			again, err := ParseSource(source.Spec())
+			if err != nil {
+				t.Fatalf("ParseSource(Spec()) = %v", err)
+			}
+			if again.Spec() != source.Spec() {
+				t.Errorf("round trip Spec() = %q, want %q", again.Spec(), source.Spec())
+			}
+		})
+	}
+}
+
+func TestParseSource_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "digg", "hn?q="} {
+		if _, err := ParseSource(spec); err == nil {
+			t.Errorf("ParseSource(%q) expected error", spec)
+		}
+	}
+}
@@ -50,7 +50,7 @@ func CheckForUpdate(currentVersion string) *UpdateInfo {
 }
 
 func updateCachePath() (string, error) {
-	cacheDir, err := getCacheDir()
+	cacheDir, err := DataDir()
 	if err != nil {
 		return "", err
 	}
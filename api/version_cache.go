@@ -14,18 +14,21 @@ type updateCache struct {
 	UpdateURL     string    `json:"update_url"`
 }
 
-func getCacheDir() (string, error) {
+// DataDir returns feedme's directory under the user's config directory,
+// creating it if needed. The update check cache, bookmarks and other
+// persistent state live here.
+func DataDir() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
 
-	cacheDir := filepath.Join(configDir, "feedme")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	dataDir := filepath.Join(configDir, "feedme")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return "", err
 	}
 
-	return cacheDir, nil
+	return dataDir, nil
 }
 
 func loadCache(path string) (*updateCache, error) {
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/export"
+)
+
+// runComments implements `fm comments`, which prints a story and its
+// comment tree and exits
+func runComments(args []string) int {
+	return runThreadCommand("comments", args, false)
+}
+
+// runExport implements `fm export`, which saves a story and its comment
+// tree to a file named after the story
+func runExport(args []string) int {
+	return runThreadCommand("export", args, true)
+}
+
+// threadArgs are the arguments of `fm comments` and `fm export`
+type threadArgs struct {
+	spec   string
+	format string
+	output string
+	id     string
+}
+
+// runThreadCommand runs `fm comments` and `fm export`, which differ only
+// in writing to stdout or to a file
+func runThreadCommand(name string, args []string, toFile bool) int {
+	cfg, _, cfgErr := loadConfig()
+	a, ok := parseThreadArgs(name, args, cfg.Source, toFile)
+	if !ok {
+		return 2
+	}
+
+	source, err := commandSource(cfg, cfgErr, a.spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	thread, err := fetchThread(source, a.id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	path := a.output
+	if toFile && path == "" {
+		path = export.FileName(thread, a.format)
+	}
+	if err := writeThread(path, a.format, thread); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if toFile && path != "-" {
+		fmt.Fprintf(os.Stderr, "Exported to %s\n", path)
+	}
+	return 0
+}
+
+// parseThreadArgs parses the arguments shared by `fm comments` and
+// `fm export`. Flags may come before or after the story ID, reporting
+// false after printing the problem.
+func parseThreadArgs(name string, args []string, defaultSpec string, toFile bool) (threadArgs, bool) {
+	var a threadArgs
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&a.spec, "source", defaultSpec, "News source: "+api.SourceSpecs)
+	fs.StringVar(&a.spec, "s", defaultSpec, "News source (shorthand)")
+	fs.StringVar(&a.format, "format", "markdown", "Output format: "+strings.Join(export.Formats, ", "))
+	fs.StringVar(&a.output, "o", "", "Write to this file instead of stdout")
+	if toFile {
+		fs.Lookup("o").Usage = "Write to this file, or - for stdout (default: a file named after the story)"
+	}
+	if err := fs.Parse(args); err != nil {
+		return a, false
+	}
+	// Parsing stops at the story ID, so parse the flags after it too
+	a.id = fs.Arg(0)
+	if fs.NArg() > 0 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return a, false
+		}
+	}
+	if a.format == "md" {
+		a.format = "markdown"
+	}
+	if !slices.Contains(export.Formats, a.format) || a.id == "" || fs.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: fm %s [-s source] [-format %s] [-o file] <story id>\n",
+			name, strings.Join(export.Formats, "|"))
+		return a, false
+	}
+	return a, true
+}
+
+// fetchThread fetches a story by key and its whole comment tree
+func fetchThread(source api.Source, key string) (export.Thread, error) {
+	story, err := source.FetchItem(key)
+	if err != nil {
+		return export.Thread{}, err
+	}
+	comments, err := source.FetchCommentTree(story, 0)
+	if err != nil {
+		return export.Thread{}, err
+	}
+	return export.NewThread(source, story, comments), nil
+}
+
+// writeThread writes a thread in format to path, or to stdout when path
+// is empty or -
+func writeThread(path, format string, thread export.Thread) error {
+	if path == "" || path == "-" {
+		return export.Write(os.Stdout, format, thread)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(f, format, thread); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
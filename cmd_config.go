@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/JonathanWThom/feedme/config"
+	"github.com/JonathanWThom/feedme/ui"
+)
+
+// loadConfig reads the config file and builds the keymap it describes.
+// On error the returned settings are still usable defaults.
+func loadConfig() (config.Config, ui.KeyMap, error) {
+	keys := ui.DefaultKeyMap()
+	path, err := config.Path()
+	if err != nil {
+		return config.Default(), keys, err
+	}
+
+	cfg, err := config.Load(path)
+	cfgErr := &config.Error{Path: path}
+	if err != nil && !errors.As(err, &cfgErr) {
+		return cfg, keys, err
+	}
+	cfgErr.Problems = append(cfgErr.Problems, keys.Apply(cfg.Keys)...)
+	if len(cfgErr.Problems) > 0 {
+		return cfg, ui.DefaultKeyMap(), cfgErr
+	}
+	return cfg, keys, nil
+}
+
+// runConfig implements `fm config check`, which validates the config file
+// and reports every problem found, and `fm config path`
+func runConfig(args []string) int {
+	if len(args) != 1 || (args[0] != "check" && args[0] != "path") {
+		fmt.Fprintln(os.Stderr, "Usage: fm config check|path")
+		return 2
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if args[0] == "path" {
+		fmt.Println(path)
+		return 0
+	}
+
+	if _, _, err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("No config file at %s; using defaults.\n", path)
+		return 0
+	}
+	fmt.Printf("%s is valid.\n", path)
+	return 0
+}
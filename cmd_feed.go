@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+	"github.com/JonathanWThom/feedme/export"
+)
+
+// runFeed implements `fm feed`, which republishes a source's feed,
+// optionally filtered, as an Atom or RSS document
+func runFeed(args []string) int {
+	cfg, _, cfgErr := loadConfig()
+
+	fs := flag.NewFlagSet("feed", flag.ContinueOnError)
+	spec := fs.String("source", cfg.Source, "News source: "+api.SourceSpecs)
+	fs.StringVar(spec, "s", cfg.Source, "News source (shorthand)")
+	feed := fs.String("feed", "", "Feed name or label, e.g. new (default: the source's first feed)")
+	limit := fs.Int("limit", cfg.Fetch.BatchSize, "Number of stories to fetch before filtering, or 0 for the whole feed")
+	format := fs.String("format", "atom", "Output format: atom or rss")
+	output := fs.String("o", "", "Write to this file instead of stdout")
+	addr := fs.String("serve", "", "Serve the feed over HTTP on this address instead, e.g. :8081")
+	ttl := fs.Duration("cache", 5*time.Minute, "How long a served feed is cached")
+	var filter storyFilter
+	fs.Func("tag", "Only stories with this tag; repeat or separate with commas for any of several", func(s string) error {
+		for _, tag := range strings.Split(s, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.tags = append(filter.tags, tag)
+			}
+		}
+		return nil
+	})
+	fs.IntVar(&filter.minScore, "min-score", 0, "Only stories with at least this score")
+	fs.IntVar(&filter.minComments, "min-comments", 0, "Only stories with at least this many comments")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	write, ok := feedFormats[*format]
+	if !ok || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: fm feed [-s source] [-feed name] [-tag t] [-min-score n] [-min-comments n] [-format atom|rss] [-o file | -serve addr]")
+		return 2
+	}
+
+	source, err := commandSource(cfg, cfgErr, *spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *feed == "" && *spec == cfg.Source {
+		*feed = cfg.Feed
+	}
+	render := func() ([]byte, error) {
+		stories, err := fetchStories(source, *feed, *limit)
+		if err != nil {
+			return nil, err
+		}
+		stories = filter.apply(stories)
+		var buf bytes.Buffer
+		err = write(&buf, newFeedInfo(source, *feed, filter, stories), source, stories)
+		return buf.Bytes(), err
+	}
+
+	if *addr != "" {
+		return serveFeed(*addr, *ttl, feedContentTypes[*format], render)
+	}
+	body, err := render()
+	if err == nil {
+		err = writeOutput(*output, body)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeOutput writes body to the file at path, or to stdout when path is
+// empty or -
+func writeOutput(path string, body []byte) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	return os.WriteFile(path, body, 0o644)
+}
+
+// serveFeed serves the document render returns at every path until
+// interrupted, rendering it again once the cached copy is ttl old
+func serveFeed(addr string, ttl time.Duration, contentType string, render func() ([]byte, error)) int {
+	cache := newResponseCache(ttl)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := cache.get("")
+		if !ok {
+			var err error
+			if body, err = render(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			cache.set("", body)
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	})
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "Serving the feed on http://%s (Ctrl+C to stop)\n", addr)
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// storyFilter selects the stories a republished feed keeps
+type storyFilter struct {
+	// tags keeps stories with any of these tags, ignoring case
+	tags        []string
+	minScore    int
+	minComments int
+}
+
+func (f storyFilter) match(story *api.Item) bool {
+	if story.Score < f.minScore || story.Descendants < f.minComments {
+		return false
+	}
+	if len(f.tags) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(story.Tags, func(tag string) bool {
+		return slices.ContainsFunc(f.tags, func(want string) bool { return strings.EqualFold(tag, want) })
+	})
+}
+
+func (f storyFilter) apply(stories []*api.Item) []*api.Item {
+	var kept []*api.Item
+	for _, story := range stories {
+		if f.match(story) {
+			kept = append(kept, story)
+		}
+	}
+	return kept
+}
+
+// query encodes the filter as URL query parameters
+func (f storyFilter) query() string {
+	v := url.Values{}
+	for _, tag := range f.tags {
+		v.Add("tag", tag)
+	}
+	if f.minScore > 0 {
+		v.Set("min_score", fmt.Sprint(f.minScore))
+	}
+	if f.minComments > 0 {
+		v.Set("min_comments", fmt.Sprint(f.minComments))
+	}
+	return v.Encode()
+}
+
+// String describes the filter, e.g. "tagged go · score ≥ 200"
+func (f storyFilter) String() string {
+	var parts []string
+	if len(f.tags) > 0 {
+		parts = append(parts, "tagged "+strings.Join(f.tags, " or "))
+	}
+	if f.minScore > 0 {
+		parts = append(parts, fmt.Sprintf("score ≥ %d", f.minScore))
+	}
+	if f.minComments > 0 {
+		parts = append(parts, fmt.Sprintf("%d+ comments", f.minComments))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// feedInfo describes a republished feed
+type feedInfo struct {
+	Title string
+	// ID identifies the feed independently of where it is published
+	ID string
+	// Link is the source's website
+	Link    string
+	Updated time.Time
+}
+
+// newFeedInfo describes the feed named feed of source holding stories; it
+// was updated when its newest story was posted
+func newFeedInfo(source api.Source, feed string, filter storyFilter, stories []*api.Item) feedInfo {
+	index := 0
+	if feed != "" {
+		index = config.FeedIndex(source, feed)
+	}
+	names, labels := source.FeedNames(), source.FeedLabels()
+	info := feedInfo{Title: source.Name(), ID: "urn:feedme:" + url.PathEscape(source.Spec())}
+	if index >= 0 && index < len(labels) {
+		info.Title += " · " + labels[index]
+		name := names[index]
+		if name == "" {
+			name = strings.ToLower(labels[index])
+		}
+		info.ID += ":" + url.PathEscape(name)
+	}
+	if s := filter.String(); s != "" {
+		info.Title += " · " + s
+		info.ID += "?" + filter.query()
+	}
+	for _, story := range stories {
+		if u, err := url.Parse(source.StoryURL(story)); err == nil && u.Host != "" && info.Link == "" {
+			info.Link = u.Scheme + "://" + u.Host + "/"
+		}
+		if t := time.Unix(story.Time, 0).UTC(); story.Time != 0 && t.After(info.Updated) {
+			info.Updated = t
+		}
+	}
+	if info.Updated.IsZero() {
+		info.Updated = time.Now().UTC().Truncate(time.Second)
+	}
+	return info
+}
+
+// feedFormats are the output formats of `fm feed`
+var feedFormats = map[string]func(w io.Writer, info feedInfo, source api.Source, stories []*api.Item) error{
+	"atom": writeAtom,
+	"rss":  writeRSS,
+}
+
+var feedContentTypes = map[string]string{
+	"atom": "application/atom+xml; charset=utf-8",
+	"rss":  "application/rss+xml; charset=utf-8",
+}
+
+// storySummary returns the HTML describing a story in a feed: its score,
+// comments and links, followed by its text. Only web URLs become links.
+func storySummary(source api.Source, story *api.Item) string {
+	s := fmt.Sprintf(`<p>%d points by %s · `, story.Score, html.EscapeString(story.By))
+	comments := fmt.Sprintf("%d comments", story.Descendants)
+	if discussion := source.StoryURL(story); export.WebURL(discussion) {
+		s += fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(discussion), comments)
+	} else {
+		s += comments
+	}
+	if export.WebURL(story.URL) {
+		s += fmt.Sprintf(` · <a href="%s">%s</a>`, html.EscapeString(story.URL), html.EscapeString(story.Domain()))
+	}
+	s += "</p>\n"
+	if story.Text != "" {
+		s += export.TextHTML(story)
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+// storyLink returns the link a story's feed entry opens: the article, or
+// the discussion for stories without a web URL, or "" if neither is one
+func storyLink(source api.Source, story *api.Item) string {
+	if export.WebURL(story.URL) {
+		return story.URL
+	}
+	if discussion := source.StoryURL(story); export.WebURL(discussion) {
+		return discussion
+	}
+	return ""
+}
+
+// storyID returns the ID of a story's feed entry: its discussion URL, or
+// for stories without one, such as linkless feed entries, a URN made
+// from its key. It reports whether the ID is a URL.
+func storyID(source api.Source, story *api.Item) (string, bool) {
+	if discussion := source.StoryURL(story); export.WebURL(discussion) {
+		return discussion, true
+	}
+	return "urn:feedme:" + url.PathEscape(source.Spec()) + ":" + url.PathEscape(story.Key), false
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",cdata"`
+}
+
+func writeAtom(w io.Writer, info feedInfo, source api.Source, stories []*api.Item) error {
+	feed := atomFeed{
+		Title:     info.Title,
+		ID:        info.ID,
+		Updated:   info.Updated.Format(time.RFC3339),
+		Generator: "feedme",
+	}
+	if info.Link != "" {
+		feed.Link = &atomLink{Href: info.Link}
+	}
+	for _, story := range stories {
+		id, permalink := storyID(source, story)
+		entry := atomEntry{
+			Title:   story.Title,
+			ID:      id,
+			Summary: atomText{Type: "html", Body: storySummary(source, story)},
+		}
+		if link := storyLink(source, story); link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: link})
+		}
+		if permalink {
+			entry.Links = append(entry.Links, atomLink{Rel: "replies", Type: "text/html", Href: id})
+		}
+		// Atom requires an update time; stories without one keep the feed's
+		entry.Updated = feed.Updated
+		if story.Time != 0 {
+			entry.Updated = time.Unix(story.Time, 0).UTC().Format(time.RFC3339)
+			entry.Published = entry.Updated
+		}
+		if story.By != "" {
+			entry.Author = &atomPerson{Name: story.By}
+		}
+		for _, tag := range story.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return writeXML(w, feed)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Comments    string   `xml:"comments,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Categories  []string `xml:"category"`
+	Description rssHTML  `xml:"description"`
+}
+
+type rssHTML struct {
+	Body string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func writeRSS(w io.Writer, info feedInfo, source api.Source, stories []*api.Item) error {
+	doc := rssDocument{
+		Version: "2.0",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       info.Title,
+			Link:        info.Link,
+			Description: info.Title + ", republished by feedme",
+			Generator:   "feedme",
+		},
+	}
+	if !info.Updated.IsZero() {
+		doc.Channel.LastBuildDate = info.Updated.Format(time.RFC1123Z)
+	}
+	for _, story := range stories {
+		id, permalink := storyID(source, story)
+		item := rssItem{
+			Title:       story.Title,
+			Link:        storyLink(source, story),
+			GUID:        rssGUID{IsPermaLink: permalink, Value: id},
+			Creator:     story.By,
+			Categories:  story.Tags,
+			Description: rssHTML{storySummary(source, story)},
+		}
+		if permalink {
+			item.Comments = id
+		}
+		if story.Time != 0 {
+			item.PubDate = time.Unix(story.Time, 0).UTC().Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestFeedFormats(t *testing.T) {
+	source := newFakeSource()
+	stories, err := fetchStories(source, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		filter storyFilter
+	}{
+		{"all", storyFilter{}},
+		{"tagged", storyFilter{tags: []string{"GO", "rust"}, minScore: 100}},
+	} {
+		kept := tt.filter.apply(stories)
+		info := newFeedInfo(source, "Hot", tt.filter, kept)
+		for format, write := range feedFormats {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := write(&buf, info, source, kept); err != nil {
+					t.Fatal(err)
+				}
+				// The document must be well-formed
+				var doc struct{}
+				if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+					t.Fatalf("invalid XML: %v", err)
+				}
+				checkGolden(t, "feed."+tt.name+"."+format+".golden", buf.Bytes())
+			})
+		}
+	}
+}
+
+// feedSource links stories the way RSS sources do: to their own URL,
+// which entries without a link lack
+type feedSource struct{ fakeSource }
+
+func (s feedSource) StoryURL(item *api.Item) string { return item.URL }
+
+func TestFeedFormatsLinklessStory(t *testing.T) {
+	source := feedSource{fakeSource{stories: []*api.Item{
+		{Key: "tag:blog.example,2025:notes/1", Title: "A note without a link", By: "ann", Time: 1760000000, Text: "<p>Just text.</p>"},
+		{Key: "tag:blog.example,2025:notes/2", Title: "Another note", Time: 1760003600},
+	}}}
+	info := newFeedInfo(source, "Hot", storyFilter{}, source.stories)
+	for format, write := range feedFormats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := write(&buf, info, source, source.stories); err != nil {
+				t.Fatal(err)
+			}
+			var doc struct{}
+			if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("invalid XML: %v", err)
+			}
+			checkGolden(t, "feed.linkless."+format+".golden", buf.Bytes())
+		})
+	}
+}
+
+func TestStoryFilter(t *testing.T) {
+	story := &api.Item{Score: 250, Descendants: 10, Tags: []string{"Go", "release"}}
+	for _, tt := range []struct {
+		filter storyFilter
+		want   bool
+		desc   string
+	}{
+		{storyFilter{}, true, ""},
+		{storyFilter{tags: []string{"go"}}, true, "tagged go"},
+		{storyFilter{tags: []string{"rust", "python"}}, false, "tagged rust or python"},
+		{storyFilter{minScore: 200, minComments: 10}, true, "score ≥ 200 · 10+ comments"},
+		{storyFilter{tags: []string{"go"}, minScore: 300}, false, "tagged go · score ≥ 300"},
+	} {
+		if got := tt.filter.match(story); got != tt.want {
+			t.Errorf("%+v matched = %v, want %v", tt.filter, got, tt.want)
+		}
+		if got := tt.filter.String(); got != tt.desc {
+			t.Errorf("%+v described as %q, want %q", tt.filter, got, tt.desc)
+		}
+	}
+}
+
+func TestFeedInfo(t *testing.T) {
+	source := newFakeSource()
+	info := newFeedInfo(source, "new", storyFilter{tags: []string{"go"}, minScore: 5}, source.stories)
+	if info.Title != "Fake · New · tagged go · score ≥ 5" {
+		t.Errorf("Title = %q", info.Title)
+	}
+	if info.ID != "urn:feedme:fake:newest?min_score=5&tag=go" || info.Link != "https://fake.example/" {
+		t.Errorf("ID = %q, Link = %q", info.ID, info.Link)
+	}
+	if !strings.HasPrefix(info.Updated.Format("2006-01-02T15:04:05Z07:00"), "2025-10-09T09:53:20Z") {
+		t.Errorf("Updated = %v, want the newest story's time", info.Updated)
+	}
+}
+
+func TestStorySummaryLinksWebURLsOnly(t *testing.T) {
+	source := newFakeSource()
+	story := &api.Item{Key: "x1", Title: "Click me", URL: "javascript:alert(1)", Descendants: 3}
+	summary := storySummary(source, story)
+	if strings.Contains(summary, "javascript:") {
+		t.Errorf("summary links a javascript: URL: %s", summary)
+	}
+	if !strings.Contains(summary, `<a href="`+source.StoryURL(story)+`">3 comments</a>`) {
+		t.Errorf("summary lost the discussion link: %s", summary)
+	}
+	if got := storyLink(source, story); got != source.StoryURL(story) {
+		t.Errorf("storyLink = %q, want the discussion", got)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+)
+
+// storyFormats are the output formats of `fm list`
+var storyFormats = map[string]func(w io.Writer, source api.Source, stories []*api.Item) error{
+	"text": writeStoriesText,
+	"json": writeStoriesJSON,
+	"tsv":  writeStoriesTSV,
+}
+
+// runList implements `fm list`, which prints the stories of a feed and
+// exits
+func runList(args []string) int {
+	cfg, _, cfgErr := loadConfig()
+
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	spec := fs.String("source", cfg.Source, "News source: "+api.SourceSpecs)
+	fs.StringVar(spec, "s", cfg.Source, "News source (shorthand)")
+	feed := fs.String("feed", "", "Feed name or label, e.g. new (default: the source's first feed)")
+	limit := fs.Int("limit", cfg.Fetch.BatchSize, "Number of stories to print, or 0 for the whole feed")
+	format := fs.String("format", "text", "Output format: text, json or tsv")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	write, ok := storyFormats[*format]
+	if !ok || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: fm list [-s source] [-feed name] [-limit n] [-format text|json|tsv]")
+		return 2
+	}
+
+	source, err := commandSource(cfg, cfgErr, *spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	// The configured feed belongs to the configured source
+	if *feed == "" && *spec == cfg.Source {
+		*feed = cfg.Feed
+	}
+
+	stories, err := fetchStories(source, *feed, *limit)
+	if err == nil {
+		err = write(os.Stdout, source, stories)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// commandSource applies the config's fetch settings and builds the
+// source spec names
+func commandSource(cfg config.Config, cfgErr error, spec string) (api.Source, error) {
+	if cfgErr != nil {
+		return nil, cfgErr
+	}
+	api.HTTPTimeout = cfg.Fetch.Timeout
+	loader, err := api.ParseCommentLoader(cfg.Fetch.HNComments)
+	if err != nil {
+		return nil, err
+	}
+	return newSource(spec, loader)
+}
+
+// newSource builds the source spec names, fetching HN comment trees with
+// loader
+func newSource(spec string, loader api.CommentLoader) (api.Source, error) {
+	source, err := api.ParseSource(spec)
+	if err != nil {
+		return nil, err
+	}
+	api.SetCommentLoader(source, loader)
+	return source, nil
+}
+
+// fetchStories fetches the first limit stories of the feed named or
+// labelled feed, or of the source's first feed
+func fetchStories(source api.Source, feed string, limit int) ([]*api.Item, error) {
+	index := 0
+	if feed != "" {
+		index = config.FeedIndex(source, feed)
+		if index < 0 {
+			return nil, fmt.Errorf("%s has no feed %q (feeds: %s)",
+				source.Name(), feed, strings.Join(source.FeedLabels(), ", "))
+		}
+	}
+
+	ids, err := source.FetchStoryIDs(source.FeedNames()[index])
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	items, err := source.FetchItems(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	stories := make([]*api.Item, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			stories = append(stories, item)
+		}
+	}
+	return stories, nil
+}
+
+// storyJSON is a story as printed by `fm list -format json`
+type storyJSON struct {
+	Key           string     `json:"key"`
+	Title         string     `json:"title"`
+	URL           string     `json:"url,omitempty"`
+	DiscussionURL string     `json:"discussion_url"`
+	By            string     `json:"by"`
+	Score         int        `json:"score"`
+	Comments      int        `json:"comments"`
+	Time          *time.Time `json:"time,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+}
+
+func newStoryJSON(source api.Source, story *api.Item) storyJSON {
+	return storyJSON{
+		Key:           story.Key,
+		Title:         story.Title,
+		URL:           story.URL,
+		DiscussionURL: source.StoryURL(story),
+		By:            story.By,
+		Score:         story.Score,
+		Comments:      story.Descendants,
+		Time:          unixTime(story.Time),
+		Tags:          story.Tags,
+	}
+}
+
+// unixTime converts a Unix time to UTC, or nil when it is unset
+func unixTime(t int64) *time.Time {
+	if t == 0 {
+		return nil
+	}
+	u := time.Unix(t, 0).UTC()
+	return &u
+}
+
+func formatTime(t int64) string {
+	if t == 0 {
+		return "unknown time"
+	}
+	return time.Unix(t, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
+
+func writeStoriesText(w io.Writer, source api.Source, stories []*api.Item) error {
+	for i, story := range stories {
+		title := story.Title
+		if domain := story.Domain(); domain != "" {
+			title += " (" + domain + ")"
+		}
+		meta := fmt.Sprintf("%d points by %s · %d comments · %s",
+			story.Score, story.By, story.Descendants, formatTime(story.Time))
+		if len(story.Tags) > 0 {
+			meta += " · " + strings.Join(story.Tags, ", ")
+		}
+		_, err := fmt.Fprintf(w, "%3d. %s\n     %s\n     %s\n", i+1, title, meta, source.StoryURL(story))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeStoriesJSON(w io.Writer, source api.Source, stories []*api.Item) error {
+	out := make([]storyJSON, len(stories))
+	for i, story := range stories {
+		out[i] = newStoryJSON(source, story)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeStoriesTSV(w io.Writer, source api.Source, stories []*api.Item) error {
+	if _, err := fmt.Fprintln(w, "key\ttitle\turl\tdiscussion_url\tby\tscore\tcomments\ttime\ttags"); err != nil {
+		return err
+	}
+	for _, story := range stories {
+		var when string
+		if t := unixTime(story.Time); t != nil {
+			when = t.Format(time.RFC3339)
+		}
+		fields := []string{
+			story.Key, story.Title, story.URL, source.StoryURL(story), story.By,
+			fmt.Sprint(story.Score), fmt.Sprint(story.Descendants), when, strings.Join(story.Tags, ","),
+		}
+		for i, field := range fields {
+			fields[i] = tsvField(field)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tsvField replaces the tabs and line breaks that would split a field
+func tsvField(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/export"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// fakeSource serves fixed stories and comments
+type fakeSource struct {
+	stories  []*api.Item
+	comments map[string][]*api.Comment
+}
+
+func (s fakeSource) Name() string         { return "Fake" }
+func (s fakeSource) Spec() string         { return "fake" }
+func (s fakeSource) FeedNames() []string  { return []string{"", "newest"} }
+func (s fakeSource) FeedLabels() []string { return []string{"Hot", "New"} }
+
+func (s fakeSource) StoryURL(item *api.Item) string {
+	return "https://fake.example/s/" + item.Key
+}
+
+func (s fakeSource) FetchStoryIDs(feed string) ([]string, error) {
+	var ids []string
+	for _, story := range s.stories {
+		ids = append(ids, story.Key)
+	}
+	if feed == "newest" {
+		ids = ids[len(ids)-1:]
+	}
+	return ids, nil
+}
+
+func (s fakeSource) FetchItem(id string) (*api.Item, error) {
+	for _, story := range s.stories {
+		if story.Key == id {
+			return story, nil
+		}
+	}
+	return nil, fmt.Errorf("story %s not found", id)
+}
+
+func (s fakeSource) FetchItems(ids []string) ([]*api.Item, error) {
+	items := make([]*api.Item, len(ids))
+	for i, id := range ids {
+		items[i], _ = s.FetchItem(id)
+	}
+	return items, nil
+}
+
+func (s fakeSource) FetchCommentTree(item *api.Item, maxDepth int) ([]*api.Comment, error) {
+	return s.comments[item.Key], nil
+}
+
+func newFakeSource() fakeSource {
+	return fakeSource{
+		stories: []*api.Item{
+			{Key: "a1", Title: "Go 1.30 released", URL: "https://go.dev/blog/go1.30", By: "gopher",
+				Score: 120, Descendants: 3, Time: 1760000000, Tags: []string{"go", "release"}},
+			{Key: "b2", Title: "Ask: favourite\ttab-separated tools?", By: "asker",
+				Score: 7, Time: 1760003600, Text: "Which do you use?\n\nI like `cut`.", Format: api.FormatMarkdown},
+			{Key: "c3", Title: "A story with no time", URL: "https://example.com/", By: "nobody"},
+		},
+		comments: map[string][]*api.Comment{
+			"a1": {
+				{
+					Item: &api.Item{Key: "a1-1", By: "alice", Time: 1760000600,
+						Text: `<p>Finally! See <a href="https://go.dev/doc/go1.30">the notes</a>.</p><p>&gt; quoted</p>`},
+					Children: []*api.Comment{
+						{Item: &api.Item{Key: "a1-2", By: "gopher", Time: 1760001200, Text: "Thanks."}, Depth: 1, OP: true},
+						{Item: &api.Item{}, Depth: 1, More: &api.MoreComments{Count: 2, ParentKey: "a1-1"}},
+					},
+				},
+				{Item: &api.Item{Key: "a1-3", Time: 1760001800, Text: "[removed]", Format: api.FormatPlain, Deleted: true}},
+			},
+		},
+	}
+}
+
+// checkGolden compares got with testdata/name, rewriting it under -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from golden file:\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestListFormats(t *testing.T) {
+	source := newFakeSource()
+	stories, err := fetchStories(source, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for format, write := range storyFormats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := write(&buf, source, stories); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "list."+format+".golden", buf.Bytes())
+		})
+	}
+}
+
+func TestFetchStories(t *testing.T) {
+	source := newFakeSource()
+
+	stories, err := fetchStories(source, "New", 0)
+	if err != nil || len(stories) != 1 || stories[0].Key != "c3" {
+		t.Errorf("feed New = %v, %v; want story c3", stories, err)
+	}
+
+	stories, err = fetchStories(source, "", 2)
+	if err != nil || len(stories) != 2 {
+		t.Errorf("limit 2 = %d stories, %v", len(stories), err)
+	}
+
+	_, err = fetchStories(source, "best", 0)
+	if err == nil || !strings.Contains(err.Error(), `Fake has no feed "best" (feeds: Hot, New)`) {
+		t.Errorf("unknown feed error = %v", err)
+	}
+}
+
+func TestCommentsFormats(t *testing.T) {
+	source := newFakeSource()
+	for _, key := range []string{"a1", "b2"} {
+		thread, err := fetchThread(source, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, format := range []string{"markdown", "json"} {
+			t.Run(key+"/"+format, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := export.Write(&buf, format, thread); err != nil {
+					t.Fatal(err)
+				}
+				checkGolden(t, "comments."+key+export.Ext(format)+".golden", buf.Bytes())
+			})
+		}
+	}
+
+	if _, err := fetchThread(source, "zz"); err == nil {
+		t.Error("fetching an unknown story succeeded")
+	}
+}
+
+func TestCommentsFlagsAfterID(t *testing.T) {
+	a, ok := parseThreadArgs("comments", []string{"-s", "fake", "a1", "--format", "json"}, "hn", false)
+	if !ok || a.spec != "fake" || a.id != "a1" || a.format != "json" {
+		t.Fatalf("parsed %+v, %v", a, ok)
+	}
+	thread, err := fetchThread(newFakeSource(), a.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "thread.json")
+	if err := writeThread(path, a.format, thread); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "comments.a1.json.golden", got)
+
+	for _, args := range [][]string{{}, {"a1", "b2"}, {"a1", "-format", "pdf"}} {
+		if _, ok := parseThreadArgs("comments", args, "hn", false); ok {
+			t.Errorf("%q parsed, want a usage error", args)
+		}
+	}
+}
+
+func TestExportFlagsAfterID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.html")
+	a, ok := parseThreadArgs("export", []string{"a1", "-format", "html", "-o", path}, "fake", true)
+	if !ok || a.id != "a1" || a.format != "html" || a.output != path {
+		t.Fatalf("parsed %+v, %v", a, ok)
+	}
+	thread, err := fetchThread(newFakeSource(), a.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeThread(a.output, a.format, thread); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "export.a1.html.golden", got)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+// runSaved implements `fm saved`, which lists bookmarked stories, and
+// `fm saved export`, which writes them as JSON or Markdown
+func runSaved(args []string) int {
+	if len(args) > 0 && args[0] == "export" {
+		return runSavedExport(args[1:])
+	}
+	if len(args) > 0 && args[0] != "list" {
+		fmt.Fprintf(os.Stderr, "Unknown saved command: %s (expected list or export)\n", args[0])
+		return 2
+	}
+
+	bookmarks, err := store.OpenBookmarks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	saved := bookmarks.List()
+	if len(saved) == 0 {
+		fmt.Println("No saved stories. Press B on a story to save it.")
+		return 0
+	}
+	for i, bm := range saved {
+		fmt.Printf("%3d. %s\n", i+1, bm.Item.Title)
+		if bm.Item.URL != "" {
+			fmt.Printf("     %s\n", bm.Item.URL)
+		}
+		fmt.Printf("     %s · saved %s\n", bm.SourceName, bm.SavedAt.Format("2006-01-02"))
+	}
+	return 0
+}
+
+func runSavedExport(args []string) int {
+	fs := flag.NewFlagSet("saved export", flag.ContinueOnError)
+	format := fs.String("format", "json", "Export format: json or markdown")
+	output := fs.String("o", "", "Write to file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	bookmarks, err := store.OpenBookmarks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(bookmarks.List())
+	case "markdown", "md":
+		err = writeSavedMarkdown(w, bookmarks.List())
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format: %s (expected json or markdown)\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func writeSavedMarkdown(w io.Writer, saved []store.Bookmark) error {
+	if _, err := fmt.Fprint(w, "# Saved stories\n\n"); err != nil {
+		return err
+	}
+	for _, bm := range saved {
+		link := bm.Item.URL
+		if link == "" {
+			if source, err := api.ParseSource(bm.Source); err == nil {
+				link = source.StoryURL(bm.Item)
+			}
+		}
+		_, err := fmt.Fprintf(w, "- [%s](%s) — %s, saved %s\n",
+			bm.Item.Title, link, bm.SourceName, bm.SavedAt.Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+	"github.com/JonathanWThom/feedme/export"
+)
+
+// runServe implements `fm serve`, which serves stories and comments from
+// every source as JSON over HTTP until interrupted
+func runServe(args []string) int {
+	cfg, _, cfgErr := loadConfig()
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on, e.g. :8080 for every interface")
+	ttl := fs.Duration("cache", time.Minute, "How long responses are cached, or 0 to not cache them")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: fm serve [-addr host:port] [-cache duration]")
+		return 2
+	}
+	if _, err := commandSource(cfg, cfgErr, cfg.Source); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// commandSource has checked the loader
+	loader, _ := api.ParseCommentLoader(cfg.Fetch.HNComments)
+
+	specs := append([]string{cfg.Source}, cfg.Favorites...)
+	specs = append(specs, "hn", "lobsters")
+	s := newServer(cfg.Source, specs, func(spec string) (api.Source, error) {
+		return newSource(spec, loader)
+	}, *ttl)
+	s.limit = cfg.Fetch.BatchSize
+	srv := &http.Server{Addr: *addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "Serving on http://%s (Ctrl+C to stop)\n", *addr)
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	// Let requests in flight finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// server serves sources' stories and comments as JSON. Each source is
+// created once, so sources that cache stories serve items from feeds
+// fetched earlier.
+type server struct {
+	defaultSpec string
+	// specs are the sources listed by /sources and the only ones served,
+	// so clients cannot make the server fetch URLs of their choosing
+	// through rss: or lemmy: specs
+	specs     []string
+	newSource func(spec string) (api.Source, error)
+	// limit is the number of stories a feed returns by default
+	limit int
+
+	mu      sync.Mutex
+	sources map[string]api.Source
+	cache   *responseCache
+}
+
+func newServer(defaultSpec string, specs []string, newSource func(string) (api.Source, error), ttl time.Duration) *server {
+	var unique []string
+	for _, spec := range specs {
+		if !slices.Contains(unique, spec) {
+			unique = append(unique, spec)
+		}
+	}
+	return &server{
+		defaultSpec: defaultSpec,
+		specs:       unique,
+		newSource:   newSource,
+		limit:       config.Default().Fetch.BatchSize,
+		sources:     make(map[string]api.Source),
+		cache:       newResponseCache(ttl),
+	}
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sources", s.serveJSON(s.handleSources))
+	mux.HandleFunc("GET /sources/{src}/feeds/{feed}", s.serveJSON(s.handleFeed))
+	mux.HandleFunc("GET /items/{id}", s.serveJSON(s.handleItem))
+	mux.HandleFunc("GET /items/{id}/comments", s.serveJSON(s.handleComments))
+	return mux
+}
+
+// httpError is an error with the status code to respond with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+// serveJSON responds with the JSON for what h returns, from the cache
+// when the same request was answered recently
+func (s *server) serveJSON(h func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.RequestURI()
+		if body, ok := s.cache.get(key); ok {
+			writeJSON(w, http.StatusOK, "hit", body)
+			return
+		}
+
+		v, err := h(r)
+		if err != nil {
+			status := http.StatusBadGateway
+			var herr *httpError
+			if errors.As(err, &herr) {
+				status = herr.status
+			}
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			writeJSON(w, status, "", body)
+			return
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.cache.set(key, buf.Bytes())
+		writeJSON(w, http.StatusOK, "miss", buf.Bytes())
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, cache string, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if cache != "" {
+		w.Header().Set("X-Cache", cache)
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// source returns the source for one of the served specs, creating it on
+// first use
+func (s *server) source(spec string) (api.Source, error) {
+	if !slices.Contains(s.specs, spec) {
+		return nil, &httpError{http.StatusNotFound, fmt.Errorf("source %q is not served (served sources: %s)", spec, strings.Join(s.specs, ", "))}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if source, ok := s.sources[spec]; ok {
+		return source, nil
+	}
+	source, err := s.newSource(spec)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err}
+	}
+	s.sources[spec] = source
+	return source, nil
+}
+
+type sourceJSON struct {
+	Spec  string     `json:"spec"`
+	Name  string     `json:"name"`
+	Feeds []feedJSON `json:"feeds"`
+}
+
+type feedJSON struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+func (s *server) handleSources(r *http.Request) (any, error) {
+	out := make([]sourceJSON, 0, len(s.specs))
+	for _, spec := range s.specs {
+		source, err := s.source(spec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, newSourceJSON(source))
+	}
+	return out, nil
+}
+
+func newSourceJSON(source api.Source) sourceJSON {
+	labels := source.FeedLabels()
+	out := sourceJSON{Spec: source.Spec(), Name: source.Name()}
+	for i, name := range source.FeedNames() {
+		feed := feedJSON{Name: name}
+		if i < len(labels) {
+			feed.Label = labels[i]
+		}
+		out.Feeds = append(out.Feeds, feed)
+	}
+	return out
+}
+
+type feedStoriesJSON struct {
+	Source  string      `json:"source"`
+	Spec    string      `json:"spec"`
+	Feed    feedJSON    `json:"feed"`
+	Stories []storyJSON `json:"stories"`
+}
+
+func (s *server) handleFeed(r *http.Request) (any, error) {
+	source, err := s.source(r.PathValue("src"))
+	if err != nil {
+		return nil, err
+	}
+	limit := s.limit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > config.MaxBatchSize {
+			return nil, &httpError{http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", config.MaxBatchSize)}
+		}
+	}
+
+	index := config.FeedIndex(source, r.PathValue("feed"))
+	if index < 0 {
+		return nil, &httpError{http.StatusNotFound, fmt.Errorf("%s has no feed %q", source.Name(), r.PathValue("feed"))}
+	}
+	stories, err := fetchStories(source, source.FeedNames()[index], limit)
+	if err != nil {
+		return nil, err
+	}
+
+	out := feedStoriesJSON{
+		Source:  source.Name(),
+		Spec:    source.Spec(),
+		Feed:    newSourceJSON(source).Feeds[index],
+		Stories: make([]storyJSON, len(stories)),
+	}
+	for i, story := range stories {
+		out.Stories[i] = newStoryJSON(source, story)
+	}
+	return out, nil
+}
+
+// itemSource returns the source named by the request's source parameter,
+// or the default source
+func (s *server) itemSource(r *http.Request) (api.Source, error) {
+	spec := r.URL.Query().Get("source")
+	if spec == "" {
+		spec = s.defaultSpec
+	}
+	return s.source(spec)
+}
+
+func (s *server) handleItem(r *http.Request) (any, error) {
+	source, err := s.itemSource(r)
+	if err != nil {
+		return nil, err
+	}
+	story, err := source.FetchItem(r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return newStoryJSON(source, story), nil
+}
+
+func (s *server) handleComments(r *http.Request) (any, error) {
+	source, err := s.itemSource(r)
+	if err != nil {
+		return nil, err
+	}
+	thread, err := fetchThread(source, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := export.JSON(&buf, thread); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// maxCachedResponses bounds the response cache; expired responses are
+// dropped when it fills up, and all of them if none have expired
+const maxCachedResponses = 1000
+
+// responseCache keeps response bodies by request URI for a while
+type responseCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	now     func() time.Time
+}
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cachedResponse), now: time.Now}
+}
+
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || c.now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *responseCache) set(key string, body []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxCachedResponses {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCachedResponses {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = cachedResponse{body: body, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// countingSource counts the story lists fetched from a fakeSource
+type countingSource struct {
+	fakeSource
+	fetches *atomic.Int32
+}
+
+func (s countingSource) FetchStoryIDs(feed string) ([]string, error) {
+	s.fetches.Add(1)
+	return s.fakeSource.FetchStoryIDs(feed)
+}
+
+func newTestServer(t *testing.T, ttl time.Duration) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	fetches := new(atomic.Int32)
+	newSource := func(spec string) (api.Source, error) {
+		if spec != "fake" {
+			return nil, errors.New("unknown source: " + spec)
+		}
+		return countingSource{newFakeSource(), fetches}, nil
+	}
+	srv := httptest.NewServer(newServer("fake", []string{"fake", "fake"}, newSource, ttl).handler())
+	t.Cleanup(srv.Close)
+	return srv, fetches
+}
+
+// get fetches path from srv, decoding the JSON response into v
+func get(t *testing.T, srv *httptest.Server, path string, v any) *http.Response {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("%s: Content-Type = %q", path, ct)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("%s: %v in %s", path, err, body)
+	}
+	return resp
+}
+
+func TestServeSources(t *testing.T) {
+	srv, _ := newTestServer(t, time.Minute)
+
+	var sources []sourceJSON
+	get(t, srv, "/sources", &sources)
+	if len(sources) != 1 || sources[0].Spec != "fake" || len(sources[0].Feeds) != 2 ||
+		sources[0].Feeds[1] != (feedJSON{Name: "newest", Label: "New"}) {
+		t.Errorf("sources = %+v", sources)
+	}
+}
+
+func TestServeFeed(t *testing.T) {
+	srv, fetches := newTestServer(t, time.Minute)
+
+	var feed feedStoriesJSON
+	resp := get(t, srv, "/sources/fake/feeds/hot?limit=2", &feed)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Cache") != "miss" {
+		t.Errorf("status %d, X-Cache %q", resp.StatusCode, resp.Header.Get("X-Cache"))
+	}
+	if feed.Spec != "fake" || feed.Feed.Label != "Hot" || len(feed.Stories) != 2 || feed.Stories[0].Key != "a1" {
+		t.Errorf("feed = %+v", feed)
+	}
+	if feed.Stories[0].DiscussionURL != "https://fake.example/s/a1" || feed.Stories[0].Score != 120 {
+		t.Errorf("first story = %+v", feed.Stories[0])
+	}
+
+	// The same request is answered from the cache
+	resp = get(t, srv, "/sources/fake/feeds/hot?limit=2", &feed)
+	if resp.Header.Get("X-Cache") != "hit" || fetches.Load() != 1 {
+		t.Errorf("X-Cache %q after %d fetches, want a hit after 1", resp.Header.Get("X-Cache"), fetches.Load())
+	}
+
+	get(t, srv, "/sources/fake/feeds/newest", &feed)
+	if len(feed.Stories) != 1 || feed.Stories[0].Key != "c3" || fetches.Load() != 2 {
+		t.Errorf("newest = %+v after %d fetches", feed.Stories, fetches.Load())
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	srv, _ := newTestServer(t, time.Minute)
+
+	for _, tt := range []struct {
+		path   string
+		status int
+		msg    string
+	}{
+		{"/sources/fake/feeds/best", http.StatusNotFound, `Fake has no feed "best"`},
+		{"/sources/fake/feeds/hot?limit=500", http.StatusBadRequest, "limit must be between 1 and 100"},
+		{"/sources/nope/feeds/hot", http.StatusNotFound, `source "nope" is not served`},
+		{"/sources/r%2Fgolang/feeds/hot", http.StatusNotFound, `source "r/golang" is not served`},
+		{"/sources/rss:http:%2F%2F169.254.169.254%2F/feeds/latest", http.StatusNotFound, "is not served"},
+		{"/items/zz", http.StatusBadGateway, "story zz not found"},
+		{"/items/a1?source=nope", http.StatusNotFound, `source "nope" is not served`},
+		{"/items/a1/comments?source=lemmy:x@internal.example", http.StatusNotFound, "is not served"},
+	} {
+		var body struct{ Error string }
+		resp := get(t, srv, tt.path, &body)
+		if resp.StatusCode != tt.status || !strings.Contains(body.Error, tt.msg) {
+			t.Errorf("%s: %d %q, want %d %q", tt.path, resp.StatusCode, body.Error, tt.status, tt.msg)
+		}
+		if resp.Header.Get("X-Cache") != "" {
+			t.Errorf("%s: error response has X-Cache %q", tt.path, resp.Header.Get("X-Cache"))
+		}
+	}
+
+	resp, err := http.Post(srv.URL+"/sources", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /sources: %d", resp.StatusCode)
+	}
+}
+
+func TestServeItem(t *testing.T) {
+	srv, _ := newTestServer(t, 0)
+
+	var story storyJSON
+	get(t, srv, "/items/a1", &story)
+	if story.Title != "Go 1.30 released" || strings.Join(story.Tags, ",") != "go,release" {
+		t.Errorf("story = %+v", story)
+	}
+
+	var thread struct {
+		Source   string
+		Story    struct{ Key string }
+		Comments []struct {
+			By      string
+			Replies []struct {
+				By   string
+				More *struct{ Count int }
+			}
+		}
+	}
+	resp := get(t, srv, "/items/a1/comments?source=fake", &thread)
+	if resp.Header.Get("X-Cache") != "miss" {
+		t.Errorf("X-Cache = %q", resp.Header.Get("X-Cache"))
+	}
+	if thread.Source != "Fake" || thread.Story.Key != "a1" || len(thread.Comments) != 2 ||
+		thread.Comments[0].Replies[0].By != "gopher" || thread.Comments[0].Replies[1].More.Count != 2 {
+		t.Errorf("thread = %+v", thread)
+	}
+
+	// Caching is off, so the thread is fetched again
+	resp = get(t, srv, "/items/a1/comments?source=fake", &thread)
+	if resp.Header.Get("X-Cache") != "miss" {
+		t.Errorf("X-Cache = %q with caching off", resp.Header.Get("X-Cache"))
+	}
+}
+
+func TestResponseCacheExpires(t *testing.T) {
+	c := newResponseCache(time.Minute)
+	now := time.Unix(1760000000, 0)
+	c.now = func() time.Time { return now }
+
+	c.set("/a", []byte("a"))
+	if body, ok := c.get("/a"); !ok || string(body) != "a" {
+		t.Errorf("get = %q, %v", body, ok)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("/a"); ok {
+		t.Error("expired response served")
+	}
+
+	for i := range maxCachedResponses + 1 {
+		c.set(strings.Repeat("x", i), nil)
+	}
+	if len(c.entries) > maxCachedResponses {
+		t.Errorf("cache holds %d responses", len(c.entries))
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+	"github.com/JonathanWThom/feedme/mute"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+// watchFormats are the output formats of `fm watch`, each writing one
+// match
+var watchFormats = map[string]func(w io.Writer, m watchMatch) error{
+	"text": writeMatchText,
+	"json": writeMatchJSON,
+}
+
+// runWatch implements `fm watch`, which polls sources for stories matching
+// the watchlist and reports each one once, until interrupted
+func runWatch(args []string) int {
+	cfg, _, cfgErr := loadConfig()
+
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	var specs []string
+	sourceFlag := func(s string) error {
+		specs = append(specs, s)
+		return nil
+	}
+	fs.Func("source", "Source to poll; repeat for several (default: watch.sources, or the configured source)", sourceFlag)
+	fs.Func("s", "Source to poll (shorthand)", sourceFlag)
+	feed := fs.String("feed", cfg.Watch.Feed, "Feed to poll by name or label, on sources that have it (others: their first feed)")
+	interval := fs.Duration("interval", cfg.Watch.Interval, "How often to poll")
+	limit := fs.Int("limit", cfg.Fetch.BatchSize, "Number of stories of each feed to check")
+	format := fs.String("format", "text", "Output format: text, or json for one object per line")
+	output := fs.String("o", "", "Append matches to this file instead of printing them")
+	once := fs.Bool("once", false, "Poll once and exit, e.g. from cron")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	write, ok := watchFormats[*format]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Usage: fm watch [-s source]... [-feed name] [-interval d] [-once] [-format text|json] [-o file] [term...]")
+		return 2
+	}
+
+	// Terms on the command line replace the configured ones
+	terms := cfg.Watch.Keywords
+	if fs.NArg() > 0 {
+		terms = fs.Args()
+	}
+	if problems := (mute.Set{Rules: mute.Rules{Keywords: terms}}).Problems(); len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", strings.Join(problems, "; "))
+		return 2
+	}
+	if len(terms) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: nothing to watch: pass terms or set watch.keywords in the config file")
+		return 2
+	}
+	if !*once && *interval < config.MinWatchInterval {
+		fmt.Fprintf(os.Stderr, "Error: -interval must be at least %s\n", config.MinWatchInterval)
+		return 2
+	}
+
+	if len(specs) == 0 {
+		specs = cfg.Watch.Sources
+	}
+	if len(specs) == 0 {
+		specs = []string{cfg.Source}
+	}
+	var sources []api.Source
+	for _, spec := range specs {
+		source, err := commandSource(cfg, cfgErr, spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		sources = append(sources, source)
+	}
+
+	seen, err := store.OpenSeen()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	out := io.Writer(os.Stdout)
+	if *output != "" && *output != "-" {
+		f, err := os.OpenFile(*output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := &watcher{
+		sources:  sources,
+		feed:     *feed,
+		limit:    *limit,
+		keywords: mute.CompileKeywords(terms),
+		seen:     seen,
+		out:      out,
+		log:      os.Stderr,
+		write:    write,
+		now:      time.Now,
+	}
+	w.reportMissingFeeds()
+	if *once {
+		failed, err := w.poll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		if failed > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	fmt.Fprintf(os.Stderr, "Watching %d source(s) for %s every %s (Ctrl+C to stop)\n",
+		len(sources), strings.Join(terms, ", "), *interval)
+	for {
+		if _, err := w.poll(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
+
+// watcher reports stories matching keywords that it has not reported
+// before, remembering them in the seen set across runs
+type watcher struct {
+	sources  []api.Source
+	feed     string
+	limit    int
+	keywords mute.Keywords
+	seen     *store.Seen
+	out      io.Writer
+	// log receives sources that could not be fetched; polling carries on
+	// with the rest
+	log   io.Writer
+	write func(w io.Writer, m watchMatch) error
+	now   func() time.Time
+}
+
+// watchMatch is a story reported by fm watch
+type watchMatch struct {
+	source     api.Source
+	story      *api.Item
+	term       string // the watch term that matched
+	reportedAt time.Time
+}
+
+// poll checks every source once, returning how many could not be fetched.
+// Failing to write a match or save the seen set is an error.
+func (w *watcher) poll() (int, error) {
+	var failed int
+	for _, source := range w.sources {
+		stories, err := fetchStories(source, w.feedOf(source), w.limit)
+		if err != nil {
+			fmt.Fprintf(w.log, "%s: %v\n", source.Name(), err)
+			failed++
+			continue
+		}
+
+		var reported []string
+		for _, story := range stories {
+			term := w.keywords.Match(story)
+			if term == "" || w.seen.Has(source.Spec(), story.Key) {
+				continue
+			}
+			if err := w.write(w.out, watchMatch{source, story, term, w.now()}); err != nil {
+				return failed, err
+			}
+			reported = append(reported, story.Key)
+		}
+		if len(reported) > 0 {
+			if err := w.seen.Add(source.Spec(), reported...); err != nil {
+				return failed, err
+			}
+		}
+	}
+	return failed, nil
+}
+
+// feedOf returns the name of the feed to poll on source: the watched
+// feed, found by name or label so "new" is Lobsters' "newest", or "" for
+// the first feed of sources without it
+func (w *watcher) feedOf(source api.Source) string {
+	if w.feed == "" {
+		return ""
+	}
+	i := config.FeedIndex(source, w.feed)
+	if i < 0 {
+		return ""
+	}
+	return source.FeedNames()[i]
+}
+
+// reportMissingFeeds logs the sources without the watched feed and the
+// feed polled instead
+func (w *watcher) reportMissingFeeds() {
+	if w.feed == "" {
+		return
+	}
+	for _, source := range w.sources {
+		if config.FeedIndex(source, w.feed) < 0 {
+			fmt.Fprintf(w.log, "%s has no feed %q (feeds: %s); polling %s\n",
+				source.Name(), w.feed, strings.Join(source.FeedLabels(), ", "), source.FeedLabels()[0])
+		}
+	}
+}
+
+func writeMatchText(w io.Writer, m watchMatch) error {
+	title := m.story.Title
+	if domain := m.story.Domain(); domain != "" {
+		title += " (" + domain + ")"
+	}
+	_, err := fmt.Fprintf(w, "%s [%s] %s %s (matched %s)\n",
+		m.reportedAt.UTC().Format("2006-01-02 15:04 UTC"), m.source.Name(), title, m.source.StoryURL(m.story), m.term)
+	return err
+}
+
+// watchMatchJSON is a match as printed by `fm watch -format json`
+type watchMatchJSON struct {
+	ReportedAt time.Time `json:"reported_at"`
+	Source     string    `json:"source"`
+	Matched    string    `json:"matched"`
+	storyJSON
+}
+
+func writeMatchJSON(w io.Writer, m watchMatch) error {
+	return json.NewEncoder(w).Encode(watchMatchJSON{
+		ReportedAt: m.reportedAt.UTC(),
+		Source:     m.source.Spec(),
+		Matched:    m.term,
+		storyJSON:  newStoryJSON(m.source, m.story),
+	})
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/mute"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+// failingSource is a source whose feeds cannot be fetched
+type failingSource struct{ fakeSource }
+
+func (s failingSource) Name() string { return "Down" }
+
+func (s failingSource) FetchStoryIDs(feed string) ([]string, error) {
+	return nil, errors.New("connection refused")
+}
+
+func newTestWatcher(t *testing.T, format string, sources ...api.Source) (*watcher, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	seen, err := store.LoadSeen(filepath.Join(t.TempDir(), store.SeenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out, log bytes.Buffer
+	return &watcher{
+		sources:  sources,
+		keywords: mute.CompileKeywords([]string{"/^go\\b/", "no time"}),
+		seen:     seen,
+		out:      &out,
+		log:      &log,
+		write:    watchFormats[format],
+		now:      func() time.Time { return time.Unix(1760007200, 0) },
+	}, &out, &log
+}
+
+func TestWatchFormats(t *testing.T) {
+	for format := range watchFormats {
+		t.Run(format, func(t *testing.T) {
+			w, out, _ := newTestWatcher(t, format, newFakeSource())
+			if _, err := w.poll(); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "watch."+format+".golden", out.Bytes())
+		})
+	}
+}
+
+func TestWatchReportsOnce(t *testing.T) {
+	source := newFakeSource()
+	w, out, log := newTestWatcher(t, "text", source, failingSource{source})
+	path := filepath.Join(t.TempDir(), store.SeenFile)
+	var err error
+	if w.seen, err = store.LoadSeen(path); err != nil {
+		t.Fatal(err)
+	}
+
+	failed, err := w.poll()
+	if err != nil || failed != 1 {
+		t.Fatalf("poll = %d, %v; want 1 failed source", failed, err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Errorf("first poll reported %d stories, want 2:\n%s", n, out)
+	}
+	if !strings.Contains(log.String(), "Down: connection refused") {
+		t.Errorf("log = %q", log)
+	}
+
+	// Only stories not reported before are reported, also after a restart
+	out.Reset()
+	source.stories = append(source.stories, &api.Item{Key: "d4", Title: "Go generics, two years on"})
+	w.sources = []api.Source{source}
+	if w.seen, err = store.LoadSeen(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "Go generics, two years on") {
+		t.Errorf("second poll reported:\n%s", got)
+	}
+}
+
+func TestWatchFeed(t *testing.T) {
+	w, out, log := newTestWatcher(t, "text", newFakeSource())
+	w.feed = "new"
+	if _, err := w.poll(); err != nil {
+		t.Fatal(err)
+	}
+	// The New feed only has the last story
+	if got := out.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "no time") {
+		t.Errorf("reported:\n%s", got)
+	}
+
+	// The feed is found by label on sources that name it differently
+	if got := w.feedOf(api.NewLobstersClient()); got != api.LobstersFeedNewest {
+		t.Errorf("Lobsters feed = %q, want %q", got, api.LobstersFeedNewest)
+	}
+	if got := w.feedOf(api.NewClient()); got != api.FeedNew {
+		t.Errorf("HN feed = %q, want %q", got, api.FeedNew)
+	}
+
+	// Sources without the feed are polled on their first, and reported
+	w.feed = "rising"
+	if w.feedOf(newFakeSource()) != "" {
+		t.Error("missing feed not replaced by the first feed")
+	}
+	w.reportMissingFeeds()
+	if want := `Fake has no feed "rising" (feeds: Hot, New); polling Hot`; !strings.Contains(log.String(), want) {
+		t.Errorf("log = %q, want %q", log, want)
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+// commands maps subcommand names (fm <name> ...) to their entry points,
+// which receive the remaining arguments and return the exit code
+var commands = map[string]func(args []string) int{
+	"comments": runComments,
+	"config":   runConfig,
+	"export":   runExport,
+	"feed":     runFeed,
+	"list":     runList,
+	"saved":    runSaved,
+	"serve":    runServe,
+	"watch":    runWatch,
+}
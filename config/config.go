@@ -0,0 +1,284 @@
+// Package config loads feedme's optional config file, config.toml in the
+// feedme config directory, which sets the default source and feed,
+// favorite sources for the source picker, keybinding overrides, fetch
+// tuning, themes, mute rules and the watchlist.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/mute"
+)
+
+// FileName is the name of the config file in the feedme config directory
+const FileName = "config.toml"
+
+// MaxBatchSize is the largest accepted fetch.batch_size
+const MaxBatchSize = 100
+
+// Config holds the settings read from the config file
+type Config struct {
+	// Source is the source spec used when -s is not given
+	Source string `toml:"source"`
+	// Feed selects the initial feed of Source by name or label
+	Feed string `toml:"feed"`
+	// Favorites are source specs listed first in the source picker
+	Favorites []string `toml:"favorites"`
+	// Keys maps action names (e.g. "next_tab") to the keys bound to them
+	Keys  map[string][]string `toml:"keys"`
+	Fetch Fetch               `toml:"fetch"`
+	Theme Theme               `toml:"theme"`
+	// Themes are user themes, selectable by name in Theme
+	Themes map[string]Palette `toml:"themes"`
+	// Mute hides stories and comments everywhere or from single sources
+	Mute  mute.Set `toml:"mute"`
+	Watch Watch    `toml:"watch"`
+}
+
+// MinWatchInterval is the shortest accepted watch.interval
+const MinWatchInterval = time.Minute
+
+// Watch sets the terms to watch for: matching stories are highlighted and
+// pinned in the app, and reported by fm watch
+type Watch struct {
+	// Keywords match story titles, written like mute keywords
+	Keywords []string `toml:"keywords"`
+	// Sources are the source specs fm watch polls (default: Source)
+	Sources []string `toml:"sources"`
+	// Feed is the feed fm watch polls by name or label, so "new" is each
+	// source's New feed; sources without it are polled on their first feed
+	Feed string `toml:"feed"`
+	// Interval is how often fm watch polls
+	Interval time.Duration `toml:"interval"`
+}
+
+// Theme selects the color theme
+type Theme struct {
+	// Name is auto (dark or light to match the terminal), dark, light,
+	// high-contrast, or the name of a user theme
+	Name string `toml:"name"`
+	// SourceAccent colors the accent after the source being read
+	SourceAccent bool `toml:"source_accent"`
+}
+
+// Palette defines a user theme as colors overriding a built-in theme.
+// Colors are hex ("#ff6600") or ANSI color numbers ("208").
+type Palette struct {
+	Base        string   `toml:"base"`
+	Accent      string   `toml:"accent"`
+	AccentDim   string   `toml:"accent_dim"`
+	OnAccent    string   `toml:"on_accent"`
+	Text        string   `toml:"text"`
+	CommentText string   `toml:"comment_text"`
+	Muted       string   `toml:"muted"`
+	Subtle      string   `toml:"subtle"`
+	StatusBar   string   `toml:"status_bar"`
+	SelectBg    string   `toml:"select_bg"`
+	SelectFg    string   `toml:"select_fg"`
+	Error       string   `toml:"error"`
+	Indent      []string `toml:"indent"`
+}
+
+// colors returns the palette's colors by setting name
+func (p Palette) colors() map[string]string {
+	return map[string]string{
+		"accent": p.Accent, "accent_dim": p.AccentDim, "on_accent": p.OnAccent,
+		"text": p.Text, "comment_text": p.CommentText, "muted": p.Muted,
+		"subtle": p.Subtle, "status_bar": p.StatusBar, "select_bg": p.SelectBg,
+		"select_fg": p.SelectFg, "error": p.Error,
+	}
+}
+
+// BuiltinThemes are the theme names available without a user theme
+var BuiltinThemes = []string{"auto", "dark", "light", "high-contrast"}
+
+// Fetch tunes how stories and comments are fetched
+type Fetch struct {
+	// BatchSize is how many stories are loaded at a time
+	BatchSize int `toml:"batch_size"`
+	// Timeout overrides the HTTP timeout of every source
+	Timeout time.Duration `toml:"timeout"`
+	// HNComments is the HN comment loader: firebase or algolia
+	HNComments string `toml:"hn_comments"`
+}
+
+// Default returns the settings used when there is no config file
+func Default() Config {
+	return Config{
+		Source: "hn",
+		Fetch: Fetch{
+			BatchSize:  30,
+			HNComments: string(api.CommentLoaderFirebase),
+		},
+		Theme: Theme{Name: "auto"},
+		Watch: Watch{Feed: "new", Interval: 10 * time.Minute},
+	}
+}
+
+// Error reports every problem found in a config file
+type Error struct {
+	Path     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config %s:", e.Path)
+	for _, p := range e.Problems {
+		b.WriteString("\n  - " + p)
+	}
+	return b.String()
+}
+
+// Path returns the location of the config file
+func Path() (string, error) {
+	dir, err := api.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the config file at path over the defaults. A missing file is
+// not an error. Invalid settings are reported together as an *Error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	md, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), &Error{Path: path, Problems: []string{err.Error()}}
+	}
+
+	var problems []string
+	for _, key := range md.Undecoded() {
+		problems = append(problems, fmt.Sprintf("unknown setting %q", key.String()))
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, &Error{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// validate checks settings that decode fine but cannot be used
+func (c Config) validate() []string {
+	var problems []string
+
+	source, err := api.ParseSource(c.Source)
+	if err != nil {
+		problems = append(problems, "source: "+err.Error())
+	} else if c.Feed != "" && FeedIndex(source, c.Feed) < 0 {
+		problems = append(problems, fmt.Sprintf("feed: %s has no feed %q (valid feeds: %s)",
+			source.Name(), c.Feed, strings.Join(source.FeedNames(), ", ")))
+	}
+
+	for _, spec := range c.Favorites {
+		if _, err := api.ParseSource(spec); err != nil {
+			problems = append(problems, "favorites: "+err.Error())
+		}
+	}
+
+	actions := make([]string, 0, len(c.Keys))
+	for action := range c.Keys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if len(c.Keys[action]) == 0 {
+			problems = append(problems, fmt.Sprintf("keys.%s: no keys given", action))
+		}
+	}
+
+	if c.Fetch.BatchSize < 1 || c.Fetch.BatchSize > MaxBatchSize {
+		problems = append(problems, fmt.Sprintf("fetch.batch_size: %d is out of range (1-%d)", c.Fetch.BatchSize, MaxBatchSize))
+	}
+	if c.Fetch.Timeout < 0 {
+		problems = append(problems, fmt.Sprintf("fetch.timeout: %s is negative", c.Fetch.Timeout))
+	}
+	if _, err := api.ParseCommentLoader(c.Fetch.HNComments); err != nil {
+		problems = append(problems, "fetch.hn_comments: "+err.Error())
+	}
+
+	if _, ok := c.Themes[c.Theme.Name]; !ok && !slices.Contains(BuiltinThemes, c.Theme.Name) {
+		problems = append(problems, fmt.Sprintf("theme.name: unknown theme %q (valid themes: %s)",
+			c.Theme.Name, strings.Join(append(slices.Clone(BuiltinThemes), slices.Sorted(maps.Keys(c.Themes))...), ", ")))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Themes)) {
+		problems = append(problems, c.Themes[name].validate("themes."+name)...)
+	}
+
+	for _, p := range c.Mute.Problems() {
+		problems = append(problems, "mute."+p)
+	}
+
+	for _, p := range (mute.Set{Rules: mute.Rules{Keywords: c.Watch.Keywords}}).Problems() {
+		problems = append(problems, "watch."+p)
+	}
+	for _, spec := range c.Watch.Sources {
+		if _, err := api.ParseSource(spec); err != nil {
+			problems = append(problems, "watch.sources: "+err.Error())
+		}
+	}
+	if c.Watch.Interval < MinWatchInterval {
+		problems = append(problems, fmt.Sprintf("watch.interval: %s is shorter than %s", c.Watch.Interval, MinWatchInterval))
+	}
+	return problems
+}
+
+func (p Palette) validate(prefix string) []string {
+	var problems []string
+	if p.Base != "" && !slices.Contains(BuiltinThemes, p.Base) {
+		problems = append(problems, fmt.Sprintf("%s.base: %q is not a built-in theme (valid themes: %s)", prefix, p.Base, strings.Join(BuiltinThemes, ", ")))
+	}
+	colors := p.colors()
+	for _, name := range slices.Sorted(maps.Keys(colors)) {
+		if colors[name] != "" && !ValidColor(colors[name]) {
+			problems = append(problems, fmt.Sprintf("%s.%s: invalid color %q (want #rrggbb or 0-255)", prefix, name, colors[name]))
+		}
+	}
+	for _, color := range p.Indent {
+		if !ValidColor(color) {
+			problems = append(problems, fmt.Sprintf("%s.indent: invalid color %q (want #rrggbb or 0-255)", prefix, color))
+		}
+	}
+	return problems
+}
+
+// ValidColor reports whether s is a hex color (#rgb or #rrggbb) or an
+// ANSI color number
+func ValidColor(s string) bool {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// FeedIndex returns the index of the feed of source named or labelled
+// name, ignoring case, or -1 if there is none
+func FeedIndex(source api.Source, name string) int {
+	labels := source.FeedLabels()
+	for i, feed := range source.FeedNames() {
+		if strings.EqualFold(feed, name) || (i < len(labels) && strings.EqualFold(labels[i], name)) {
+			return i
+		}
+	}
+	return -1
+}
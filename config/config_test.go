@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Source != "hn" || cfg.Fetch.BatchSize != 30 {
+		t.Errorf("defaults = %+v", cfg)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+source = "r/golang"
+feed = "Top"
+favorites = ["lobsters", "hn?q=rust"]
+
+[keys]
+quit = ["Q", "ctrl+c"]
+
+[fetch]
+batch_size = 50
+timeout = "20s"
+hn_comments = "algolia"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Source != "r/golang" || cfg.Feed != "Top" {
+		t.Errorf("source = %q, feed = %q", cfg.Source, cfg.Feed)
+	}
+	if strings.Join(cfg.Favorites, ",") != "lobsters,hn?q=rust" {
+		t.Errorf("favorites = %v", cfg.Favorites)
+	}
+	if strings.Join(cfg.Keys["quit"], ",") != "Q,ctrl+c" {
+		t.Errorf("keys = %v", cfg.Keys)
+	}
+	want := Fetch{BatchSize: 50, Timeout: 20 * time.Second, HNComments: "algolia"}
+	if cfg.Fetch != want {
+		t.Errorf("fetch = %+v, want %+v", cfg.Fetch, want)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, `
+source = "r/golang"
+feed = "newest"
+favorites = ["lobsters", "nope"]
+colour = "red"
+
+[keys]
+quit = []
+
+[fetch]
+batch_size = 0
+timeout = "-1s"
+hn_comments = "carrier-pigeon"
+`)
+	_, err := Load(path)
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Load error = %v, want *Error", err)
+	}
+	if cfgErr.Path != path {
+		t.Errorf("error path = %q, want %q", cfgErr.Path, path)
+	}
+	for _, want := range []string{
+		`unknown setting "colour"`,
+		`feed: r/golang has no feed "newest"`,
+		"favorites: unknown source: nope",
+		"keys.quit: no keys given",
+		"fetch.batch_size: 0 is out of range",
+		"fetch.timeout: -1s is negative",
+		`fetch.hn_comments: unknown comment loader "carrier-pigeon"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadSyntaxError(t *testing.T) {
+	path := writeConfig(t, "source = \n")
+	cfg, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Load error = %v, want a syntax error with its line", err)
+	}
+	if cfg.Source != "hn" {
+		t.Errorf("config after syntax error = %+v, want defaults", cfg)
+	}
+}
+
+func TestFeedIndex(t *testing.T) {
+	source := api.NewLobstersClient()
+	for name, want := range map[string]int{"hot": 0, "NEW": 1, "Recent": 2, "top": -1} {
+		if got := FeedIndex(source, name); got != want {
+			t.Errorf("FeedIndex(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	path := writeConfig(t, `
+[theme]
+name = "paper"
+source_accent = true
+
+[themes.paper]
+base = "light"
+accent = "#0055aa"
+indent = ["#0055aa", "27"]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Theme.Name != "paper" || !cfg.Theme.SourceAccent {
+		t.Errorf("theme = %+v", cfg.Theme)
+	}
+	if p := cfg.Themes["paper"]; p.Base != "light" || p.Accent != "#0055aa" || len(p.Indent) != 2 {
+		t.Errorf("themes.paper = %+v", p)
+	}
+}
+
+func TestLoadReportsThemeProblems(t *testing.T) {
+	path := writeConfig(t, `
+[theme]
+name = "sepia"
+
+[themes.paper]
+base = "beige"
+accent = "orange"
+indent = ["#12345"]
+`)
+	_, err := Load(path)
+	for _, want := range []string{
+		`theme.name: unknown theme "sepia" (valid themes: auto, dark, light, high-contrast, paper)`,
+		`themes.paper.base: "beige" is not a built-in theme`,
+		`themes.paper.accent: invalid color "orange"`,
+		`themes.paper.indent: invalid color "#12345"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	for color, want := range map[string]bool{
+		"#ff6600": true, "#F60": true, "208": true, "0": true,
+		"#ff660": false, "#gggggg": false, "256": false, "orange": false, "": false,
+	} {
+		if got := ValidColor(color); got != want {
+			t.Errorf("ValidColor(%q) = %v, want %v", color, got, want)
+		}
+	}
+}
+
+func TestLoadMute(t *testing.T) {
+	path := writeConfig(t, `
+[mute]
+domains = ["example.com"]
+keywords = ["/^ask hn/"]
+
+[mute.sources."r/golang"]
+authors = ["AutoModerator"]
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if strings.Join(cfg.Mute.Domains, ",") != "example.com" || strings.Join(cfg.Mute.Keywords, ",") != "/^ask hn/" {
+		t.Errorf("mute = %+v", cfg.Mute.Rules)
+	}
+	if strings.Join(cfg.Mute.Sources["r/golang"].Authors, ",") != "AutoModerator" {
+		t.Errorf("mute.sources = %+v", cfg.Mute.Sources)
+	}
+
+	path = writeConfig(t, `
+[mute]
+keywords = ["/(/"]
+
+[mute.sources.nope]
+authors = ["x"]
+`)
+	_, err = Load(path)
+	for _, want := range []string{
+		"mute.keywords: invalid regular expression /(/",
+		`mute.sources."nope": unknown source: nope`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadWatch(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+[watch]
+keywords = ["golang", "/\\bzig\\b/"]
+sources = ["hn", "lobsters"]
+interval = "5m"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := Watch{Keywords: []string{"golang", `/\bzig\b/`}, Sources: []string{"hn", "lobsters"}, Feed: "new", Interval: 5 * time.Minute}
+	if strings.Join(cfg.Watch.Keywords, ",") != strings.Join(want.Keywords, ",") ||
+		strings.Join(cfg.Watch.Sources, ",") != strings.Join(want.Sources, ",") ||
+		cfg.Watch.Feed != want.Feed || cfg.Watch.Interval != want.Interval {
+		t.Errorf("watch = %+v, want %+v", cfg.Watch, want)
+	}
+
+	_, err = Load(writeConfig(t, `
+[watch]
+keywords = ["/[/"]
+sources = ["nope"]
+interval = "5s"
+`))
+	for _, want := range []string{
+		"watch.keywords: invalid regular expression /[/",
+		"watch.sources: unknown source: nope",
+		"watch.interval: 5s is shorter than 1m0s",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package export writes a story and its comment thread as Markdown,
+// standalone HTML or JSON, for archiving discussions.
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// Formats are the export format names Write accepts
+var Formats = []string{"markdown", "html", "json"}
+
+// Thread is a story with its comments, ready for export
+type Thread struct {
+	// Source is the display name of the story's source
+	Source string
+	// Spec is the source spec, e.g. "hn" or "r/golang"
+	Spec string
+	// DiscussionURL is the story's page on the source's website
+	DiscussionURL string
+	Story         *api.Item
+	Comments      []*api.Comment
+}
+
+// NewThread returns the thread of a story from source
+func NewThread(source api.Source, story *api.Item, comments []*api.Comment) Thread {
+	return Thread{
+		Source:        source.Name(),
+		Spec:          source.Spec(),
+		DiscussionURL: source.StoryURL(story),
+		Story:         story,
+		Comments:      comments,
+	}
+}
+
+// Write writes a thread in the named format; "md" is accepted for
+// Markdown
+func Write(w io.Writer, format string, t Thread) error {
+	switch format {
+	case "markdown", "md":
+		return Markdown(w, t)
+	case "html":
+		return HTML(w, t)
+	case "json":
+		return JSON(w, t)
+	}
+	return fmt.Errorf("unknown export format %q (expected markdown, html or json)", format)
+}
+
+// Ext returns the file extension for a format, including the dot
+func Ext(format string) string {
+	switch format {
+	case "markdown", "md":
+		return ".md"
+	case "html":
+		return ".html"
+	}
+	return "." + format
+}
+
+// FileName returns a file name for a thread exported in format, made of
+// the source, the story's key and its title, e.g.
+// hn-41234567-show-hn-feedme.md
+func FileName(t Thread, format string) string {
+	parts := []string{slug(t.Spec), slug(t.Story.Key)}
+	if title := slug(t.Story.Title); title != "" {
+		parts = append(parts, title)
+	}
+	name := strings.Join(parts, "-")
+	if len(name) > 80 {
+		name = strings.TrimRight(name[:80], "-")
+	}
+	return name + Ext(format)
+}
+
+// slug lowercases s and joins its words with hyphens
+func slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// Dir returns the directory the interface saves exports in, exports in
+// the feedme config directory
+func Dir() (string, error) {
+	dir, err := api.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "exports"), nil
+}
+
+// Save writes a thread in format to its FileName in dir, creating dir if
+// needed, and returns the file's path
+func Save(dir, format string, t Thread) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, FileName(t, format))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := Write(f, format, t); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// blocks parses an item's text according to its format
+func blocks(item *api.Item) []markup.Block {
+	switch item.Format {
+	case api.FormatMarkdown:
+		return markup.FromMarkdown(item.Text)
+	case api.FormatPlain:
+		var blocks []markup.Block
+		for _, para := range strings.Split(item.Text, "\n\n") {
+			if para = strings.TrimSpace(para); para != "" {
+				blocks = append(blocks, markup.Block{Spans: []markup.Span{{Text: para}}})
+			}
+		}
+		return blocks
+	}
+	return markup.FromHTMLFragment(item.Text)
+}
+
+// author returns a comment's author, or [deleted] when it has none
+func author(c *api.Comment) string {
+	if c.By == "" {
+		return "[deleted]"
+	}
+	return c.By
+}
+
+// moreLabel describes the replies a placeholder comment stands for
+func moreLabel(more *api.MoreComments) string {
+	switch n := max(more.Count, len(more.Keys)); n {
+	case 0:
+		return "thread continues on the site"
+	case 1:
+		return "1 more reply not loaded"
+	default:
+		return fmt.Sprintf("%d more replies not loaded", n)
+	}
+}
+
+// formatTime formats a Unix time in UTC, so exports read the same
+// wherever they are made
+func formatTime(t int64) string {
+	if t == 0 {
+		return "unknown time"
+	}
+	return time.Unix(t, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
+
+// storyMeta returns the story's score, author, comment count, time and
+// tags as words
+func storyMeta(story *api.Item) []string {
+	meta := []string{
+		fmt.Sprintf("%d points", story.Score),
+		"by " + story.By,
+		fmt.Sprintf("%d comments", story.Descendants),
+		formatTime(story.Time),
+	}
+	if len(story.Tags) > 0 {
+		meta = append(meta, strings.Join(story.Tags, ", "))
+	}
+	return meta
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// testThread returns a thread mixing HTML, Markdown and plain comments,
+// nested replies, a deleted comment and a placeholder
+func testThread() Thread {
+	story := &api.Item{
+		Key: "41234567", Title: "Show HN: feedme <a terminal reader>", URL: "https://github.com/JonathanWThom/feedme",
+		By: "jonathan", Score: 120, Descendants: 5, Time: 1760000000, Tags: []string{"go", "tui"},
+		Text: `I built this to read <i>everything</i> in one place.<p>Feedback welcome &amp; appreciated.`,
+	}
+	return Thread{
+		Source:        "Hacker News",
+		Spec:          "hn",
+		DiscussionURL: "https://news.ycombinator.com/item?id=41234567",
+		Story:         story,
+		Comments: []*api.Comment{
+			{
+				Item: &api.Item{Key: "101", By: "alice", Time: 1760000600,
+					Text: `<p>Nice! See <a href="https://go.dev/doc">the docs</a>.</p><p>&gt; one place</p><pre><code>go install ./...
+</code></pre>`},
+				Children: []*api.Comment{
+					{
+						Item:  &api.Item{Key: "102", By: "jonathan", Time: 1760001200, Text: "Thanks, **really**.\n\n- one\n- two", Format: api.FormatMarkdown},
+						Depth: 1, OP: true,
+						Children: []*api.Comment{
+							{Item: &api.Item{Key: "103", By: "bob_", Time: 1760001800, Text: "Agreed <script>alert(1)</script>"}, Depth: 2},
+						},
+					},
+					{Item: &api.Item{}, Depth: 1, More: &api.MoreComments{Count: 2, ParentKey: "101"}},
+				},
+			},
+			{Item: &api.Item{Key: "104", Time: 1760002400, Text: "[deleted]", Format: api.FormatPlain, Deleted: true}},
+		},
+	}
+}
+
+// checkGolden compares got with testdata/name, rewriting it under -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from golden file:\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, testThread()); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "thread"+Ext(format), buf.Bytes())
+		})
+	}
+
+	if err := Write(&bytes.Buffer{}, "pdf", testThread()); err == nil || !strings.Contains(err.Error(), `unknown export format "pdf"`) {
+		t.Errorf("unknown format error = %v", err)
+	}
+}
+
+func TestWrite_NoComments(t *testing.T) {
+	thread := testThread()
+	thread.Comments = nil
+	thread.Story.Text = ""
+
+	var buf bytes.Buffer
+	if err := Markdown(&buf, thread); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Show HN: feedme \\<a terminal reader>\n\n<https://github.com/JonathanWThom/feedme>\n\n" +
+		"120 points · by jonathan · 5 comments · 2025-10-09 08:53 UTC · go, tui · " +
+		"[Hacker News](https://news.ycombinator.com/item?id=41234567)\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "exports")
+	path, err := Save(dir, "md", testThread())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "hn-41234567-show-hn-feedme-a-terminal-reader.md"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(data, []byte("# Show HN")) {
+		t.Errorf("saved file = %q, %v", data, err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	thread := testThread()
+	thread.Spec = "lemmy:rust@programming.dev"
+	thread.Story.Key = "12"
+	thread.Story.Title = "Ünïcode & a title long enough that the file name has to be cut somewhere reasonable"
+	want := "lemmy-rust-programming-dev-12-n-code-a-title-long-enough-that-the-file-name-has.json"
+	if got := FileName(thread, "json"); got != want {
+		t.Errorf("FileName = %s, want %s", got, want)
+	}
+}
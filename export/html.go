@@ -0,0 +1,107 @@
+package export
+
+import (
+	"html"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// htmlStyle styles exported HTML, following the reader's light or dark
+// preference
+const htmlStyle = `body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font: 16px/1.5 system-ui, sans-serif; color: #222; background: #fff; }
+a { color: #c25400; }
+header .meta, summary .time, .more { color: #777; }
+details { margin: .75rem 0 0 0; }
+details details { margin-left: .5rem; padding-left: 1rem; border-left: 2px solid #ddd; }
+summary { cursor: pointer; }
+summary .author { font-weight: bold; }
+.op { color: #c25400; font-size: .85em; }
+.text > :first-child { margin-top: .25rem; }
+blockquote { margin: .5rem 0; padding-left: 1rem; border-left: 3px solid #ddd; color: #555; }
+pre { overflow-x: auto; padding: .5rem; background: #f4f4f4; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: .25rem .5rem; }
+@media (prefers-color-scheme: dark) {
+  body { color: #ddd; background: #1a1a1a; }
+  a, .op { color: #ff8c3a; }
+  details details, blockquote, th, td { border-color: #444; }
+  blockquote { color: #aaa; }
+  pre { background: #262626; }
+}
+`
+
+// HTML writes a thread as a standalone HTML page. Each comment is a
+// <details> element, open to begin with, so threads can be collapsed.
+func HTML(w io.Writer, t Thread) error {
+	var b strings.Builder
+	story := t.Story
+	title := html.EscapeString(story.Title)
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>" + title + "</title>\n<style>\n" + htmlStyle + "</style>\n</head>\n<body>\n")
+
+	b.WriteString("<header>\n<h1>")
+	if WebURL(story.URL) {
+		b.WriteString(`<a href="` + html.EscapeString(story.URL) + `">` + title + "</a>")
+	} else {
+		b.WriteString(title)
+	}
+	b.WriteString("</h1>\n<p class=\"meta\">" + html.EscapeString(strings.Join(storyMeta(story), " · ")))
+	if WebURL(t.DiscussionURL) {
+		b.WriteString(` · <a href="` + html.EscapeString(t.DiscussionURL) + `">` + html.EscapeString(t.Source) + "</a>")
+	}
+	b.WriteString("</p>\n")
+	b.WriteString(TextHTML(story))
+	b.WriteString("</header>\n")
+
+	if len(t.Comments) > 0 {
+		b.WriteString("<hr>\n<main>\n")
+		writeHTMLComments(&b, t.Comments)
+		b.WriteString("</main>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeHTMLComments(b *strings.Builder, comments []*api.Comment) {
+	for _, c := range comments {
+		if c.More != nil {
+			b.WriteString("<p class=\"more\">" + moreLabel(c.More) + "</p>\n")
+			continue
+		}
+
+		b.WriteString("<details open")
+		if c.Key != "" {
+			b.WriteString(` id="c-` + html.EscapeString(c.Key) + `"`)
+		}
+		b.WriteString(">\n<summary><span class=\"author\">" + html.EscapeString(author(c)) + "</span>")
+		if c.OP {
+			b.WriteString(" <span class=\"op\">OP</span>")
+		}
+		b.WriteString(" · <span class=\"time\">" + formatTime(c.Time) + "</span></summary>\n")
+		b.WriteString("<div class=\"text\">\n" + TextHTML(c.Item) + "</div>\n")
+		writeHTMLComments(b, c.Children)
+		b.WriteString("</details>\n")
+	}
+}
+
+// WebURL reports whether u is an http or https URL, which are the only
+// links exported pages and fm feed entries make of story and discussion
+// URLs
+func WebURL(u string) bool {
+	parsed, err := url.Parse(u)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
+
+// TextHTML returns an item's text as an HTML fragment, whatever markup it
+// was written in
+func TextHTML(item *api.Item) string {
+	return markup.ToHTML(blocks(item))
+}
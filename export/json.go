@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// formatNames name text formats in JSON exports
+var formatNames = map[api.TextFormat]string{
+	api.FormatHTML:     "html",
+	api.FormatMarkdown: "markdown",
+	api.FormatPlain:    "plain",
+}
+
+type threadJSON struct {
+	Source   string        `json:"source"`
+	Spec     string        `json:"spec"`
+	Story    storyJSON     `json:"story"`
+	Comments []commentJSON `json:"comments"`
+}
+
+type storyJSON struct {
+	Key           string     `json:"key"`
+	Title         string     `json:"title"`
+	URL           string     `json:"url,omitempty"`
+	DiscussionURL string     `json:"discussion_url"`
+	By            string     `json:"by"`
+	Score         int        `json:"score"`
+	Comments      int        `json:"comments"`
+	Time          *time.Time `json:"time,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Text          string     `json:"text,omitempty"`
+	Format        string     `json:"format,omitempty"`
+}
+
+// commentJSON is a comment and its replies, or, with More set, a
+// placeholder for replies the source left out
+type commentJSON struct {
+	Key     string        `json:"key,omitempty"`
+	By      string        `json:"by,omitempty"`
+	Time    *time.Time    `json:"time,omitempty"`
+	OP      bool          `json:"op,omitempty"`
+	Deleted bool          `json:"deleted,omitempty"`
+	Text    string        `json:"text,omitempty"`
+	Format  string        `json:"format,omitempty"`
+	More    *moreJSON     `json:"more,omitempty"`
+	Replies []commentJSON `json:"replies,omitempty"`
+}
+
+type moreJSON struct {
+	// Count is the number of replies left out, or 0 when unknown
+	Count int `json:"count"`
+}
+
+// JSON writes a thread as a JSON object holding the story and its
+// comment tree. Texts keep the markup they were written in, which
+// format names.
+func JSON(w io.Writer, t Thread) error {
+	story := t.Story
+	out := threadJSON{
+		Source: t.Source,
+		Spec:   t.Spec,
+		Story: storyJSON{
+			Key:           story.Key,
+			Title:         story.Title,
+			URL:           story.URL,
+			DiscussionURL: t.DiscussionURL,
+			By:            story.By,
+			Score:         story.Score,
+			Comments:      story.Descendants,
+			Time:          unixTime(story.Time),
+			Tags:          story.Tags,
+			Text:          story.Text,
+		},
+		Comments: commentsJSON(t.Comments),
+	}
+	if story.Text != "" {
+		out.Story.Format = formatNames[story.Format]
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+func commentsJSON(comments []*api.Comment) []commentJSON {
+	out := make([]commentJSON, 0, len(comments))
+	for _, c := range comments {
+		if c.More != nil {
+			out = append(out, commentJSON{More: &moreJSON{Count: max(c.More.Count, len(c.More.Keys))}})
+			continue
+		}
+		out = append(out, commentJSON{
+			Key:     c.Key,
+			By:      c.By,
+			Time:    unixTime(c.Time),
+			OP:      c.OP,
+			Deleted: c.Deleted || c.Dead,
+			Text:    c.Text,
+			Format:  formatNames[c.Format],
+			Replies: commentsJSON(c.Children),
+		})
+	}
+	return out
+}
+
+// unixTime converts a Unix time to UTC, or nil when it is unset
+func unixTime(t int64) *time.Time {
+	if t == 0 {
+		return nil
+	}
+	u := time.Unix(t, 0).UTC()
+	return &u
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// Markdown writes a thread as a Markdown document: the story's header and
+// text, then the comments as nested lists, each item holding the
+// comment's author, time and text. Comment items are bulleted with "*",
+// which keeps replies from joining a "-" list that ends a comment.
+func Markdown(w io.Writer, t Thread) error {
+	var b strings.Builder
+	story := t.Story
+	b.WriteString("# " + inline(markup.Span{Text: story.Title}) + "\n\n")
+	if story.URL != "" {
+		b.WriteString(inline(markup.Span{Text: story.URL, URL: story.URL}) + "\n\n")
+	}
+	b.WriteString(inline(markup.Span{Text: strings.Join(storyMeta(story), " · ")}) + " · " +
+		inline(markup.Span{Text: t.Source, URL: t.DiscussionURL}) + "\n")
+	if text := markup.ToMarkdown(blocks(story)); text != "" {
+		b.WriteString("\n" + text + "\n")
+	}
+	if len(t.Comments) > 0 {
+		b.WriteString("\n---\n")
+	}
+	writeMarkdownComments(&b, t.Comments, "")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownComments(b *strings.Builder, comments []*api.Comment, indent string) {
+	for _, c := range comments {
+		b.WriteString("\n")
+		if c.More != nil {
+			b.WriteString(indent + "* *" + moreLabel(c.More) + "*\n")
+			continue
+		}
+
+		byline := inline(markup.Span{Text: author(c), Style: markup.Bold})
+		if c.OP {
+			byline += " (OP)"
+		}
+		b.WriteString(indent + "* " + byline + " · " + formatTime(c.Time) + "\n")
+		if text := markup.ToMarkdown(blocks(c.Item)); text != "" {
+			b.WriteString("\n")
+			for _, line := range strings.Split(text, "\n") {
+				if line != "" {
+					line = indent + "  " + line
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+		writeMarkdownComments(b, c.Children, indent+"  ")
+	}
+}
+
+// inline returns a span as Markdown
+func inline(span markup.Span) string {
+	return markup.ToMarkdown([]markup.Block{{Spans: []markup.Span{span}}})
+}
@@ -4,10 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
 	"github.com/JonathanWThom/feedme/ui"
 )
 
@@ -15,12 +16,22 @@ import (
 var version = "dev"
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
+	cfg, keys, cfgErr := loadConfig()
+
 	var sourceFlag string
 	var showVersion bool
-	flag.StringVar(&sourceFlag, "source", "hn", "News source: hn, lobsters, or r/subreddit (e.g., r/golang)")
-	flag.StringVar(&sourceFlag, "s", "hn", "News source (shorthand)")
+	var hnComments string
+	flag.StringVar(&sourceFlag, "source", cfg.Source, "News source: hn, hn?q=query, lobsters, r/subreddit (e.g., r/golang), rss:URL, or lemmy:community@instance")
+	flag.StringVar(&sourceFlag, "s", cfg.Source, "News source (shorthand)")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
 	flag.BoolVar(&showVersion, "v", false, "Show version information (shorthand)")
+	flag.StringVar(&hnComments, "hn-comments", cfg.Fetch.HNComments, "HN comment loader: firebase or algolia (single request)")
 	flag.Parse()
 
 	if showVersion {
@@ -28,30 +39,47 @@ func main() {
 		os.Exit(0)
 	}
 
+	if cfgErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", cfgErr)
+		os.Exit(1)
+	}
+	api.HTTPTimeout = cfg.Fetch.Timeout
+
+	loader, err := api.ParseCommentLoader(hnComments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Check for updates in background
 	updateChan := make(chan *api.UpdateInfo, 1)
 	go func() {
 		updateChan <- api.CheckForUpdate(version)
 	}()
 
-	var source api.Source
-	sourceLower := strings.ToLower(sourceFlag)
-
-	switch {
-	case sourceLower == "hn" || sourceLower == "hackernews" || sourceLower == "hacker-news":
-		source = api.NewClient()
-	case sourceLower == "lobsters" || sourceLower == "lobste.rs" || sourceLower == "l":
-		source = api.NewLobstersClient()
-	case strings.HasPrefix(sourceLower, "r/") || strings.HasPrefix(sourceLower, "/r/"):
-		source = api.NewRedditClient(sourceFlag)
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown source: %s\n", sourceFlag)
-		fmt.Fprintf(os.Stderr, "Valid sources: hn, lobsters, r/subreddit\n")
+	source, err := newSource(sourceFlag, loader)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	opts := ui.Options{
+		Keys:         keys,
+		Favorites:    cfg.Favorites,
+		BatchSize:    cfg.Fetch.BatchSize,
+		Theme:        ui.ConfigTheme(cfg, lipgloss.HasDarkBackground),
+		SourceAccent: cfg.Theme.SourceAccent,
+		Mute:         cfg.Mute,
+		Watch:        cfg.Watch.Keywords,
+		HNComments:   loader,
+	}
+	// The configured feed belongs to the configured source
+	if sourceFlag == cfg.Source && cfg.Feed != "" {
+		opts.Feed = max(config.FeedIndex(source, cfg.Feed), 0)
+	}
+
 	p := tea.NewProgram(
-		ui.NewWithSource(source, updateChan),
+		ui.NewWithOptions(source, updateChan, opts),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
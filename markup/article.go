@@ -0,0 +1,176 @@
+package markup
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ErrNoContent is returned when a page has no readable main content
+var ErrNoContent = errors.New("no readable content found")
+
+var (
+	// Elements that never hold article text
+	junkSelector = "script, style, noscript, template, iframe, form, nav, aside, footer, svg, button, object, embed"
+
+	// Class and id names of page furniture, and of content that might
+	// share a name with it
+	unlikelyNames = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|gdpr|header|menu|modal|newsletter|pager|pagination|popup|promo|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental|widget`)
+	maybeNames    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	positiveNames = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story`)
+	negativeNames = regexp.MustCompile(`(?i)comment|contact|foot|masthead|media|meta|outbrain|promo|related|scroll|shoutbox|sidebar|sponsor|shopping|tags|tool|widget|nav|menu|share|social`)
+)
+
+// ExtractArticle finds the main content of an HTML page, readability
+// style: page furniture is stripped, then the element whose paragraphs
+// hold the most text with the fewest links is taken as the article.
+// Relative links are resolved against pageURL.
+func ExtractArticle(r io.Reader, pageURL string) (*Document, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	base, _ := url.Parse(pageURL)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	article := &Document{
+		Title:  articleTitle(doc),
+		Byline: articleByline(doc),
+	}
+
+	doc.Find(junkSelector).Remove()
+	doc.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "article" || goquery.NodeName(s) == "main" {
+			return
+		}
+		names := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyNames.MatchString(names) && !maybeNames.MatchString(names) {
+			s.Remove()
+		}
+	})
+
+	content := bestCandidate(doc)
+	if content == nil {
+		return nil, ErrNoContent
+	}
+	article.Blocks = FromHTML(content, base)
+
+	// Drop the title when the content repeats it
+	if len(article.Blocks) > 0 && article.Blocks[0].Kind == Heading &&
+		strings.EqualFold(strings.TrimSpace(PlainText(article.Blocks[0].Spans)), article.Title) {
+		article.Blocks = article.Blocks[1:]
+	}
+	if len(article.Blocks) == 0 {
+		return nil, ErrNoContent
+	}
+	return article, nil
+}
+
+func articleTitle(doc *goquery.Document) string {
+	if title, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+	if title := strings.TrimSpace(doc.Find("title").First().Text()); title != "" {
+		return title
+	}
+	return strings.TrimSpace(doc.Find("h1").First().Text())
+}
+
+func articleByline(doc *goquery.Document) string {
+	if author, ok := doc.Find(`meta[name="author"]`).Attr("content"); ok && strings.TrimSpace(author) != "" {
+		return strings.TrimSpace(author)
+	}
+	return strings.Join(strings.Fields(doc.Find(`[rel="author"], .byline, .author`).First().Text()), " ")
+}
+
+// bestCandidate scores the parents of paragraphs by the text they hold and
+// returns the best one, or the body when no paragraph qualifies
+func bestCandidate(doc *goquery.Document) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+	add := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 || goquery.NodeName(s) == "html" {
+			return
+		}
+		n := s.Get(0)
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(s)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	doc.Find("p, pre, td, blockquote").Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		add(p.Parent(), score)
+		add(p.Parent().Parent(), score/2)
+	})
+
+	var best *html.Node
+	bestScore := math.Inf(-1)
+	for _, n := range order {
+		score := scores[n] * (1 - linkDensity(goquery.NewDocumentFromNode(n).Selection))
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		if body := doc.Find("body"); body.Length() > 0 {
+			return body.Get(0)
+		}
+	}
+	return best
+}
+
+func initialScore(s *goquery.Selection) float64 {
+	var score float64
+	switch goquery.NodeName(s) {
+	case "article", "main":
+		score = 10
+	case "div":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+	names := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+	if positiveNames.MatchString(names) {
+		score += 25
+	}
+	if negativeNames.MatchString(names) {
+		score -= 25
+	}
+	return score
+}
+
+// linkDensity is the share of an element's text inside links
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(strings.TrimSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linked += len(strings.TrimSpace(a.Text()))
+	})
+	return float64(linked) / float64(total)
+}
@@ -0,0 +1,123 @@
+package markup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func extractFixture(t *testing.T, name, pageURL string) *Document {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := ExtractArticle(f, pageURL)
+	if err != nil {
+		t.Fatalf("ExtractArticle: %v", err)
+	}
+	return doc
+}
+
+// outline summarizes blocks as one line each, e.g. "h2 Unbuffered channels"
+func outline(blocks []Block) []string {
+	var lines []string
+	for _, b := range blocks {
+		var kind string
+		switch b.Kind {
+		case Heading:
+			kind = "h" + string(rune('0'+b.Level))
+		case ListItem:
+			kind = strings.Repeat("  ", b.Level) + "li " + b.Marker
+		case Code:
+			kind = "code"
+		case Rule:
+			kind = "hr"
+		default:
+			kind = "p"
+		}
+		if b.Quote > 0 {
+			kind = strings.Repeat(">", b.Quote) + " " + kind
+		}
+		text := PlainText(b.Spans)
+		if b.Kind == Code {
+			text = strings.SplitN(b.Text, "\n", 2)[0]
+		}
+		lines = append(lines, kind+" "+text)
+	}
+	return lines
+}
+
+func TestExtractArticleBlog(t *testing.T) {
+	doc := extractFixture(t, "blog.html", "https://blog.example.com/posts/channels")
+
+	if doc.Title != "Understanding Go Channels" {
+		t.Errorf("title = %q", doc.Title)
+	}
+	if doc.Byline != "Ada Gopher" {
+		t.Errorf("byline = %q", doc.Byline)
+	}
+
+	want := []string{
+		"p March 3, 2024",
+		"p Channels are the pipes that connect concurrent goroutines. You can send values into channels from one goroutine and receive those values into another goroutine, as the tour explains.",
+		"h2 Unbuffered channels",
+		"p By default, sends and receives block until the other side is ready. This allows goroutines to synchronize without explicit locks or condition variables.",
+		"code ch := make(chan int)",
+		"h2 Things to remember",
+		"li • Only the sender should close a channel.",
+		"li • Receiving from a closed channel returns the zero value.",
+		"  li 1. Use the v, ok := <-ch form to tell.",
+		"> p Don't communicate by sharing memory; share memory by communicating.",
+		"p Read more in Effective Go.",
+	}
+	got := outline(doc.Blocks)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("blocks:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	code := doc.Blocks[4].Text
+	if !strings.Contains(code, "\tch <- 42\n") {
+		t.Errorf("code block lost its formatting:\n%s", code)
+	}
+
+	var links []string
+	for _, b := range doc.Blocks {
+		for _, s := range b.Spans {
+			if s.URL != "" {
+				links = append(links, s.URL)
+			}
+		}
+	}
+	wantLinks := "https://blog.example.com/tour/concurrency https://go.dev/doc/effective_go#channels"
+	if strings.Join(links, " ") != wantLinks {
+		t.Errorf("links = %v, want %s", links, wantLinks)
+	}
+}
+
+func TestExtractArticleWithoutSemanticMarkup(t *testing.T) {
+	doc := extractFixture(t, "divsoup.html", "https://news.example.com/local/bike-lanes.html")
+
+	text := strings.Join(outline(doc.Blocks), "\n")
+	if !strings.HasPrefix(text, "p BELLINGHAM, Wash.\np The city council voted 6-1") {
+		t.Errorf("article does not start with the story:\n%s", text)
+	}
+	for _, junk := range []string{"Trending", "Sports", "Home"} {
+		if strings.Contains(text, junk) {
+			t.Errorf("article contains navigation text %q:\n%s", junk, text)
+		}
+	}
+	last := doc.Blocks[len(doc.Blocks)-1]
+	if url := last.Spans[len(last.Spans)-2].URL; url != "https://news.example.com/local/plans/bike-lanes.pdf" {
+		t.Errorf("relative link resolved to %q", url)
+	}
+}
+
+func TestExtractArticleNoContent(t *testing.T) {
+	_, err := ExtractArticle(strings.NewReader("<html><body><script>app()</script></body></html>"), "")
+	if err != ErrNoContent {
+		t.Errorf("error = %v, want ErrNoContent", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package markup converts rich text, such as web articles, into a small
+// document model of blocks and styled spans, and renders documents as
+// wrapped, styled terminal lines.
+package markup
+
+import "strings"
+
+// Kind is the type of a Block
+type Kind int
+
+const (
+	Paragraph Kind = iota
+	Heading
+	ListItem
+	Code // preformatted text, never wrapped
+	Rule
+	Table // rows of cells, the first of which is the header
+)
+
+// Block is a paragraph-level element
+type Block struct {
+	Kind Kind
+	// Level is the heading level (1-6), or the nesting depth of a list
+	// item (0 for top-level items)
+	Level int
+	// Marker is a list item's bullet or number, e.g. "•" or "3.". It is
+	// empty for further paragraphs of the same item.
+	Marker string
+	// Quote is how many block quotes the block is nested in
+	Quote int
+	// Spans hold the inline content; Code blocks use Text instead
+	Spans []Span
+	Text  string
+	// Rows are a table's cells
+	Rows [][][]Span
+}
+
+// Style is a set of inline text styles
+type Style uint8
+
+const (
+	Bold Style = 1 << iota
+	Italic
+	Mono
+)
+
+// Span is a run of inline text in a single style
+type Span struct {
+	Text  string
+	Style Style
+	// URL is the target of a link
+	URL string
+}
+
+// Document is an extracted article
+type Document struct {
+	Title  string
+	Byline string
+	Blocks []Block
+}
+
+// PlainText returns the text of spans without styling
+func PlainText(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// Link is a link found in blocks
+type Link struct {
+	URL string
+	// Text is the link's text where it first appears
+	Text string
+}
+
+// Links returns the links in blocks in order of appearance, each once
+func Links(blocks []Block) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+	collect := func(spans []Span) {
+		for i := 0; i < len(spans); i++ {
+			url := spans[i].URL
+			if url == "" {
+				continue
+			}
+			var text strings.Builder
+			for ; i < len(spans) && spans[i].URL == url; i++ {
+				text.WriteString(spans[i].Text)
+			}
+			i--
+			if !seen[url] {
+				seen[url] = true
+				links = append(links, Link{URL: url, Text: strings.TrimSpace(text.String())})
+			}
+		}
+	}
+	for _, b := range blocks {
+		collect(b.Spans)
+		for _, row := range b.Rows {
+			for _, cell := range row {
+				collect(cell)
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,311 @@
+package markup
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FromHTML converts the children of an HTML node to blocks, resolving
+// link targets against base (which may be nil)
+func FromHTML(n *html.Node, base *url.URL) []Block {
+	c := &converter{base: base}
+	c.children(n)
+	c.flush()
+	return c.blocks
+}
+
+// FromHTMLFragment converts an HTML fragment, such as the text of a
+// comment, to blocks. Paragraphs starting with ">" become quotes, since
+// that is how Hacker News comments quote.
+func FromHTMLFragment(s string) []Block {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), body)
+	if err != nil {
+		return []Block{{Spans: []Span{{Text: s}}}}
+	}
+	c := &converter{}
+	for _, n := range nodes {
+		c.node(n)
+	}
+	c.flush()
+	for i := range c.blocks {
+		unquote(&c.blocks[i])
+	}
+	return c.blocks
+}
+
+// unquote turns a paragraph whose text starts with ">" into a quote
+func unquote(b *Block) {
+	if b.Kind != Paragraph || len(b.Spans) == 0 {
+		return
+	}
+	first := &b.Spans[0]
+	for strings.HasPrefix(first.Text, ">") {
+		first.Text = strings.TrimLeft(first.Text[1:], " ")
+		b.Quote++
+	}
+	if first.Text == "" {
+		b.Spans = b.Spans[1:]
+		if len(b.Spans) > 0 {
+			b.Spans[0].Text = strings.TrimLeft(b.Spans[0].Text, " ")
+		}
+	}
+}
+
+type converter struct {
+	base   *url.URL
+	blocks []Block
+
+	// the block being collected
+	spans  []Span
+	kind   Kind
+	level  int
+	marker string
+
+	style Style
+	link  string
+	quote int
+	lists []list
+}
+
+type list struct {
+	ordered bool
+	n       int
+}
+
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Head,
+		atom.Iframe, atom.Svg, atom.Button, atom.Input, atom.Select, atom.Textarea:
+		return
+
+	case atom.Br:
+		c.spans = append(c.spans, Span{Text: "\n"})
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		c.flush()
+		c.kind = Heading
+		c.level = int(n.Data[1] - '0')
+		c.children(n)
+		c.flush()
+
+	case atom.Ul, atom.Ol:
+		c.flush()
+		l := list{ordered: n.DataAtom == atom.Ol, n: 1}
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			l.n = start
+		}
+		c.lists = append(c.lists, l)
+		c.children(n)
+		c.flush()
+		c.lists = c.lists[:len(c.lists)-1]
+
+	case atom.Li:
+		c.flush()
+		if len(c.lists) == 0 {
+			c.lists = append(c.lists, list{n: 1})
+			defer func() { c.lists = c.lists[:len(c.lists)-1] }()
+		}
+		l := &c.lists[len(c.lists)-1]
+		prevKind, prevLevel, prevMarker := c.kind, c.level, c.marker
+		c.kind = ListItem
+		c.level = len(c.lists) - 1
+		c.marker = "•"
+		if l.ordered {
+			c.marker = strconv.Itoa(l.n) + "."
+			l.n++
+		}
+		c.children(n)
+		c.flush()
+		c.kind, c.level, c.marker = prevKind, prevLevel, prevMarker
+
+	case atom.Pre:
+		c.flush()
+		if text := strings.TrimPrefix(textContent(n), "\n"); strings.TrimSpace(text) != "" {
+			c.blocks = append(c.blocks, Block{Kind: Code, Quote: c.quote, Text: text})
+		}
+
+	case atom.Blockquote:
+		c.flush()
+		c.quote++
+		c.children(n)
+		c.flush()
+		c.quote--
+
+	case atom.Hr:
+		c.flush()
+		c.blocks = append(c.blocks, Block{Kind: Rule, Quote: c.quote})
+
+	case atom.Tr:
+		c.flush()
+		first := true
+		for cell := n.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+				continue
+			}
+			if !first {
+				c.spans = append(c.spans, Span{Text: " | "})
+			}
+			first = false
+			c.inline(cell, 0, "")
+		}
+		c.flush()
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header,
+		atom.Footer, atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd,
+		atom.Table, atom.Details, atom.Summary, atom.Aside, atom.Nav, atom.Address:
+		c.flush()
+		c.children(n)
+		c.flush()
+
+	case atom.B, atom.Strong:
+		c.inline(n, Bold, "")
+	case atom.I, atom.Em, atom.Cite, atom.Var:
+		c.inline(n, Italic, "")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		c.inline(n, Mono, "")
+	case atom.A:
+		c.inline(n, 0, c.resolve(attr(n, "href")))
+
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			c.spans = append(c.spans, Span{Text: "[image: " + alt + "]", Style: Italic})
+		}
+
+	default:
+		c.children(n)
+	}
+}
+
+// inline converts the children of n with an added style or link
+func (c *converter) inline(n *html.Node, style Style, link string) {
+	prevStyle, prevLink := c.style, c.link
+	c.style |= style
+	if link != "" {
+		c.link = link
+	}
+	c.children(n)
+	c.style, c.link = prevStyle, prevLink
+}
+
+// text adds text to the current block, collapsing whitespace
+func (c *converter) text(s string) {
+	if s == "" {
+		return
+	}
+	words := strings.Fields(s)
+	separated := len(c.spans) == 0 || endsWithSpace(c.spans[len(c.spans)-1].Text)
+	if len(words) == 0 {
+		if !separated {
+			c.spans = append(c.spans, Span{Text: " ", Style: c.style, URL: c.link})
+		}
+		return
+	}
+
+	text := strings.Join(words, " ")
+	if isSpace(s[0]) && !separated {
+		text = " " + text
+	}
+	if isSpace(s[len(s)-1]) {
+		text += " "
+	}
+	c.spans = append(c.spans, Span{Text: text, Style: c.style, URL: c.link})
+}
+
+// flush ends the current block
+func (c *converter) flush() {
+	spans := c.spans
+	c.spans = nil
+	for len(spans) > 0 && strings.TrimSpace(spans[0].Text) == "" {
+		spans = spans[1:]
+	}
+	for len(spans) > 0 && strings.TrimSpace(spans[len(spans)-1].Text) == "" {
+		spans = spans[:len(spans)-1]
+	}
+	if len(spans) > 0 {
+		spans[0].Text = strings.TrimLeft(spans[0].Text, " ")
+		spans[len(spans)-1].Text = strings.TrimRight(spans[len(spans)-1].Text, " ")
+		c.blocks = append(c.blocks, Block{
+			Kind:   c.kind,
+			Level:  c.level,
+			Marker: c.marker,
+			Quote:  c.quote,
+			Spans:  spans,
+		})
+		// Further paragraphs of a list item continue it without a marker
+		c.marker = ""
+	}
+	if c.kind == Heading {
+		c.kind, c.level = Paragraph, 0
+	}
+}
+
+func (c *converter) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if c.base != nil {
+		u = c.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.DataAtom == atom.Br {
+			b.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+func endsWithSpace(s string) bool {
+	return strings.HasSuffix(s, " ") || strings.HasSuffix(s, "\n")
+}
@@ -0,0 +1,144 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromHTMLFragment(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{
+			name: "hn paragraphs and entities",
+			html: `It&#x27;s not &quot;free&quot; &amp; never was.<p>Second paragraph &lt;here&gt;.`,
+			want: []string{
+				`It's not "free" & never was.`,
+				``,
+				`Second paragraph <here>.`,
+			},
+		},
+		{
+			name: "hn code block keeps its layout",
+			html: `Try this:<p><pre><code>  func main() {
+      fmt.Println(&quot;a line long enough that wrapping it would be wrong&quot;)
+  }
+</code></pre>
+Works for me.`,
+			want: []string{
+				`Try this:`,
+				``,
+				`    func main() {`,
+				`        fmt.Println("a line long enough that wrapping it would be wrong")`,
+				`    }`,
+				``,
+				`Works for me.`,
+			},
+		},
+		{
+			name: "hn quote and italics",
+			html: `&gt; Anything that can go wrong will go wrong.<p>Not <i>always</i>, but often.<p><i>&gt; quoted in italics</i>`,
+			want: []string{
+				`│ Anything that can go wrong will go wrong.`,
+				``,
+				`Not always, but often.`,
+				``,
+				`│ quoted in italics`,
+			},
+		},
+		{
+			name: "hn truncated link",
+			html: `Source: <a href="https:&#x2F;&#x2F;example.com&#x2F;a&#x2F;very&#x2F;long&#x2F;path&#x2F;to&#x2F;an&#x2F;article" rel="nofollow">https:&#x2F;&#x2F;example.com&#x2F;a&#x2F;very&#x2F;long&#x2F;path&#x2F;...</a>`,
+			want: []string{
+				`Source: https://example.com/a/very/long/path/...[1]`,
+				``,
+				`[1] https://example.com/a/very/long/path/to/an/article`,
+			},
+		},
+		{
+			name: "hn full link is not repeated",
+			html: `See <a href="https:&#x2F;&#x2F;go.dev" rel="nofollow">https:&#x2F;&#x2F;go.dev</a> for more.`,
+			want: []string{
+				`See https://go.dev for more.`,
+			},
+		},
+		{
+			name: "lobsters paragraphs, quote and list",
+			html: `<p>I’ve used <code>sqlc</code> for a year.</p>
+<blockquote>
+<p>The best code is no code.</p>
+</blockquote>
+<ul>
+<li>fast</li>
+<li>type safe, see <a href="https://sqlc.dev" rel="ugc">the docs</a> and <a href="https://sqlc.dev" rel="ugc">again</a></li>
+</ul>
+<p>Nested <a href="https://a.example" rel="ugc"><em>styled</em> link</a>.</p>`,
+			want: []string{
+				`I’ve used sqlc for a year.`,
+				``,
+				`│ The best code is no code.`,
+				``,
+				`• fast`,
+				`• type safe, see the docs[1] and again[1]`,
+				``,
+				`Nested styled link[2].`,
+				``,
+				`[1] https://sqlc.dev`,
+				`[2] https://a.example`,
+			},
+		},
+		{
+			name: "lobsters code block in a quote",
+			html: `<blockquote>
+<pre><code>if err != nil {
+	return err
+}
+</code></pre>
+</blockquote>`,
+			want: []string{
+				`│   if err != nil {`,
+				`│       return err`,
+				`│   }`,
+			},
+		},
+		{
+			name: "line breaks",
+			html: `one<br>two<br/>three`,
+			want: []string{
+				`one`,
+				`two`,
+				`three`,
+			},
+		},
+		{
+			name: "empty",
+			html: ``,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(FromHTMLFragment(tt.html), 60, plainStyles())
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFromHTMLFragmentStyles(t *testing.T) {
+	blocks := FromHTMLFragment(`<p>An <i>aside</i> with <code>x := 1</code> and <b>bold</b></p>`)
+	if len(blocks) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(blocks))
+	}
+	styles := map[string]Style{}
+	for _, span := range blocks[0].Spans {
+		styles[strings.TrimSpace(span.Text)] = span.Style
+	}
+	if styles["aside"] != Italic || styles["x := 1"] != Mono || styles["bold"] != Bold {
+		t.Errorf("spans = %+v", blocks[0].Spans)
+	}
+}
@@ -0,0 +1,533 @@
+package markup
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	mdHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRule      = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdListItem  = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	mdFence     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})")
+	mdQuote     = regexp.MustCompile(`^ {0,3}>`)
+	mdTableRule = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// FromMarkdown converts Markdown, as written on Reddit and Lemmy, to
+// blocks. It covers the common subset: headings, emphasis, inline and
+// fenced code, block quotes, lists, tables, rules and links.
+func FromMarkdown(src string) []Block {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	p := &mdParser{}
+	p.parse(strings.Split(src, "\n"), mdContext{})
+	return p.blocks
+}
+
+type mdParser struct {
+	blocks []Block
+}
+
+// mdContext is where blocks are being parsed: inside how many quotes and
+// which list item
+type mdContext struct {
+	quote int
+	// level is the nesting depth of lists started here
+	level int
+	// inItem is set while parsing the content of a list item, whose first
+	// paragraph takes marker
+	inItem bool
+	marker *string
+}
+
+func (p *mdParser) parse(lines []string, ctx mdContext) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case mdFence.MatchString(line):
+			i = p.fenced(lines, i, ctx)
+		case isIndentedCode(line):
+			i = p.indented(lines, i, ctx)
+		case mdHeading.MatchString(line):
+			text := mdHeading.FindStringSubmatch(line)
+			p.add(ctx, Block{Kind: Heading, Level: len(text[1]), Spans: parseInline(text[2])})
+			i++
+		case mdRule.MatchString(line):
+			p.add(ctx, Block{Kind: Rule})
+			i++
+		case mdQuote.MatchString(line):
+			i = p.quote(lines, i, ctx)
+		case mdListItem.MatchString(line):
+			i = p.list(lines, i, ctx)
+		case strings.Contains(line, "|") && i+1 < len(lines) && mdTableRule.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			i = p.table(lines, i, ctx)
+		default:
+			i = p.paragraph(lines, i, ctx)
+		}
+	}
+}
+
+// add appends a block, turning paragraphs inside list items into list
+// item blocks
+func (p *mdParser) add(ctx mdContext, b Block) {
+	b.Quote = ctx.quote
+	if ctx.inItem && b.Kind == Paragraph {
+		b.Kind = ListItem
+		b.Level = ctx.level - 1
+		b.Marker = *ctx.marker
+		*ctx.marker = ""
+	}
+	p.blocks = append(p.blocks, b)
+}
+
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// startsBlock reports whether line interrupts a paragraph
+func startsBlock(line string) bool {
+	return mdFence.MatchString(line) || mdHeading.MatchString(line) || mdRule.MatchString(line) ||
+		mdQuote.MatchString(line) || mdListItem.MatchString(line)
+}
+
+func (p *mdParser) paragraph(lines []string, i int, ctx mdContext) int {
+	start := i
+	for i++; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || startsBlock(lines[i]) {
+			break
+		}
+	}
+	for j := start; j < i; j++ {
+		lines[j] = strings.TrimLeft(lines[j], " \t")
+	}
+	p.add(ctx, Block{Spans: parseInline(strings.Join(lines[start:i], "\n"))})
+	return i
+}
+
+func (p *mdParser) fenced(lines []string, i int, ctx mdContext) int {
+	m := mdFence.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		code = append(code, line)
+	}
+	p.add(ctx, Block{Kind: Code, Text: strings.Join(code, "\n")})
+	return i
+}
+
+func (p *mdParser) indented(lines []string, i int, ctx mdContext) int {
+	var code []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			code = append(code, line[1:])
+		case strings.HasPrefix(line, "    "):
+			code = append(code, line[4:])
+		case strings.TrimSpace(line) == "":
+			code = append(code, "")
+		default:
+			p.add(ctx, Block{Kind: Code, Text: strings.TrimRight(strings.Join(code, "\n"), "\n")})
+			return i
+		}
+	}
+	p.add(ctx, Block{Kind: Code, Text: strings.TrimRight(strings.Join(code, "\n"), "\n")})
+	return i
+}
+
+// quote parses consecutive quoted lines, and unquoted lines continuing a
+// quoted paragraph, as blocks nested one quote deeper
+func (p *mdParser) quote(lines []string, i int, ctx mdContext) int {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if mdQuote.MatchString(line) {
+			line = strings.TrimLeft(line, " ")[1:]
+			line = strings.TrimPrefix(line, " ")
+		} else if strings.TrimSpace(line) == "" || startsBlock(line) ||
+			len(inner) == 0 || strings.TrimSpace(inner[len(inner)-1]) == "" {
+			break
+		}
+		inner = append(inner, line)
+	}
+	inCtx := ctx
+	inCtx.quote++
+	p.parse(inner, inCtx)
+	return i
+}
+
+// list parses a run of list items at the indentation of lines[i]. Lines
+// indented past an item's marker, and lines continuing its paragraph,
+// belong to the item.
+func (p *mdParser) list(lines []string, i int, ctx mdContext) int {
+	first := mdListItem.FindStringSubmatch(lines[i])
+	listIndent := len(first[1])
+	for i < len(lines) {
+		m := mdListItem.FindStringSubmatch(lines[i])
+		if m == nil || len(m[1]) < listIndent || len(m[1]) > listIndent+3 || mdRule.MatchString(lines[i]) {
+			break
+		}
+		contentIndent := len(m[1]) + len(m[2]) + 1
+		content := []string{m[3]}
+		blank := false
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				blank = true
+				content = append(content, "")
+				continue
+			}
+			if indentOf(line) >= contentIndent || (mdListItem.MatchString(line) && indentOf(line) > len(m[1])) {
+				content = append(content, dedent(line, contentIndent))
+				blank = false
+				continue
+			}
+			if !blank && !startsBlock(line) {
+				// A lazy continuation of the item's paragraph
+				content = append(content, line)
+				continue
+			}
+			break
+		}
+
+		marker := listMarker(m[2])
+		itemCtx := mdContext{quote: ctx.quote, level: ctx.level + 1, inItem: true, marker: &marker}
+		p.parse(content, itemCtx)
+		if marker != "" {
+			// An empty item
+			p.add(itemCtx, Block{})
+		}
+	}
+	return i
+}
+
+// listMarker returns the marker to show for a list item's source marker
+func listMarker(m string) string {
+	if n, err := strconv.Atoi(strings.TrimRight(m, ".)")); err == nil {
+		return strconv.Itoa(n) + "."
+	}
+	return "•"
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func dedent(line string, n int) string {
+	for ; n > 0 && strings.HasPrefix(line, " "); n-- {
+		line = line[1:]
+	}
+	return line
+}
+
+// table parses a header row, its delimiter row and the body rows after
+// them
+func (p *mdParser) table(lines []string, i int, ctx mdContext) int {
+	rows := [][][]Span{tableCells(lines[i])}
+	for i += 2; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		rows = append(rows, tableCells(lines[i]))
+	}
+	p.add(ctx, Block{Kind: Table, Rows: rows})
+	return i
+}
+
+func tableCells(line string) [][]Span {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells [][]Span
+	var cell strings.Builder
+	for j := 0; j < len(line); j++ {
+		switch {
+		case line[j] == '\\' && j+1 < len(line) && line[j+1] == '|':
+			cell.WriteByte('|')
+			j++
+		case line[j] == '|':
+			cells = append(cells, parseInline(strings.TrimSpace(cell.String())))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[j])
+		}
+	}
+	return append(cells, parseInline(strings.TrimSpace(cell.String())))
+}
+
+// parseInline converts inline Markdown to spans
+func parseInline(s string) []Span {
+	var p inlineParser
+	p.parse(s, 0, "")
+	return p.spans
+}
+
+type inlineParser struct {
+	spans []Span
+}
+
+func (p *inlineParser) parse(s string, style Style, link string) {
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			p.spans = append(p.spans, Span{Text: text.String(), Style: style, URL: link})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush()
+			p.spans = append(p.spans, Span{Text: "\n"})
+			i += 2
+			continue
+
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '\n':
+			if strings.HasSuffix(text.String(), "  ") {
+				trimmed := strings.TrimRight(text.String(), " ")
+				text.Reset()
+				text.WriteString(trimmed)
+				flush()
+				p.spans = append(p.spans, Span{Text: "\n"})
+			} else {
+				text.WriteByte(' ')
+			}
+			i++
+			continue
+
+		case c == '`':
+			n := runLength(s, i)
+			fence := s[i : i+n]
+			if end := strings.Index(s[i+n:], fence); end >= 0 {
+				flush()
+				code := strings.ReplaceAll(s[i+n:i+n+end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				p.spans = append(p.spans, Span{Text: code, Style: style | Mono, URL: link})
+				i += n + end + n
+				continue
+			}
+			text.WriteString(fence)
+			i += n
+			continue
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i)
+			if end := closingDelimiter(s, i, n); end >= 0 {
+				flush()
+				inner := style
+				switch {
+				case c == '~':
+				case n == 1:
+					inner |= Italic
+				case n == 2:
+					inner |= Bold
+				default:
+					inner |= Bold | Italic
+				}
+				p.parse(s[i+n:end], inner, link)
+				i = end + n
+				continue
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+			continue
+
+		case c == '[' || (c == '!' && i+1 < len(s) && s[i+1] == '['):
+			image := c == '!'
+			open := i
+			if image {
+				open++
+			}
+			if label, target, end, ok := parseLink(s, open); ok {
+				flush()
+				switch {
+				case image:
+					if label == "" {
+						label = "image"
+					}
+					p.spans = append(p.spans, Span{Text: "[" + label + "]", Style: style | Italic, URL: target})
+				case link != "":
+					p.parse(label, style, link)
+				default:
+					p.parse(label, style, target)
+				}
+				i = end
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				target := s[i+1 : i+end]
+				if isURL(target) && !strings.ContainsAny(target, " \t\n") {
+					flush()
+					p.spans = append(p.spans, Span{Text: target, Style: style, URL: target})
+					i += end + 1
+					continue
+				}
+			}
+
+		case c == 'h' && link == "" && (i == 0 || !isWordByte(s[i-1])) && isURL(s[i:]):
+			flush()
+			target := bareURL(s[i:])
+			p.spans = append(p.spans, Span{Text: target, Style: style, URL: target})
+			i += len(target)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		text.WriteRune(r)
+		i += size
+	}
+	flush()
+}
+
+func runLength(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// closingDelimiter finds the run of n delimiters closing the one at i,
+// returning its index or -1. Underscores only delimit at word boundaries,
+// so snake_case stays as it is.
+func closingDelimiter(s string, i, n int) int {
+	c := s[i]
+	if n > 3 || (c == '~' && n != 2) {
+		return -1
+	}
+	start := i + n
+	if start >= len(s) || isSpaceByte(s[start]) {
+		return -1
+	}
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return -1
+	}
+	for j := start + 1; j+n <= len(s); j++ {
+		if s[j] == '`' {
+			// Skip code spans, whose delimiters are literal
+			m := runLength(s, j)
+			if end := strings.Index(s[j+m:], s[j:j+m]); end >= 0 {
+				j += m + end + m - 1
+			}
+			continue
+		}
+		if s[j] != c {
+			continue
+		}
+		// A run of three can close emphasis nested in strong emphasis,
+		// as in **bold *italic***
+		m := runLength(s, j)
+		if (m != n && m != 3) || m < n || isSpaceByte(s[j-1]) ||
+			(c == '_' && j+m < len(s) && isWordByte(s[j+m])) {
+			j += m - 1
+			continue
+		}
+		return j + m - n
+	}
+	return -1
+}
+
+// parseLink parses "[label](target)" at s[i], returning the index after
+// it
+func parseLink(s string, i int) (label, target string, end int, ok bool) {
+	depth := 0
+	closeBracket := -1
+	for j := i; j < len(s) && closeBracket < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeBracket = j
+			}
+		}
+	}
+	if closeBracket < 0 || closeBracket+1 >= len(s) || s[closeBracket+1] != '(' {
+		return "", "", 0, false
+	}
+	depth = 0
+	for j := closeBracket + 1; j < len(s); j++ {
+		switch s[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				target = strings.TrimSpace(s[closeBracket+2 : j])
+				if k := strings.IndexAny(target, " \t\n"); k >= 0 {
+					// Drop a title: [label](url "title")
+					target = target[:k]
+				}
+				target = strings.Trim(target, "<>")
+				return s[i+1 : closeBracket], target, j + 1, target != ""
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// bareURL returns the URL at the start of s, leaving out trailing
+// punctuation and unbalanced closing parentheses
+func bareURL(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '<' })
+	if end < 0 {
+		end = len(s)
+	}
+	u := s[:end]
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		if strings.IndexByte(".,:;!?*_~\"'", last) >= 0 ||
+			(last == ')' && strings.Count(u, "(") < strings.Count(u, ")")) {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	return u
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
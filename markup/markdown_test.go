@@ -0,0 +1,247 @@
+package markup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromMarkdown_Inline(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want []Span
+	}{
+		{
+			name: "emphasis",
+			md:   "plain *italic* **bold** ***both*** _under_ __strong__",
+			want: []Span{
+				{Text: "plain "},
+				{Text: "italic", Style: Italic},
+				{Text: " "},
+				{Text: "bold", Style: Bold},
+				{Text: " "},
+				{Text: "both", Style: Bold | Italic},
+				{Text: " "},
+				{Text: "under", Style: Italic},
+				{Text: " "},
+				{Text: "strong", Style: Bold},
+			},
+		},
+		{
+			name: "nested emphasis",
+			md:   "**bold and *italic***",
+			want: []Span{
+				{Text: "bold and ", Style: Bold},
+				{Text: "italic", Style: Bold | Italic},
+			},
+		},
+		{
+			name: "intraword underscores and lone stars",
+			md:   "snake_case_name and 2 * 3 * 4",
+			want: []Span{{Text: "snake_case_name and 2 * 3 * 4"}},
+		},
+		{
+			name: "strikethrough keeps the text",
+			md:   "~~gone~~ away",
+			want: []Span{{Text: "gone"}, {Text: " away"}},
+		},
+		{
+			name: "code span is literal",
+			md:   "run `go test ./... -run '*_x'` now",
+			want: []Span{
+				{Text: "run "},
+				{Text: "go test ./... -run '*_x'", Style: Mono},
+				{Text: " now"},
+			},
+		},
+		{
+			name: "double backticks",
+			md:   "``a ` tick``",
+			want: []Span{{Text: "a ` tick", Style: Mono}},
+		},
+		{
+			name: "links",
+			md:   `see [the **docs**](https://go.dev/doc "Docs") or <https://go.dev>`,
+			want: []Span{
+				{Text: "see "},
+				{Text: "the ", URL: "https://go.dev/doc"},
+				{Text: "docs", Style: Bold, URL: "https://go.dev/doc"},
+				{Text: " or "},
+				{Text: "https://go.dev", URL: "https://go.dev"},
+			},
+		},
+		{
+			name: "bare URL without trailing punctuation",
+			md:   "read https://en.wikipedia.org/wiki/Go_(programming_language). Then (https://go.dev)",
+			want: []Span{
+				{Text: "read "},
+				{Text: "https://en.wikipedia.org/wiki/Go_(programming_language)", URL: "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+				{Text: ". Then ("},
+				{Text: "https://go.dev", URL: "https://go.dev"},
+				{Text: ")"},
+			},
+		},
+		{
+			name: "image",
+			md:   "![a gopher](https://go.dev/gopher.png)",
+			want: []Span{{Text: "[a gopher]", Style: Italic, URL: "https://go.dev/gopher.png"}},
+		},
+		{
+			name: "escapes",
+			md:   `\*not italic\* and \[not a link\](x)`,
+			want: []Span{{Text: "*not italic* and [not a link](x)"}},
+		},
+		{
+			name: "soft and hard breaks",
+			md:   "one\ntwo  \nthree\\\nfour",
+			want: []Span{
+				{Text: "one two"},
+				{Text: "\n"},
+				{Text: "three"},
+				{Text: "\n"},
+				{Text: "four"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := FromMarkdown(tt.md)
+			if len(blocks) != 1 || blocks[0].Kind != Paragraph {
+				t.Fatalf("got blocks %+v, want one paragraph", blocks)
+			}
+			if !reflect.DeepEqual(blocks[0].Spans, tt.want) {
+				t.Errorf("spans =\n%+v\nwant\n%+v", blocks[0].Spans, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromMarkdown_Blocks(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want []string
+	}{
+		{
+			name: "paragraphs and heading",
+			md:   "# Title #\n\nFirst paragraph\ncontinues here.\n\nSecond.",
+			want: []string{
+				"Title",
+				"",
+				"First paragraph continues here.",
+				"",
+				"Second.",
+			},
+		},
+		{
+			name: "nested quotes",
+			md:   "> outer\nlazy line\n>\n> > inner\n\nafter",
+			want: []string{
+				"│ outer lazy line",
+				"│",
+				"│ │ inner",
+				"",
+				"after",
+			},
+		},
+		{
+			name: "fenced code is not wrapped or parsed",
+			md:   "```go\nfunc f() { return *p } // a comment longer than the width\n```\ntext",
+			want: []string{
+				"  func f() { return *p } // a comment longer than the width",
+				"",
+				"text",
+			},
+		},
+		{
+			name: "indented code",
+			md:   "Example:\n\n    x := 1\n\n    y := 2\n\nDone.",
+			want: []string{
+				"Example:",
+				"",
+				"  x := 1",
+				"  ",
+				"  y := 2",
+				"",
+				"Done.",
+			},
+		},
+		{
+			name: "lists",
+			md:   "* one\n* two\n  continued\n    - nested\n* loose\n\n  second paragraph",
+			want: []string{
+				"• one",
+				"• two continued",
+				"  • nested",
+				"• loose",
+				"",
+				"  second paragraph",
+			},
+		},
+		{
+			name: "ordered list keeps its numbering",
+			md:   "3) three\n4) four\n1. five",
+			want: []string{
+				"3. three",
+				"4. four",
+				"1. five",
+			},
+		},
+		{
+			name: "rule",
+			md:   "above\n\n***\n\nbelow",
+			want: []string{
+				"above",
+				"",
+				strings.Repeat("─", 40),
+				"",
+				"below",
+			},
+		},
+		{
+			name: "table",
+			md:   "| Lang | Year |\n|:-----|-----:|\n| Go | 2009 |\n| C | 1972 |",
+			want: []string{
+				"Lang │ Year",
+				"─────┼─────",
+				"Go   │ 2009",
+				"C    │ 1972",
+			},
+		},
+		{
+			name: "links become footnotes",
+			md:   "A [link](https://example.com) and https://go.dev",
+			want: []string{
+				"A link[1] and https://go.dev",
+				"",
+				"[1] https://example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(FromMarkdown(tt.md), 50, plainStyles())
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestRender_NarrowTable(t *testing.T) {
+	blocks := FromMarkdown("| Name | Description |\n|---|---|\n| feedme | a terminal reader for news aggregators |")
+	got := Render(blocks, 20, plainStyles())
+	want := []string{
+		"Name | Description",
+		"feedme | a",
+		"  terminal reader",
+		"  for news",
+		"  aggregators",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,308 @@
+package markup
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles are the terminal styles documents are rendered with
+type Styles struct {
+	Text    lipgloss.Style
+	Heading lipgloss.Style
+	Code    lipgloss.Style
+	Link    lipgloss.Style
+	Quote   lipgloss.Style // block quote bars
+	Muted   lipgloss.Style // list markers, rules and footnote numbers
+}
+
+// Render lays blocks out as lines at most width columns wide; code blocks
+// keep their lines as they are. Links are numbered as footnotes, which
+// are listed after the text.
+func Render(blocks []Block, width int, st Styles) []string {
+	r := &renderer{width: max(width, 20), st: st, footnotes: make(map[string]int)}
+	for i, b := range blocks {
+		if i > 0 && !(b.Kind == ListItem && blocks[i-1].Kind == ListItem && b.Marker != "") {
+			r.lines = append(r.lines, strings.TrimRight(r.quotePrefix(min(b.Quote, blocks[i-1].Quote)), " "))
+		}
+		r.block(b)
+	}
+	if len(r.links) > 0 {
+		r.lines = append(r.lines, "")
+		for i, link := range r.links {
+			r.lines = append(r.lines, r.st.Muted.Render(fmt.Sprintf("[%d]", i+1))+" "+r.st.Link.Render(link))
+		}
+	}
+	return r.lines
+}
+
+type renderer struct {
+	width     int
+	st        Styles
+	lines     []string
+	links     []string
+	footnotes map[string]int
+}
+
+// segment is part of a word in a single style
+type segment struct {
+	text  string
+	style lipgloss.Style
+}
+
+// word is an unbreakable run of segments. A nil word forces a line break.
+type word []segment
+
+func (w word) width() int {
+	n := 0
+	for _, s := range w {
+		n += lipgloss.Width(s.text)
+	}
+	return n
+}
+
+func (w word) render() string {
+	var b strings.Builder
+	for _, s := range w {
+		b.WriteString(s.style.Render(s.text))
+	}
+	return b.String()
+}
+
+func (r *renderer) block(b Block) {
+	prefix := r.quotePrefix(b.Quote)
+	prefixWidth := 2 * b.Quote
+
+	switch b.Kind {
+	case Heading:
+		r.wrap(r.words(b.Spans, r.st.Heading), prefix, prefix, prefixWidth)
+	case ListItem:
+		indent := strings.Repeat("  ", b.Level)
+		marker := b.Marker
+		if marker == "" {
+			marker = " "
+		}
+		first := prefix + indent + r.st.Muted.Render(marker) + " "
+		rest := prefix + indent + strings.Repeat(" ", lipgloss.Width(marker)+1)
+		r.wrap(r.words(b.Spans, r.st.Text), first, rest, prefixWidth+len(indent)+lipgloss.Width(marker)+1)
+	case Code:
+		for _, line := range strings.Split(strings.TrimRight(b.Text, "\n"), "\n") {
+			line = strings.ReplaceAll(line, "\t", "    ")
+			r.lines = append(r.lines, prefix+"  "+r.st.Code.Render(line))
+		}
+	case Rule:
+		r.lines = append(r.lines, prefix+r.st.Muted.Render(strings.Repeat("─", min(r.width-prefixWidth, 40))))
+	case Table:
+		r.table(b.Rows, prefix, prefixWidth)
+	default:
+		r.wrap(r.words(b.Spans, r.st.Text), prefix, prefix, prefixWidth)
+	}
+}
+
+func (r *renderer) quotePrefix(depth int) string {
+	return strings.Repeat(r.st.Quote.Render("│")+" ", depth)
+}
+
+// words splits spans into styled words, appending a footnote number to
+// each link whose text is not the URL itself
+func (r *renderer) words(spans []Span, base lipgloss.Style) []word {
+	var words []word
+	var current word
+	var text strings.Builder
+
+	flushText := func(style lipgloss.Style) {
+		if text.Len() > 0 {
+			current = append(current, segment{text.String(), style})
+			text.Reset()
+		}
+	}
+	flushWord := func() {
+		if len(current) > 0 {
+			words = append(words, current)
+			current = nil
+		}
+	}
+
+	var linkText strings.Builder
+	for i, span := range spans {
+		style := r.spanStyle(span, base)
+		for _, c := range span.Text {
+			switch {
+			case c == '\n':
+				flushText(style)
+				flushWord()
+				words = append(words, nil)
+			case unicode.IsSpace(c):
+				flushText(style)
+				flushWord()
+			default:
+				text.WriteRune(c)
+			}
+		}
+		flushText(style)
+		if span.URL == "" {
+			continue
+		}
+		// Number a link after the last of its spans
+		linkText.WriteString(span.Text)
+		if i+1 < len(spans) && spans[i+1].URL == span.URL {
+			continue
+		}
+		if span.URL != strings.TrimSpace(linkText.String()) {
+			current = append(current, segment{fmt.Sprintf("[%d]", r.footnote(span.URL)), r.st.Muted})
+		}
+		linkText.Reset()
+	}
+	flushWord()
+	return words
+}
+
+func (r *renderer) spanStyle(span Span, base lipgloss.Style) lipgloss.Style {
+	style := base
+	if span.Style&Mono != 0 {
+		style = r.st.Code
+	}
+	if span.URL != "" {
+		style = r.st.Link
+	}
+	if span.Style&Bold != 0 {
+		style = style.Bold(true)
+	}
+	if span.Style&Italic != 0 {
+		style = style.Italic(true)
+	}
+	return style
+}
+
+// footnote returns the number of a link, numbering new links in order
+func (r *renderer) footnote(url string) int {
+	if n, ok := r.footnotes[url]; ok {
+		return n
+	}
+	r.links = append(r.links, url)
+	r.footnotes[url] = len(r.links)
+	return len(r.links)
+}
+
+// wrap fills lines with words. Words longer than a line get a line of
+// their own.
+func (r *renderer) wrap(words []word, first, rest string, prefixWidth int) {
+	avail := max(r.width-prefixWidth, 10)
+	var line strings.Builder
+	lineWidth := 0
+	prefix := first
+
+	emit := func() {
+		r.lines = append(r.lines, prefix+line.String())
+		line.Reset()
+		lineWidth = 0
+		prefix = rest
+	}
+
+	for _, w := range words {
+		if w == nil {
+			emit()
+			continue
+		}
+		ww := w.width()
+		if lineWidth > 0 && lineWidth+1+ww > avail {
+			emit()
+		}
+		if lineWidth > 0 {
+			line.WriteString(" ")
+			lineWidth++
+		}
+		line.WriteString(w.render())
+		lineWidth += ww
+	}
+	if lineWidth > 0 || prefix == first {
+		emit()
+	}
+}
+
+// table lays rows out in aligned columns when they fit, and otherwise
+// wraps each row onto lines of its own
+func (r *renderer) table(rows [][][]Span, prefix string, prefixWidth int) {
+	cells := make([][][]word, len(rows))
+	var widths []int
+	for i, row := range rows {
+		style := r.st.Text
+		if i == 0 {
+			style = style.Bold(true)
+		}
+		for j, cell := range row {
+			words := r.words(cell, style)
+			cells[i] = append(cells[i], words)
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], wordsWidth(words))
+		}
+	}
+
+	total := 3 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	if total > r.width-prefixWidth {
+		for _, row := range cells {
+			var words []word
+			for j, cell := range row {
+				if j > 0 {
+					words = append(words, word{{"|", r.st.Muted}})
+				}
+				words = append(words, cell...)
+			}
+			r.wrap(words, prefix, prefix+"  ", prefixWidth+2)
+		}
+		return
+	}
+
+	for i, row := range cells {
+		var line strings.Builder
+		for j, cell := range row {
+			if j > 0 {
+				line.WriteString(r.st.Muted.Render(" │ "))
+			}
+			line.WriteString(joinWords(cell))
+			if j < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[j]-wordsWidth(cell)))
+			}
+		}
+		r.lines = append(r.lines, prefix+line.String())
+		if i == 0 {
+			rules := make([]string, len(widths))
+			for j, w := range widths {
+				rules[j] = strings.Repeat("─", w)
+			}
+			r.lines = append(r.lines, prefix+r.st.Muted.Render(strings.Join(rules, "─┼─")))
+		}
+	}
+}
+
+// wordsWidth is the width of words joined by spaces
+func wordsWidth(words []word) int {
+	n := 0
+	for _, w := range words {
+		if w == nil {
+			continue
+		}
+		if n > 0 {
+			n++
+		}
+		n += w.width()
+	}
+	return n
+}
+
+func joinWords(words []word) string {
+	var parts []string
+	for _, w := range words {
+		if w != nil {
+			parts = append(parts, w.render())
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,81 @@
+package markup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func plainStyles() Styles {
+	s := lipgloss.NewStyle()
+	return Styles{Text: s, Heading: s, Code: s, Link: s, Quote: s, Muted: s}
+}
+
+func TestRender(t *testing.T) {
+	blocks := []Block{
+		{Kind: Heading, Level: 2, Spans: []Span{{Text: "Getting started"}}},
+		{Spans: []Span{
+			{Text: "Install it with "},
+			{Text: "go install", Style: Mono},
+			{Text: ", then read the "},
+			{Text: "docs", URL: "https://example.com/docs"},
+			{Text: " and the "},
+			{Text: "FAQ", URL: "https://example.com/faq"},
+			{Text: " (see the "},
+			{Text: "docs", URL: "https://example.com/docs"},
+			{Text: " again)."},
+		}},
+		{Kind: ListItem, Marker: "1.", Spans: []Span{{Text: "First item that is long enough to wrap onto a second line"}}},
+		{Kind: ListItem, Marker: "2.", Spans: []Span{{Text: "Second"}}},
+		{Kind: ListItem, Level: 1, Marker: "•", Spans: []Span{{Text: "Nested"}}},
+		{Kind: Code, Text: "func main() {\n\tfmt.Println(\"a line that is much longer than the wrap width\")\n}\n"},
+		{Quote: 1, Spans: []Span{{Text: "Quoted text"}, {Text: "\n"}, {Text: "after a break"}}},
+		{Kind: Rule},
+	}
+
+	got := strings.Join(Render(blocks, 40, plainStyles()), "\n")
+	want := strings.Join([]string{
+		"Getting started",
+		"",
+		"Install it with go install, then read",
+		"the docs[1] and the FAQ[2] (see the",
+		"docs[1] again).",
+		"",
+		"1. First item that is long enough to",
+		"   wrap onto a second line",
+		"2. Second",
+		"  • Nested",
+		"",
+		"  func main() {",
+		"      fmt.Println(\"a line that is much longer than the wrap width\")",
+		"  }",
+		"",
+		"│ Quoted text",
+		"│ after a break",
+		"",
+		strings.Repeat("─", 40),
+		"",
+		"[1] https://example.com/docs",
+		"[2] https://example.com/faq",
+	}, "\n")
+	if got != want {
+		t.Errorf("Render:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestFromHTMLWhitespace(t *testing.T) {
+	doc, err := ExtractArticle(strings.NewReader(`<html><body><article>
+		<p>  one <b>two</b><i> three</i>
+		four<br>five  </p></article></body></html>`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := doc.Blocks[0].Spans
+	if got := PlainText(spans); got != "one two three four\nfive" {
+		t.Errorf("text = %q", got)
+	}
+	if spans[1].Style != Bold || spans[2].Style != Italic {
+		t.Errorf("styles = %+v", spans)
+	}
+}
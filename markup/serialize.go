@@ -0,0 +1,406 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ToMarkdown writes blocks as CommonMark
+func ToMarkdown(blocks []Block) string {
+	var b strings.Builder
+	var indents []int // content column of the open list item at each level
+	for i, block := range blocks {
+		if i > 0 {
+			prev := blocks[i-1]
+			if block.Kind == ListItem && block.Marker != "" && prev.Kind == ListItem && block.Quote == prev.Quote {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n" + quotePrefix(min(block.Quote, prev.Quote)) + "\n")
+			}
+		}
+
+		indent := 0
+		if block.Kind == ListItem {
+			if block.Marker != "" {
+				indents = indents[:min(block.Level, len(indents))]
+				base := 0
+				if len(indents) > 0 {
+					base = indents[len(indents)-1]
+				}
+				indents = append(indents, base+len(markdownMarker(block.Marker))+1)
+			}
+			if len(indents) > 0 {
+				indent = indents[min(block.Level, len(indents)-1)]
+			}
+		} else {
+			indents = indents[:0]
+		}
+
+		prefix := quotePrefix(block.Quote)
+		for j, line := range markdownLines(block, indent) {
+			if j > 0 {
+				b.WriteString("\n")
+			}
+			if j > 0 || block.Kind != ListItem || block.Marker == "" {
+				line = strings.Repeat(" ", indent) + line
+			}
+			b.WriteString(strings.TrimRight(prefix+line, " "))
+		}
+	}
+	return b.String()
+}
+
+func quotePrefix(depth int) string {
+	return strings.Repeat("> ", depth)
+}
+
+// markdownLines returns a block's lines, without the quote prefix or,
+// after the first line of a list item, its indentation
+func markdownLines(b Block, indent int) []string {
+	switch b.Kind {
+	case Heading:
+		return []string{strings.Repeat("#", max(b.Level, 1)) + " " + markdownInline(b.Spans)}
+	case ListItem:
+		lines := strings.Split(markdownInline(b.Spans), "\n")
+		if b.Marker != "" {
+			marker := markdownMarker(b.Marker)
+			lines[0] = strings.Repeat(" ", indent-len(marker)-1) + marker + " " + lines[0]
+		} else {
+			lines[0] = escapeLineStart(lines[0])
+		}
+		return lines
+	case Code:
+		fence := "```"
+		for strings.Contains(b.Text, fence) {
+			fence += "`"
+		}
+		lines := []string{fence}
+		lines = append(lines, strings.Split(strings.TrimRight(b.Text, "\n"), "\n")...)
+		return append(lines, fence)
+	case Rule:
+		return []string{"---"}
+	case Table:
+		return markdownTable(b.Rows)
+	}
+	lines := strings.Split(markdownInline(b.Spans), "\n")
+	lines[0] = escapeLineStart(lines[0])
+	return lines
+}
+
+// markdownMarker returns the Markdown for a list item's marker
+func markdownMarker(marker string) string {
+	if _, err := strconv.Atoi(strings.TrimSuffix(marker, ".")); err == nil {
+		return marker
+	}
+	return "-"
+}
+
+func markdownTable(rows [][][]Span) []string {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = strings.ReplaceAll(markdownInline(row[j]), "|", `\|`)
+				cells[j] = strings.ReplaceAll(cells[j], "\\\n", " ")
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return lines
+}
+
+// markdownInline writes spans as Markdown, links included
+func markdownInline(spans []Span) string {
+	var b strings.Builder
+	for i := 0; i < len(spans); {
+		target := spans[i].URL
+		if target == "" {
+			b.WriteString(markdownSpan(spans[i]))
+			i++
+			continue
+		}
+
+		j := i
+		var text strings.Builder
+		for ; j < len(spans) && spans[j].URL == target; j++ {
+			span := spans[j]
+			span.URL = ""
+			text.WriteString(markdownSpan(span))
+		}
+		if strings.TrimSpace(PlainText(spans[i:j])) == target && strings.Contains(target, ":") {
+			b.WriteString("<" + target + ">")
+		} else {
+			fmt.Fprintf(&b, "[%s](%s)", text.String(), markdownURL(target))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// markdownURL escapes the characters that would end a link destination
+func markdownURL(u string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E").Replace(u)
+}
+
+func markdownSpan(s Span) string {
+	text := s.Text
+	if s.Style&Mono != 0 {
+		ticks := "`"
+		for strings.Contains(text, ticks) {
+			ticks += "`"
+		}
+		pad := ""
+		if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+			pad = " "
+		}
+		text = ticks + pad + text + pad + ticks
+	} else {
+		text = escapeMarkdown(text)
+	}
+
+	var delim string
+	switch s.Style & (Bold | Italic) {
+	case Bold:
+		delim = "**"
+	case Italic:
+		delim = "*"
+	case Bold | Italic:
+		delim = "***"
+	}
+	if delim != "" {
+		// Delimiters must touch the text they emphasize
+		core := strings.TrimSpace(text)
+		if core != "" {
+			start := strings.Index(text, core)
+			text = text[:start] + delim + core + delim + text[start+len(core):]
+		}
+	}
+	return strings.ReplaceAll(text, "\n", "\\\n")
+}
+
+// escapeMarkdown escapes the characters in text that Markdown would take
+// as markup
+func escapeMarkdown(text string) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '\\', '*', '`', '[', ']', '<':
+			b.WriteRune('\\')
+		case '_':
+			// Underscores inside words never emphasize
+			if i == 0 || i == len(runes)-1 || !isWordRune(runes[i-1]) || !isWordRune(runes[i+1]) {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+var orderedMarker = regexp.MustCompile(`^(\d+)([.)])( |$)`)
+
+// escapeLineStart escapes text at the start of a paragraph that would
+// make it a heading, quote or list item
+func escapeLineStart(line string) string {
+	if m := orderedMarker.FindStringSubmatch(line); m != nil {
+		return m[1] + `\` + line[len(m[1]):]
+	}
+	for _, prefix := range []string{"#", ">", "- ", "+ "} {
+		if strings.HasPrefix(line, prefix) || line == strings.TrimSpace(prefix) {
+			return `\` + line
+		}
+	}
+	if strings.Trim(line, "-= ") == "" && line != "" {
+		return `\` + line
+	}
+	return line
+}
+
+// ToHTML writes blocks as an HTML fragment. Links to anything but web
+// and mail addresses keep their text but lose the link.
+func ToHTML(blocks []Block) string {
+	var w htmlWriter
+	for _, block := range blocks {
+		w.block(block)
+	}
+	w.closeLists(0)
+	w.setQuote(0)
+	return w.b.String()
+}
+
+type htmlWriter struct {
+	b     strings.Builder
+	quote int
+	// lists are the tags of the open lists, one per level, each with an
+	// open <li>
+	lists []string
+}
+
+func (w *htmlWriter) block(b Block) {
+	if b.Kind != ListItem || b.Quote != w.quote {
+		w.closeLists(0)
+	}
+	w.setQuote(b.Quote)
+
+	switch b.Kind {
+	case Heading:
+		level := min(max(b.Level, 1), 6)
+		fmt.Fprintf(&w.b, "<h%d>%s</h%d>\n", level, htmlInline(b.Spans), level)
+	case ListItem:
+		w.listItem(b)
+	case Code:
+		w.b.WriteString("<pre><code>" + html.EscapeString(strings.TrimRight(b.Text, "\n")) + "</code></pre>\n")
+	case Rule:
+		w.b.WriteString("<hr>\n")
+	case Table:
+		w.table(b.Rows)
+	default:
+		w.b.WriteString("<p>" + htmlInline(b.Spans) + "</p>\n")
+	}
+}
+
+func (w *htmlWriter) setQuote(depth int) {
+	for ; w.quote < depth; w.quote++ {
+		w.b.WriteString("<blockquote>\n")
+	}
+	for ; w.quote > depth; w.quote-- {
+		w.b.WriteString("</blockquote>\n")
+	}
+}
+
+func (w *htmlWriter) listItem(b Block) {
+	if b.Marker == "" {
+		// A further paragraph of the open item
+		w.closeLists(b.Level + 1)
+		w.b.WriteString("<p>" + htmlInline(b.Spans) + "</p>\n")
+		return
+	}
+
+	tag, start := "ul", 1
+	if n, err := strconv.Atoi(strings.TrimSuffix(b.Marker, ".")); err == nil {
+		tag, start = "ol", n
+	}
+	w.closeLists(b.Level + 1)
+	if len(w.lists) == b.Level+1 {
+		if w.lists[b.Level] == tag {
+			w.b.WriteString("</li>\n")
+		} else {
+			w.closeLists(b.Level)
+		}
+	}
+	for len(w.lists) < b.Level+1 {
+		if len(w.lists) < b.Level {
+			w.b.WriteString("<ul>\n<li>")
+			w.lists = append(w.lists, "ul")
+			continue
+		}
+		if tag == "ol" && start != 1 {
+			fmt.Fprintf(&w.b, "<ol start=\"%d\">\n", start)
+		} else {
+			w.b.WriteString("<" + tag + ">\n")
+		}
+		w.lists = append(w.lists, tag)
+	}
+	w.b.WriteString("<li>" + htmlInline(b.Spans) + "\n")
+}
+
+// closeLists closes the lists nested deeper than depth
+func (w *htmlWriter) closeLists(depth int) {
+	for len(w.lists) > depth {
+		w.b.WriteString("</li>\n</" + w.lists[len(w.lists)-1] + ">\n")
+		w.lists = w.lists[:len(w.lists)-1]
+	}
+}
+
+func (w *htmlWriter) table(rows [][][]Span) {
+	w.b.WriteString("<table>\n")
+	for i, row := range rows {
+		cell := "td"
+		if i == 0 {
+			cell = "th"
+			w.b.WriteString("<thead>\n")
+		}
+		w.b.WriteString("<tr>")
+		for _, spans := range row {
+			w.b.WriteString("<" + cell + ">" + htmlInline(spans) + "</" + cell + ">")
+		}
+		w.b.WriteString("</tr>\n")
+		if i == 0 {
+			w.b.WriteString("</thead>\n<tbody>\n")
+		}
+	}
+	if len(rows) > 0 {
+		w.b.WriteString("</tbody>\n")
+	}
+	w.b.WriteString("</table>\n")
+}
+
+// htmlInline writes spans as HTML, links included
+func htmlInline(spans []Span) string {
+	var b strings.Builder
+	for i := 0; i < len(spans); {
+		target := spans[i].URL
+		j := i + 1
+		for j < len(spans) && spans[j].URL == target {
+			j++
+		}
+		link := target != "" && safeURL(target)
+		if link {
+			b.WriteString(`<a href="` + html.EscapeString(target) + `">`)
+		}
+		for _, span := range spans[i:j] {
+			b.WriteString(htmlSpan(span))
+		}
+		if link {
+			b.WriteString("</a>")
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func htmlSpan(s Span) string {
+	text := strings.ReplaceAll(html.EscapeString(s.Text), "\n", "<br>\n")
+	if s.Style&Mono != 0 {
+		text = "<code>" + text + "</code>"
+	}
+	if s.Style&Italic != 0 {
+		text = "<em>" + text + "</em>"
+	}
+	if s.Style&Bold != 0 {
+		text = "<strong>" + text + "</strong>"
+	}
+	return text
+}
+
+// safeURL reports whether a link target is a web or mail address, or
+// relative to one
+func safeURL(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package markup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []Block
+		want   string
+	}{
+		{
+			name: "inline styles and links",
+			blocks: FromHTMLFragment(`It's <i>really</i> <b>fast</b>, see <a href="https://go.dev/doc">the <code>go</code> docs</a> ` +
+				`or <a href="https://go.dev">https://go.dev</a>. Use snake_case, not *stars* or [brackets].`),
+			want: "It's *really* **fast**, see [the `go` docs](https://go.dev/doc) or <https://go.dev>. " +
+				`Use snake_case, not \*stars\* or \[brackets\].`,
+		},
+		{
+			name:   "quotes and code",
+			blocks: FromHTMLFragment("<p>&gt; quoted\n<p>reply<pre><code>x := 1\n</code></pre>"),
+			want:   "> quoted\n\nreply\n\n```\nx := 1\n```",
+		},
+		{
+			name:   "text that looks like markup",
+			blocks: []Block{{Spans: []Span{{Text: "1. not a list"}}}, {Spans: []Span{{Text: "# not a heading"}}}},
+			want:   "1\\. not a list\n\n\\# not a heading",
+		},
+		{
+			name: "nested lists",
+			blocks: FromHTMLFragment(`<ul><li>one<ol start="9"><li>nine</li><li>ten<ul><li>deep</li></ul></li></ol></li>` +
+				`<li>two<p>more about two</li></ul>`),
+			want: "- one\n  9. nine\n  10. ten\n      - deep\n- two\n\n  more about two",
+		},
+		{
+			name: "table",
+			blocks: []Block{{Kind: Table, Rows: [][][]Span{
+				{{{Text: "name"}}, {{Text: "a|b"}}},
+				{{{Text: "go", Style: Bold}}},
+			}}},
+			want: "| name | a\\|b |\n| --- | --- |\n| **go** |  |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToMarkdown(tt.blocks); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Markdown written from a document parses back to the same document
+func TestToMarkdown_RoundTrip(t *testing.T) {
+	sources := []string{
+		"plain *italic* **bold** ***both*** and `code` with [a **link**](https://go.dev/doc)",
+		"# Heading\n\nA paragraph\nwith a soft break and a hard  \nbreak.\n\n---\n\n> quoted\n>\n> > twice",
+		"1. first\n2. second\n   - nested\n   - items\n\n     with a paragraph\n3. third",
+		"```go\nfunc main() {\n\tfmt.Println(\"```\")\n}\n```",
+		"Stars * and _underscores_ and \\*escapes\\* and <https://example.com/a_b>",
+		"| a | b |\n|---|---|\n| 1 | **2** |",
+	}
+	for _, src := range sources {
+		want := FromMarkdown(src)
+		md := ToMarkdown(want)
+		if got := FromMarkdown(md); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q\nwrote:\n%s\nparsed back as %+v\nwant %+v", src, md, got, want)
+		}
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []Block
+		want   []string
+	}{
+		{
+			name:   "escapes text and drops unsafe links",
+			blocks: FromMarkdown("<b>not a tag</b> & [ok](https://go.dev/?a=1&b=2) [bad](javascript:alert(1))  \nnext line"),
+			want: []string{
+				`<p>&lt;b&gt;not a tag&lt;/b&gt; &amp; <a href="https://go.dev/?a=1&amp;b=2">ok</a> bad<br>`,
+				`next line</p>`,
+			},
+		},
+		{
+			name:   "quotes and styles",
+			blocks: FromMarkdown("> **bold *both***\n>\n> > `code`\n\nafter"),
+			want: []string{
+				`<blockquote>`,
+				`<p><strong>bold </strong><strong><em>both</em></strong></p>`,
+				`<blockquote>`,
+				`<p><code>code</code></p>`,
+				`</blockquote>`,
+				`</blockquote>`,
+				`<p>after</p>`,
+			},
+		},
+		{
+			name:   "nested lists",
+			blocks: FromMarkdown("3. three\n   - nested\n\n     more\n4. four\n\n- bullet"),
+			want: []string{
+				`<ol start="3">`,
+				`<li>three`,
+				`<ul>`,
+				`<li>nested`,
+				`<p>more</p>`,
+				`</li>`,
+				`</ul>`,
+				`</li>`,
+				`<li>four`,
+				`</li>`,
+				`</ol>`,
+				`<ul>`,
+				`<li>bullet`,
+				`</li>`,
+				`</ul>`,
+			},
+		},
+		{
+			name:   "code and tables",
+			blocks: FromMarkdown("```\na < b\n```\n\n| a | b |\n|---|---|\n| 1 | 2 |"),
+			want: []string{
+				`<pre><code>a &lt; b</code></pre>`,
+				`<table>`,
+				`<thead>`,
+				`<tr><th>a</th><th>b</th></tr>`,
+				`</thead>`,
+				`<tbody>`,
+				`<tr><td>1</td><td>2</td></tr>`,
+				`</tbody>`,
+				`</table>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Split(strings.TrimSuffix(ToHTML(tt.blocks), "\n"), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package mute hides stories and comments by domain, author or title
+// keyword, with rules for every source and for single sources. Its
+// keyword patterns also pick the stories on watchlists.
+package mute
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// Rules lists what to mute. A pattern wrapped in slashes, like
+// /^ask hn/, is a regular expression; matching ignores case either way.
+type Rules struct {
+	// Domains mute stories linking to a domain or its subdomains
+	Domains []string `toml:"domains" json:"domains,omitempty"`
+	// Authors mute stories and comments by a user
+	Authors []string `toml:"authors" json:"authors,omitempty"`
+	// Keywords mute stories with a word or phrase in the title
+	Keywords []string `toml:"keywords" json:"keywords,omitempty"`
+}
+
+// Set holds rules for every source along with rules for single sources,
+// by source spec
+type Set struct {
+	Rules
+	Sources map[string]Rules `toml:"sources" json:"sources,omitempty"`
+}
+
+// Add appends the rules in other that r does not already have
+func (r *Rules) Add(other Rules) {
+	r.Domains = appendNew(r.Domains, other.Domains)
+	r.Authors = appendNew(r.Authors, other.Authors)
+	r.Keywords = appendNew(r.Keywords, other.Keywords)
+}
+
+// Remove deletes the rules in other from r
+func (r *Rules) Remove(other Rules) {
+	r.Domains = deleteAll(r.Domains, other.Domains)
+	r.Authors = deleteAll(r.Authors, other.Authors)
+	r.Keywords = deleteAll(r.Keywords, other.Keywords)
+}
+
+// Contains reports whether r has every rule in other
+func (r Rules) Contains(other Rules) bool {
+	return containsAll(r.Domains, other.Domains) &&
+		containsAll(r.Authors, other.Authors) &&
+		containsAll(r.Keywords, other.Keywords)
+}
+
+// Empty reports whether r has no rules
+func (r Rules) Empty() bool {
+	return len(r.Domains) == 0 && len(r.Authors) == 0 && len(r.Keywords) == 0
+}
+
+func deleteAll(from, remove []string) []string {
+	return slices.DeleteFunc(from, func(s string) bool { return slices.Contains(remove, s) })
+}
+
+func containsAll(in, want []string) bool {
+	for _, s := range want {
+		if !slices.Contains(in, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendNew(to, from []string) []string {
+	for _, s := range from {
+		if !slices.Contains(to, s) {
+			to = append(to, s)
+		}
+	}
+	return to
+}
+
+// Problems describes every invalid pattern in the set
+func (s Set) Problems() []string {
+	problems := s.Rules.problems("")
+	for _, spec := range slices.Sorted(maps.Keys(s.Sources)) {
+		prefix := fmt.Sprintf("sources.%q", spec)
+		if _, err := api.ParseSource(spec); err != nil {
+			problems = append(problems, prefix+": "+err.Error())
+		}
+		problems = append(problems, s.Sources[spec].problems(prefix+".")...)
+	}
+	return problems
+}
+
+func (r Rules) problems(prefix string) []string {
+	var problems []string
+	for _, list := range []struct {
+		name     string
+		patterns []string
+	}{{"domains", r.Domains}, {"authors", r.Authors}, {"keywords", r.Keywords}} {
+		for _, p := range list.patterns {
+			if _, err := compile(p); err != nil {
+				problems = append(problems, fmt.Sprintf("%s%s: %v", prefix, list.name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// Filter matches stories and comments against compiled rules. A nil
+// *Filter mutes nothing.
+type Filter struct {
+	all     matcher
+	sources map[string]matcher // by source spec
+}
+
+// Compile combines sets of rules into a filter, skipping invalid patterns
+// (see Set.Problems)
+func Compile(sets ...Set) *Filter {
+	f := &Filter{sources: make(map[string]matcher)}
+	for _, s := range sets {
+		f.all.add(s.Rules)
+		for spec, rules := range s.Sources {
+			// Key by the source's own spec, so rules for "/r/golang"
+			// apply to r/golang
+			if source, err := api.ParseSource(spec); err == nil {
+				spec = source.Spec()
+			}
+			m := f.sources[spec]
+			m.add(rules)
+			f.sources[spec] = m
+		}
+	}
+	return f
+}
+
+// Story returns why a story from the source with spec is muted, such as
+// "domain example.com", or "" if it is not
+func (f *Filter) Story(spec string, item *api.Item) string {
+	if f == nil || item == nil {
+		return ""
+	}
+	for _, m := range f.matchers(spec) {
+		if p := m.domains.find(item.Domain(), matchDomain); p != "" {
+			return "domain " + p
+		}
+		if p := m.authors.find(item.By, matchWhole); p != "" {
+			return "author " + p
+		}
+		if p := m.keywords.find(item.Title, matchSubstring); p != "" {
+			return "keyword " + p
+		}
+	}
+	return ""
+}
+
+// Comment returns why a comment from the source with spec is muted, or ""
+// if it is not. Comments are muted by author only.
+func (f *Filter) Comment(spec string, c *api.Comment) string {
+	if f == nil || c == nil || c.Item == nil {
+		return ""
+	}
+	for _, m := range f.matchers(spec) {
+		if p := m.authors.find(c.By, matchWhole); p != "" {
+			return "author " + p
+		}
+	}
+	return ""
+}
+
+func (f *Filter) matchers(spec string) []matcher {
+	if m, ok := f.sources[spec]; ok {
+		return []matcher{f.all, m}
+	}
+	return []matcher{f.all}
+}
+
+// Keywords matches story titles against patterns written like
+// Rules.Keywords
+type Keywords struct {
+	patterns patterns
+}
+
+// CompileKeywords compiles keyword patterns, skipping invalid ones (see
+// Set.Problems)
+func CompileKeywords(texts []string) Keywords {
+	var k Keywords
+	k.patterns.add(texts)
+	return k
+}
+
+// Match returns the first pattern matching a story's title, or "" if none
+// does
+func (k Keywords) Match(item *api.Item) string {
+	if item == nil {
+		return ""
+	}
+	return k.patterns.find(item.Title, matchSubstring)
+}
+
+// Empty reports whether there are no patterns
+func (k Keywords) Empty() bool {
+	return len(k.patterns) == 0
+}
+
+type matcher struct {
+	domains, authors, keywords patterns
+}
+
+func (m *matcher) add(r Rules) {
+	m.domains.add(r.Domains)
+	m.authors.add(r.Authors)
+	m.keywords.add(r.Keywords)
+}
+
+// pattern is a rule as written and, for /regexp/ rules, its compiled form
+type pattern struct {
+	text string
+	re   *regexp.Regexp
+}
+
+type patterns []pattern
+
+func (ps *patterns) add(texts []string) {
+	for _, text := range texts {
+		if p, err := compile(text); err == nil {
+			*ps = append(*ps, p)
+		}
+	}
+}
+
+// find returns the first pattern matching s, or "" if none does
+func (ps patterns) find(s string, match func(s, text string) bool) string {
+	if s == "" {
+		return ""
+	}
+	for _, p := range ps {
+		if (p.re != nil && p.re.MatchString(s)) || (p.re == nil && match(s, p.text)) {
+			return p.text
+		}
+	}
+	return ""
+}
+
+func compile(text string) (pattern, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return pattern{}, errors.New("empty pattern")
+	}
+	expr, ok := strings.CutPrefix(text, "/")
+	if !ok || len(expr) < 2 || !strings.HasSuffix(expr, "/") {
+		return pattern{text: text}, nil
+	}
+	re, err := regexp.Compile("(?i)" + strings.TrimSuffix(expr, "/"))
+	if err != nil {
+		return pattern{}, fmt.Errorf("invalid regular expression %s: %w", text, err)
+	}
+	return pattern{text: text, re: re}, nil
+}
+
+// matchDomain matches a domain and its subdomains
+func matchDomain(domain, text string) bool {
+	text = strings.TrimPrefix(strings.ToLower(text), "www.")
+	domain = strings.ToLower(domain)
+	return domain == text || strings.HasSuffix(domain, "."+text)
+}
+
+func matchWhole(s, text string) bool {
+	return strings.EqualFold(s, text)
+}
+
+func matchSubstring(s, text string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(text))
+}
@@ -0,0 +1,110 @@
+package mute
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestFilterStory(t *testing.T) {
+	f := Compile(Set{
+		Rules: Rules{
+			Domains:  []string{"example.com", "/^medium\\./"},
+			Authors:  []string{"Troll"},
+			Keywords: []string{"crypto", "/^ask hn:/"},
+		},
+		Sources: map[string]Rules{"/r/golang": {Keywords: []string{"generics"}}},
+	})
+
+	for _, tt := range []struct {
+		spec string
+		item api.Item
+		want string
+	}{
+		{"hn", api.Item{Title: "Go 1.30", URL: "https://go.dev/blog"}, ""},
+		{"hn", api.Item{Title: "A post", URL: "https://www.example.com/a"}, "domain example.com"},
+		{"hn", api.Item{Title: "A post", URL: "https://blog.example.com/a"}, "domain example.com"},
+		{"hn", api.Item{Title: "A post", URL: "https://notexample.com/a"}, ""},
+		{"hn", api.Item{Title: "A post", URL: "https://Medium.com/@x/y"}, "domain /^medium\\./"},
+		{"hn", api.Item{Title: "A post", By: "troll"}, "author Troll"},
+		{"hn", api.Item{Title: "Why Crypto failed"}, "keyword crypto"},
+		{"hn", api.Item{Title: "Ask HN: What are you reading?"}, "keyword /^ask hn:/"},
+		{"hn", api.Item{Title: "Show HN: Ask HN: clone"}, ""},
+		{"hn", api.Item{Title: "Generics in Go"}, ""},
+		{"r/golang", api.Item{Title: "Generics in Go"}, "keyword generics"},
+		{"r/golang", api.Item{Title: "crypto/tls changes"}, "keyword crypto"},
+	} {
+		if got := f.Story(tt.spec, &tt.item); got != tt.want {
+			t.Errorf("Story(%s, %q by %q at %s) = %q, want %q", tt.spec, tt.item.Title, tt.item.By, tt.item.URL, got, tt.want)
+		}
+	}
+}
+
+func TestFilterComment(t *testing.T) {
+	f := Compile(Set{Sources: map[string]Rules{"lobsters": {Authors: []string{"/^bot_/"}}}}, Set{Rules: Rules{Authors: []string{"spammer"}}})
+
+	comment := func(by string) *api.Comment { return &api.Comment{Item: &api.Item{By: by}} }
+	if got := f.Comment("hn", comment("SPAMMER")); got != "author spammer" {
+		t.Errorf("global author: %q", got)
+	}
+	if got := f.Comment("lobsters", comment("bot_42")); got != "author /^bot_/" {
+		t.Errorf("source author: %q", got)
+	}
+	if got := f.Comment("hn", comment("bot_42")); got != "" {
+		t.Errorf("lobsters rule muted an hn comment: %q", got)
+	}
+	if got := f.Comment("hn", &api.Comment{More: &api.MoreComments{Count: 2}}); got != "" {
+		t.Errorf("placeholder muted: %q", got)
+	}
+
+	var none *Filter
+	if none.Story("hn", &api.Item{By: "spammer"}) != "" || none.Comment("hn", comment("spammer")) != "" {
+		t.Error("nil filter muted something")
+	}
+}
+
+func TestSetProblems(t *testing.T) {
+	s := Set{
+		Rules:   Rules{Domains: []string{"example.com", " "}, Keywords: []string{"/(/", "/", "//"}},
+		Sources: map[string]Rules{"nope": {Authors: []string{"/[/"}}, "lobsters": {Authors: []string{"x"}}},
+	}
+	got := strings.Join(s.Problems(), "\n")
+	for _, want := range []string{
+		"domains: empty pattern",
+		"keywords: invalid regular expression /(/",
+		`sources."nope": unknown source: nope`,
+		`sources."nope".authors: invalid regular expression /[/`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("problems missing %q:\n%s", want, got)
+		}
+	}
+	if n := len(s.Problems()); n != 4 {
+		t.Errorf("%d problems, want 4:\n%s", n, got)
+	}
+}
+
+func TestRulesAdd(t *testing.T) {
+	r := Rules{Domains: []string{"a.com"}}
+	r.Add(Rules{Domains: []string{"a.com", "b.com"}, Authors: []string{"x"}})
+	if strings.Join(r.Domains, ",") != "a.com,b.com" || strings.Join(r.Authors, ",") != "x" {
+		t.Errorf("rules = %+v", r)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	k := CompileKeywords([]string{"golang", "/\\bzig\\b/", "/(/"})
+	for title, want := range map[string]string{
+		"Golang 2 announced":    "golang",
+		"Why we chose Zig":      "/\\bzig\\b/",
+		"Zigzag layouts in CSS": "",
+	} {
+		if got := k.Match(&api.Item{Title: title}); got != want {
+			t.Errorf("Match(%q) = %q, want %q", title, got, want)
+		}
+	}
+	if k.Empty() || !CompileKeywords(nil).Empty() {
+		t.Error("Empty is wrong")
+	}
+}
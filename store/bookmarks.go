@@ -0,0 +1,116 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// bookmarksVersion is the schema version of the bookmarks file
+const bookmarksVersion = 1
+
+// BookmarksFile is the bookmarks file name in the data directory
+const BookmarksFile = "bookmarks.json"
+
+// Bookmark is a saved story along with the source it came from
+type Bookmark struct {
+	// Source is the specification api.ParseSource uses to recreate the
+	// story's source, for loading its comments later
+	Source     string    `json:"source"`
+	SourceName string    `json:"source_name"`
+	Item       *api.Item `json:"item"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// Bookmarks is the set of saved stories, persisted on every change
+type Bookmarks struct {
+	path string
+
+	mu    sync.Mutex
+	items []Bookmark // newest first
+}
+
+// OpenBookmarks loads the bookmarks file from the data directory
+func OpenBookmarks() (*Bookmarks, error) {
+	path, err := Path(BookmarksFile)
+	if err != nil {
+		return nil, err
+	}
+	return LoadBookmarks(path)
+}
+
+// LoadBookmarks loads bookmarks from path. A missing file is an empty set.
+func LoadBookmarks(path string) (*Bookmarks, error) {
+	b := &Bookmarks{path: path}
+	if err := readFile(path, bookmarksVersion, &b.items); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// List returns the bookmarks, most recently saved first
+func (b *Bookmarks) List() []Bookmark {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Bookmark(nil), b.items...)
+}
+
+// Has reports whether the story with key from source is saved
+func (b *Bookmarks) Has(source, key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index(source, key) >= 0
+}
+
+// Add saves a story, replacing an existing bookmark for the same story
+func (b *Bookmarks) Add(source api.Source, item *api.Item) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if i := b.index(source.Spec(), item.Key); i >= 0 {
+		b.items = append(b.items[:i], b.items[i+1:]...)
+	}
+	b.items = append([]Bookmark{{
+		Source:     source.Spec(),
+		SourceName: source.Name(),
+		Item:       item,
+		SavedAt:    time.Now(),
+	}}, b.items...)
+	return b.save()
+}
+
+// Remove deletes the bookmark for the story with key from source
+func (b *Bookmarks) Remove(source, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.index(source, key)
+	if i < 0 {
+		return nil
+	}
+	b.items = append(b.items[:i], b.items[i+1:]...)
+	return b.save()
+}
+
+// Toggle saves the story if it is not saved and removes it otherwise,
+// returning whether it is now saved
+func (b *Bookmarks) Toggle(source api.Source, item *api.Item) (bool, error) {
+	if b.Has(source.Spec(), item.Key) {
+		return false, b.Remove(source.Spec(), item.Key)
+	}
+	return true, b.Add(source, item)
+}
+
+func (b *Bookmarks) index(source, key string) int {
+	for i, bm := range b.items {
+		if bm.Source == source && bm.Item != nil && bm.Item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *Bookmarks) save() error {
+	return writeFile(b.path, bookmarksVersion, b.items)
+}
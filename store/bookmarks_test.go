@@ -0,0 +1,94 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestBookmarks_ToggleAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), BookmarksFile)
+	b, err := LoadBookmarks(path)
+	if err != nil {
+		t.Fatalf("LoadBookmarks on missing file: %v", err)
+	}
+	if len(b.List()) != 0 {
+		t.Fatalf("new bookmarks has %d entries, want 0", len(b.List()))
+	}
+
+	hn := api.NewClient()
+	reddit := api.NewRedditClient("golang")
+	first := &api.Item{Key: "1", Title: "First"}
+	second := &api.Item{Key: "t3_abc", Title: "Second"}
+
+	if saved, err := b.Toggle(hn, first); err != nil || !saved {
+		t.Fatalf("Toggle(first) = %v, %v; want saved", saved, err)
+	}
+	if saved, err := b.Toggle(reddit, second); err != nil || !saved {
+		t.Fatalf("Toggle(second) = %v, %v; want saved", saved, err)
+	}
+
+	reloaded, err := LoadBookmarks(path)
+	if err != nil {
+		t.Fatalf("LoadBookmarks: %v", err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 {
+		t.Fatalf("reloaded %d bookmarks, want 2", len(list))
+	}
+	if list[0].Item.Title != "Second" || list[0].Source != "r/golang" || list[0].SourceName != "r/golang" {
+		t.Errorf("newest bookmark = %+v, want Second from r/golang", list[0])
+	}
+	if list[1].Item.Title != "First" || list[1].Source != "hn" {
+		t.Errorf("oldest bookmark = %+v, want First from hn", list[1])
+	}
+	if !reloaded.Has("hn", "1") || reloaded.Has("lobsters", "1") {
+		t.Error("Has should match on both source and key")
+	}
+
+	if saved, err := reloaded.Toggle(hn, first); err != nil || saved {
+		t.Fatalf("second Toggle(first) = %v, %v; want removed", saved, err)
+	}
+	if reloaded.Has("hn", "1") {
+		t.Error("bookmark still present after toggling off")
+	}
+}
+
+func TestBookmarks_AtomicWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, BookmarksFile)
+	b, _ := LoadBookmarks(path)
+	if err := b.Add(api.NewLobstersClient(), &api.Item{Key: "abc", Title: "Story"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != BookmarksFile {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("data dir contains %v, want only %s", names, BookmarksFile)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"version": 1`) {
+		t.Errorf("bookmarks file missing schema version:\n%s", data)
+	}
+}
+
+func TestBookmarks_RejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), BookmarksFile)
+	if err := os.WriteFile(path, []byte(`{"version": 99, "data": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBookmarks(path); err == nil {
+		t.Error("LoadBookmarks accepted a file from a newer schema version")
+	}
+}
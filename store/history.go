@@ -0,0 +1,134 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// historyVersion is the schema version of the history file
+const historyVersion = 1
+
+// HistoryFile is the read history file name in the data directory
+const HistoryFile = "history.json"
+
+// historyMaxAge bounds how long visits are remembered, so the file does
+// not grow forever
+const historyMaxAge = 180 * 24 * time.Hour
+
+// Visit records when a story was last read
+type Visit struct {
+	ReadAt time.Time `json:"read_at"`
+	// CommentsAt is when the comments were last viewed, and Comments the
+	// story's comment count at that time
+	CommentsAt time.Time `json:"comments_at,omitempty"`
+	Comments   int       `json:"comments,omitempty"`
+	// SeenComments holds the keys of every comment loaded on previous
+	// visits, used to highlight comments posted since
+	SeenComments []string `json:"seen_comments,omitempty"`
+}
+
+// NewComments returns how many comments were added since the comments
+// were last viewed, or 0 if they never were
+func (v Visit) NewComments(item *api.Item) int {
+	if v.CommentsAt.IsZero() {
+		return 0
+	}
+	return max(item.Descendants-v.Comments, 0)
+}
+
+// History tracks which stories have been read, per source
+type History struct {
+	path string
+
+	mu     sync.Mutex
+	visits map[string]map[string]*Visit // source spec -> item key -> visit
+}
+
+// OpenHistory loads the history file from the data directory
+func OpenHistory() (*History, error) {
+	path, err := Path(HistoryFile)
+	if err != nil {
+		return nil, err
+	}
+	return LoadHistory(path)
+}
+
+// LoadHistory loads read history from path. A missing file is empty.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path, visits: make(map[string]map[string]*Visit)}
+	if err := readFile(path, historyVersion, &h.visits); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Get returns the last visit to the story with key from source
+func (h *History) Get(source, key string) (Visit, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, ok := h.visits[source][key]; ok {
+		return *v, true
+	}
+	return Visit{}, false
+}
+
+// MarkRead records that the story was opened
+func (h *History) MarkRead(source string, item *api.Item) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.visit(source, item.Key).ReadAt = time.Now()
+	return h.save()
+}
+
+// MarkCommentsViewed records that the story's comments were viewed along
+// with its current comment count, adding seen to the comments seen on
+// earlier visits
+func (h *History) MarkCommentsViewed(source string, item *api.Item, seen []string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v := h.visit(source, item.Key)
+	v.ReadAt = time.Now()
+	v.CommentsAt = v.ReadAt
+	v.Comments = item.Descendants
+
+	known := make(map[string]bool, len(v.SeenComments))
+	for _, key := range v.SeenComments {
+		known[key] = true
+	}
+	for _, key := range seen {
+		if !known[key] {
+			known[key] = true
+			v.SeenComments = append(v.SeenComments, key)
+		}
+	}
+	return h.save()
+}
+
+func (h *History) visit(source, key string) *Visit {
+	if h.visits[source] == nil {
+		h.visits[source] = make(map[string]*Visit)
+	}
+	v, ok := h.visits[source][key]
+	if !ok {
+		v = &Visit{}
+		h.visits[source][key] = v
+	}
+	return v
+}
+
+func (h *History) save() error {
+	cutoff := time.Now().Add(-historyMaxAge)
+	for source, visits := range h.visits {
+		for key, v := range visits {
+			if v.ReadAt.Before(cutoff) {
+				delete(visits, key)
+			}
+		}
+		if len(visits) == 0 {
+			delete(h.visits, source)
+		}
+	}
+	return writeFile(h.path, historyVersion, h.visits)
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestHistory_MarkAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFile)
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory on missing file: %v", err)
+	}
+
+	story := &api.Item{Key: "42", Descendants: 10}
+	if _, ok := h.Get("hn", "42"); ok {
+		t.Fatal("unvisited story reported as read")
+	}
+	if err := h.MarkRead("hn", story); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if err := h.MarkCommentsViewed("lobsters", &api.Item{Key: "abc", Descendants: 3}, nil); err != nil {
+		t.Fatalf("MarkCommentsViewed: %v", err)
+	}
+
+	reloaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	v, ok := reloaded.Get("hn", "42")
+	if !ok || v.ReadAt.IsZero() {
+		t.Fatalf("Get(hn, 42) = %+v, %v; want a read visit", v, ok)
+	}
+	if _, ok := reloaded.Get("lobsters", "42"); ok {
+		t.Error("visits must be keyed per source")
+	}
+	if v.NewComments(story) != 0 {
+		t.Error("NewComments should be 0 when comments were never viewed")
+	}
+
+	v, _ = reloaded.Get("lobsters", "abc")
+	tests := []struct {
+		descendants int
+		want        int
+	}{
+		{3, 0},
+		{15, 12},
+		{1, 0}, // comments were deleted
+	}
+	for _, tt := range tests {
+		if got := v.NewComments(&api.Item{Descendants: tt.descendants}); got != tt.want {
+			t.Errorf("NewComments with %d comments = %d, want %d", tt.descendants, got, tt.want)
+		}
+	}
+}
+
+func TestHistory_PrunesOldVisits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFile)
+	h, _ := LoadHistory(path)
+	h.visit("hn", "old").ReadAt = time.Now().Add(-historyMaxAge - time.Hour)
+
+	if err := h.MarkRead("hn", &api.Item{Key: "new"}); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	if _, ok := h.Get("hn", "old"); ok {
+		t.Error("visit older than historyMaxAge was kept")
+	}
+	if _, ok := h.Get("hn", "new"); !ok {
+		t.Error("recent visit was pruned")
+	}
+}
+
+func TestHistory_SeenCommentsAccumulate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFile)
+	h, _ := LoadHistory(path)
+	story := &api.Item{Key: "1"}
+
+	if err := h.MarkCommentsViewed("hn", story, []string{"a", "b"}); err != nil {
+		t.Fatalf("MarkCommentsViewed: %v", err)
+	}
+	if err := h.MarkCommentsViewed("hn", story, []string{"b", "c"}); err != nil {
+		t.Fatalf("MarkCommentsViewed: %v", err)
+	}
+
+	reloaded, _ := LoadHistory(path)
+	v, _ := reloaded.Get("hn", "1")
+	if got := strings.Join(v.SeenComments, ","); got != "a,b,c" {
+		t.Errorf("SeenComments = %s, want a,b,c", got)
+	}
+}
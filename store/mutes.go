@@ -0,0 +1,112 @@
+package store
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/JonathanWThom/feedme/mute"
+)
+
+// mutesVersion is the schema version of the mutes file
+const mutesVersion = 1
+
+// MutesFile is the file name of mute rules added in the app, in the data
+// directory; rules from the config file are not stored here
+const MutesFile = "mutes.json"
+
+// Mutes is the set of mute rules added in the app, persisted on every
+// change
+type Mutes struct {
+	path string
+
+	mu  sync.Mutex
+	set mute.Set
+}
+
+// OpenMutes loads the mutes file from the data directory
+func OpenMutes() (*Mutes, error) {
+	path, err := Path(MutesFile)
+	if err != nil {
+		return nil, err
+	}
+	return LoadMutes(path)
+}
+
+// LoadMutes loads mute rules from path. A missing file has no rules.
+func LoadMutes(path string) (*Mutes, error) {
+	m := &Mutes{path: path}
+	if err := readFile(path, mutesVersion, &m.set); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Set returns a copy of the rules
+func (m *Mutes) Set() mute.Set {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	set := mute.Set{Rules: cloneRules(m.set.Rules)}
+	if m.set.Sources != nil {
+		set.Sources = make(map[string]mute.Rules, len(m.set.Sources))
+		for spec, rules := range m.set.Sources {
+			set.Sources[spec] = cloneRules(rules)
+		}
+	}
+	return set
+}
+
+// Has reports whether every rule in rules was added for the source with
+// spec, or for every source when spec is ""
+func (m *Mutes) Has(spec string, rules mute.Rules) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rules(spec).Contains(rules)
+}
+
+// Toggle adds rules for the source with spec, or for every source when
+// spec is "", or removes them if they were all added before, returning
+// whether they are now muted
+func (m *Mutes) Toggle(spec string, rules mute.Rules) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.rules(spec)
+	muted := !current.Contains(rules)
+	if muted {
+		current.Add(rules)
+	} else {
+		current.Remove(rules)
+	}
+
+	switch {
+	case spec == "":
+		m.set.Rules = current
+	case current.Empty():
+		delete(m.set.Sources, spec)
+	default:
+		if m.set.Sources == nil {
+			m.set.Sources = make(map[string]mute.Rules)
+		}
+		m.set.Sources[spec] = current
+	}
+	return muted, m.save()
+}
+
+func (m *Mutes) rules(spec string) mute.Rules {
+	if spec == "" {
+		return m.set.Rules
+	}
+	return m.set.Sources[spec]
+}
+
+func (m *Mutes) save() error {
+	return writeFile(m.path, mutesVersion, m.set)
+}
+
+func cloneRules(r mute.Rules) mute.Rules {
+	return mute.Rules{
+		Domains:  slices.Clone(r.Domains),
+		Authors:  slices.Clone(r.Authors),
+		Keywords: slices.Clone(r.Keywords),
+	}
+}
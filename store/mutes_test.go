@@ -0,0 +1,51 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/mute"
+)
+
+func TestMutes_ToggleAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MutesFile)
+	m, err := LoadMutes(path)
+	if err != nil {
+		t.Fatalf("LoadMutes on missing file: %v", err)
+	}
+
+	domain := mute.Rules{Domains: []string{"example.com"}}
+	author := mute.Rules{Authors: []string{"troll"}}
+	if muted, err := m.Toggle("", domain); err != nil || !muted {
+		t.Fatalf("Toggle(domain) = %v, %v; want muted", muted, err)
+	}
+	if muted, err := m.Toggle("r/golang", author); err != nil || !muted {
+		t.Fatalf("Toggle(author) = %v, %v; want muted", muted, err)
+	}
+
+	reloaded, err := LoadMutes(path)
+	if err != nil {
+		t.Fatalf("LoadMutes: %v", err)
+	}
+	set := reloaded.Set()
+	if strings.Join(set.Domains, ",") != "example.com" || len(set.Authors) != 0 {
+		t.Errorf("global rules = %+v", set.Rules)
+	}
+	if strings.Join(set.Sources["r/golang"].Authors, ",") != "troll" {
+		t.Errorf("source rules = %+v", set.Sources)
+	}
+
+	// Set returns a copy
+	set.Domains[0] = "changed"
+	if reloaded.Set().Domains[0] != "example.com" {
+		t.Error("Set shares the stored rules")
+	}
+
+	if muted, err := reloaded.Toggle("r/golang", author); err != nil || muted {
+		t.Fatalf("second Toggle(author) = %v, %v; want unmuted", muted, err)
+	}
+	if _, ok := reloaded.Set().Sources["r/golang"]; ok {
+		t.Error("source without rules left behind")
+	}
+}
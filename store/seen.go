@@ -0,0 +1,81 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// seenVersion is the schema version of the seen file
+const seenVersion = 1
+
+// SeenFile is the file name of the stories fm watch has reported, in the
+// data directory
+const SeenFile = "watch_seen.json"
+
+// seenMaxAge bounds how long reported stories are remembered; they have
+// long left the feeds by then
+const seenMaxAge = 90 * 24 * time.Hour
+
+// Seen is the set of stories already reported, per source, so each story
+// is only reported once
+type Seen struct {
+	path string
+
+	mu      sync.Mutex
+	stories map[string]map[string]time.Time // source spec -> item key -> reported at
+}
+
+// OpenSeen loads the seen file from the data directory
+func OpenSeen() (*Seen, error) {
+	path, err := Path(SeenFile)
+	if err != nil {
+		return nil, err
+	}
+	return LoadSeen(path)
+}
+
+// LoadSeen loads the seen set from path. A missing file is empty.
+func LoadSeen(path string) (*Seen, error) {
+	s := &Seen{path: path, stories: make(map[string]map[string]time.Time)}
+	if err := readFile(path, seenVersion, &s.stories); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Has reports whether the story with key from source was seen
+func (s *Seen) Has(source, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.stories[source][key]
+	return ok
+}
+
+// Add records the stories with keys from source as seen
+func (s *Seen) Add(source string, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stories[source] == nil {
+		s.stories[source] = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for _, key := range keys {
+		s.stories[source][key] = now
+	}
+	return s.save()
+}
+
+func (s *Seen) save() error {
+	cutoff := time.Now().Add(-seenMaxAge)
+	for source, stories := range s.stories {
+		for key, at := range stories {
+			if at.Before(cutoff) {
+				delete(stories, key)
+			}
+		}
+		if len(stories) == 0 {
+			delete(s.stories, source)
+		}
+	}
+	return writeFile(s.path, seenVersion, s.stories)
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSeen_AddAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SeenFile)
+	s, err := LoadSeen(path)
+	if err != nil {
+		t.Fatalf("LoadSeen on missing file: %v", err)
+	}
+	if s.Has("hn", "1") {
+		t.Fatal("empty set has a story")
+	}
+	if err := s.Add("hn", "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadSeen(path)
+	if err != nil {
+		t.Fatalf("LoadSeen: %v", err)
+	}
+	if !reloaded.Has("hn", "1") || !reloaded.Has("hn", "2") || reloaded.Has("lobsters", "1") {
+		t.Error("Has should match on both source and key")
+	}
+}
+
+func TestSeen_ForgetsOldStories(t *testing.T) {
+	s, err := LoadSeen(filepath.Join(t.TempDir(), SeenFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.stories["hn"] = map[string]time.Time{"old": time.Now().Add(-seenMaxAge - time.Hour)}
+	if err := s.Add("lobsters", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Has("hn", "old") || !s.Has("lobsters", "new") {
+		t.Errorf("stories after save = %v", s.stories)
+	}
+}
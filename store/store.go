@@ -0,0 +1,89 @@
+// Package store persists feedme's local state (bookmarks, read history,
+// ...) as versioned JSON files in the feedme data directory.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// Path returns the location of a store file in the feedme data directory
+func Path(name string) (string, error) {
+	dir, err := api.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("locating data directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// envelope wraps every store file with its schema version
+type envelope struct {
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// readFile decodes a store file into v. A missing file leaves v untouched
+// and is not an error. Files written by a newer schema version are
+// rejected rather than silently dropping fields on the next save.
+func readFile(path string, version int, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if env.Version > version {
+		return fmt.Errorf("%s has schema version %d, newer than supported version %d", path, env.Version, version)
+	}
+	if len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeFile atomically replaces path with v, so a crash mid-write never
+// leaves a truncated file behind
+func writeFile(path string, version int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(envelope{Version: version, Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Terminal attributes for search matches: reverse video for every match,
+// plus underline for the current one. Only these attributes are reset at
+// the end of a match so surrounding styles are preserved.
+const (
+	matchOn        = "\x1b[7m"
+	matchOff       = "\x1b[27m"
+	currentMatchOn = "\x1b[4m"
+	currentOff     = "\x1b[24m"
+)
+
+// commentMatch is an occurrence of the search query in the rendered
+// comment lines, as a range of visible columns on a line
+type commentMatch struct {
+	line       int
+	start, end int // rune columns, end exclusive
+}
+
+// setCommentQuery searches the comment view for query and moves to the
+// first match at or below the top of the viewport
+func (m *Model) setCommentQuery(query string) {
+	m.commentQuery = query
+	m.findCommentMatches()
+	m.matchIndex = 0
+	for i, match := range m.commentMatches {
+		if match.line >= m.viewport.YOffset {
+			m.matchIndex = i
+			break
+		}
+	}
+	if len(m.commentMatches) > 0 {
+		m.scrollToMatch()
+		return
+	}
+	m.updateViewportWithHighlight()
+}
+
+// findCommentMatches finds every case-insensitive occurrence of the query
+// in the rendered comment lines
+func (m *Model) findCommentMatches() {
+	m.commentMatches = nil
+	query := foldRunes(m.commentQuery)
+	if len(query) == 0 {
+		return
+	}
+	for i, line := range m.commentLines {
+		text := foldRunes(stripAnsi(line))
+		for col := 0; col+len(query) <= len(text); col++ {
+			if runesEqual(text[col:col+len(query)], query) {
+				m.commentMatches = append(m.commentMatches, commentMatch{line: i, start: col, end: col + len(query)})
+				col += len(query) - 1
+			}
+		}
+	}
+	m.matchIndex = min(m.matchIndex, max(len(m.commentMatches)-1, 0))
+}
+
+// nextCommentMatch moves to the next (delta 1) or previous (delta -1)
+// match, wrapping around
+func (m *Model) nextCommentMatch(delta int) {
+	n := len(m.commentMatches)
+	if n == 0 {
+		if m.commentQuery != "" {
+			m.statusMsg = "no matches for " + m.commentQuery
+		}
+		return
+	}
+	m.matchIndex = (m.matchIndex + delta + n) % n
+	m.scrollToMatch()
+}
+
+// scrollToMatch scrolls the current match into view and moves the comment
+// cursor to the comment containing it
+func (m *Model) scrollToMatch() {
+	match := m.commentMatches[m.matchIndex]
+	top := m.viewport.YOffset
+	if match.line < top || match.line >= top+m.viewport.Height {
+		m.viewport.SetYOffset(max(match.line-m.viewport.Height/3, 0))
+	}
+	if !m.visualMode {
+		for i, span := range m.commentSpans {
+			if match.line >= span.start && match.line <= span.end {
+				m.commentCursor = i
+				break
+			}
+		}
+	}
+	m.updateViewportWithHighlight()
+}
+
+// clearCommentQuery removes the search and its highlighting
+func (m *Model) clearCommentQuery() {
+	m.commentQuery = ""
+	m.commentMatches = nil
+	m.matchIndex = 0
+	m.updateViewportWithHighlight()
+}
+
+// lineMatches returns the matches on each line, for highlighting
+func (m Model) lineMatches() map[int][]commentMatch {
+	if len(m.commentMatches) == 0 {
+		return nil
+	}
+	byLine := make(map[int][]commentMatch)
+	for _, match := range m.commentMatches {
+		byLine[match.line] = append(byLine[match.line], match)
+	}
+	return byLine
+}
+
+// highlightMatches wraps the given column ranges of a styled line in
+// match attributes, re-applying them after any escape sequence inside a
+// match so the line's own styling cannot cancel them
+func highlightMatches(line string, matches []commentMatch, current commentMatch) string {
+	var b strings.Builder
+	col, next := 0, 0
+	active := ""
+
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			end := ansiSequenceEnd(line, i)
+			b.WriteString(line[i:end])
+			b.WriteString(active)
+			i = end
+			continue
+		}
+		if next < len(matches) && col == matches[next].start {
+			active = matchOn
+			if matches[next] == current {
+				active += currentMatchOn
+			}
+			b.WriteString(active)
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		b.WriteString(line[i : i+size])
+		i += size
+		col++
+		if next < len(matches) && col == matches[next].end {
+			b.WriteString(matchOff + currentOff)
+			active = ""
+			next++
+		}
+	}
+	if active != "" {
+		b.WriteString(matchOff + currentOff)
+	}
+	return b.String()
+}
+
+// ansiSequenceEnd returns the index just past the escape sequence at i
+func ansiSequenceEnd(s string, i int) int {
+	j := i + 1
+	if j < len(s) && s[j] == '[' {
+		j++
+		for j < len(s) && (s[j] < '@' || s[j] > '~') {
+			j++
+		}
+	}
+	return min(j+1, len(s))
+}
+
+// foldRunes lowercases s rune by rune, so columns in the result line up
+// with columns in s
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func searchComments(t *testing.T, m Model, query string) Model {
+	t.Helper()
+	m = pressKey(t, m, "/")
+	for _, r := range query {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestCommentSearchFindsMatches(t *testing.T) {
+	m := newCommentsModel(t)
+
+	m = searchComments(t, m, "SAYS")
+	if m.editingSearch {
+		t.Fatal("search prompt still open after enter")
+	}
+	if got := len(m.commentMatches); got != 4 {
+		t.Fatalf("matches = %d, want 4", got)
+	}
+	if _, right := m.statusBarContent(); !strings.Contains(right, "match 1/4") {
+		t.Errorf("status = %q, want match 1/4", right)
+	}
+
+	m = pressKey(t, m, "n")
+	m = pressKey(t, m, "n")
+	if got := cursorAuthor(m); got != "carol" {
+		t.Errorf("cursor after n n = %q, want %q", got, "carol")
+	}
+	if _, right := m.statusBarContent(); !strings.Contains(right, "match 3/4") {
+		t.Errorf("status = %q, want match 3/4", right)
+	}
+
+	m = pressKey(t, m, "N")
+	m = pressKey(t, m, "N")
+	m = pressKey(t, m, "N")
+	if got := cursorAuthor(m); got != "dave" {
+		t.Errorf("cursor after wrapping back = %q, want %q", got, "dave")
+	}
+}
+
+func TestCommentSearchHighlightsMatches(t *testing.T) {
+	m := newCommentsModel(t)
+	m = searchComments(t, m, "bob")
+
+	view := m.viewport.View()
+	if !strings.Contains(view, matchOn+currentMatchOn+"bob") {
+		t.Errorf("current match not highlighted:\n%q", view)
+	}
+	if got := strings.Count(view, matchOn); got != 2 {
+		t.Errorf("highlighted %d matches, want 2 (byline and text)", got)
+	}
+	if !strings.Contains(stripAnsi(view), "bob says hi") {
+		t.Errorf("highlighting changed the text:\n%s", stripAnsi(view))
+	}
+}
+
+func TestCommentSearchWithVisualMode(t *testing.T) {
+	m := newCommentsModel(t)
+	m = searchComments(t, m, "dave")
+	m = pressKey(t, m, "v")
+
+	if !strings.Contains(m.viewport.View(), matchOn) {
+		t.Error("match highlighting lost in visual mode")
+	}
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.visualMode || m.commentQuery == "" {
+		t.Fatalf("first esc should only leave visual mode (visual=%v, query=%q)", m.visualMode, m.commentQuery)
+	}
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.commentQuery != "" || m.view != CommentsView {
+		t.Fatalf("second esc should clear the search (query=%q, view=%v)", m.commentQuery, m.view)
+	}
+	if strings.Contains(m.viewport.View(), matchOn) {
+		t.Error("highlighting left after clearing the search")
+	}
+}
+
+func TestCommentSearchNoMatches(t *testing.T) {
+	m := newCommentsModel(t)
+	m = searchComments(t, m, "zzz")
+
+	if _, right := m.statusBarContent(); !strings.Contains(right, "no matches") {
+		t.Errorf("status = %q, want no matches", right)
+	}
+	m = pressKey(t, m, "n")
+	if got := cursorAuthor(m); got != "alice" {
+		t.Errorf("cursor moved to %q with no matches", got)
+	}
+}
+
+func TestHighlightMatchesKeepsStyling(t *testing.T) {
+	line := "\x1b[1mhello\x1b[0m world"
+	matches := []commentMatch{{start: 3, end: 8}}
+	got := highlightMatches(line, matches, commentMatch{})
+
+	if stripAnsi(got) != "hello world" {
+		t.Errorf("text changed: %q", stripAnsi(got))
+	}
+	want := "\x1b[1mhel" + matchOn + "lo\x1b[0m" + matchOn + " wo" + matchOff + currentOff + "rld"
+	if got != want {
+		t.Errorf("highlightMatches = %q, want %q", got, want)
+	}
+}
+
+func TestCommentSearchMatchesWideRunes(t *testing.T) {
+	m := newCommentsModelWith(t, api.NewClient(), []*api.Comment{
+		{Item: &api.Item{Key: "1", By: "ümit", Text: "Ünïcode ÜNÏCODE", Type: "comment"}},
+	})
+	m = searchComments(t, m, "ünï")
+	if got := len(m.commentMatches); got != 2 {
+		t.Errorf("matches = %d, want 2", got)
+	}
+}
@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// commentSpan records which rendered lines belong to a comment's own
+// byline and text (not its replies)
+type commentSpan struct {
+	comment *api.Comment
+	start   int
+	end     int // inclusive
+}
+
+// commentGutter is the first column of every comment line, replaced by
+// the cursor marker for the comment under the cursor
+const commentGutter = " "
+
+// renderCommentLines renders the story header and visible comments,
+// returning the lines and the span of each visible comment
+func (m Model) renderCommentLines() ([]string, []commentSpan) {
+	if m.currentItem == nil {
+		return nil, nil
+	}
+
+	var header strings.Builder
+	header.WriteString(m.renderCommentHeader())
+	header.WriteString(m.styles().Meta.Render(fmt.Sprintf("─── %d comments ───", m.currentItem.Descendants)))
+	header.WriteString("\n")
+
+	lines := strings.Split(header.String(), "\n")
+	var spans []commentSpan
+	for _, comment := range m.comments {
+		lines, spans = m.renderCommentNode(comment, lines, spans)
+	}
+	return lines, spans
+}
+
+// renderCommentNode appends a comment and, unless it is collapsed, its
+// replies
+func (m Model) renderCommentNode(c *api.Comment, lines []string, spans []commentSpan) ([]string, []commentSpan) {
+	indent := strings.Repeat("  ", c.Depth)
+	prefix := commentGutter + indent + m.styles().Indent(c.Depth).Render("│ ")
+	start := len(lines)
+
+	if c.More != nil {
+		lines = append(lines, prefix+m.styles().MoreComments.Render(m.placeholderLabel(c)))
+		lines = append(lines, prefix)
+		return lines, append(spans, commentSpan{comment: c, start: start, end: len(lines) - 1})
+	}
+
+	byline := m.renderCommentByline(c)
+	if m.newComments[c] {
+		byline += " " + m.styles().NewBadge.Render("NEW")
+	}
+	if m.collapsed[c] {
+		lines = append(lines, prefix+byline+" "+m.styles().CommentMeta.Render(collapsedMarker(c)))
+		lines = append(lines, prefix)
+		return lines, append(spans, commentSpan{comment: c, start: start, end: len(lines) - 1})
+	}
+
+	lines = append(lines, prefix+byline)
+	for _, line := range m.renderText(c.Item, m.width-len(indent)-5) {
+		lines = append(lines, prefix+line)
+	}
+	lines = append(lines, prefix)
+	spans = append(spans, commentSpan{comment: c, start: start, end: len(lines) - 1})
+
+	for _, child := range c.Children {
+		lines, spans = m.renderCommentNode(child, lines, spans)
+	}
+	return lines, spans
+}
+
+func collapsedMarker(c *api.Comment) string {
+	if n := countReplies(c); n > 0 {
+		return fmt.Sprintf("[+%d hidden]", n)
+	}
+	return "[collapsed]"
+}
+
+// placeholderLabel describes what selecting a placeholder will load
+func (m Model) placeholderLabel(c *api.Comment) string {
+	switch {
+	case m.loadingMore == c:
+		return "loading..."
+	case len(c.More.Keys) == 0:
+		return "continue this thread →"
+	case c.More.Count == 1:
+		return "load 1 more reply"
+	default:
+		return fmt.Sprintf("load %d more replies", max(c.More.Count, len(c.More.Keys)))
+	}
+}
+
+// countReplies returns the number of comments below c
+func countReplies(c *api.Comment) int {
+	var n int
+	for _, child := range c.Children {
+		if child.More != nil {
+			n += max(child.More.Count, len(child.More.Keys))
+			continue
+		}
+		n += 1 + countReplies(child)
+	}
+	return n
+}
+
+// rebuildComments re-renders the comment view after the tree, collapse
+// state or width changes, keeping the cursor on the same comment
+func (m *Model) rebuildComments() {
+	var current *api.Comment
+	if m.commentCursor < len(m.commentSpans) {
+		current = m.commentSpans[m.commentCursor].comment
+	}
+
+	m.commentLines, m.commentSpans = m.renderCommentLines()
+	m.mutedComments = m.countMutedComments()
+	m.findCommentMatches()
+
+	m.commentCursor = 0
+	for i, span := range m.commentSpans {
+		if span.comment == current {
+			m.commentCursor = i
+			break
+		}
+	}
+	m.updateViewportWithHighlight()
+}
+
+// cursorSpan returns the span of the comment under the cursor
+func (m Model) cursorSpan() (commentSpan, bool) {
+	if m.commentCursor < 0 || m.commentCursor >= len(m.commentSpans) {
+		return commentSpan{}, false
+	}
+	return m.commentSpans[m.commentCursor], true
+}
+
+// moveCommentCursor moves the cursor by delta comments and scrolls it
+// into view
+func (m *Model) moveCommentCursor(delta int) {
+	if len(m.commentSpans) == 0 {
+		return
+	}
+	m.commentCursor = max(0, min(m.commentCursor+delta, len(m.commentSpans)-1))
+	m.scrollToCursor()
+}
+
+// scrollToCursor adjusts the viewport so the cursor comment is visible
+func (m *Model) scrollToCursor() {
+	span, ok := m.cursorSpan()
+	if !ok {
+		m.updateViewportWithHighlight()
+		return
+	}
+	top := m.viewport.YOffset
+	height := max(m.viewport.Height, 1)
+	switch {
+	case span.start < top:
+		top = span.start
+	case span.end >= top+height:
+		top = min(span.start, span.end-height+1)
+	}
+	if m.commentCursor == 0 {
+		// Keep the story header visible when returning to the first comment
+		top = 0
+	}
+	m.viewport.SetYOffset(top)
+	m.updateViewportWithHighlight()
+}
+
+// syncCursorToViewport moves the cursor to the first comment visible
+// after the viewport scrolled by a page
+func (m *Model) syncCursorToViewport() {
+	top := m.viewport.YOffset
+	bottom := top + m.viewport.Height
+	if span, ok := m.cursorSpan(); ok && span.start >= top && span.start < bottom {
+		m.updateViewportWithHighlight()
+		return
+	}
+	for i, span := range m.commentSpans {
+		if span.start >= top {
+			m.commentCursor = i
+			break
+		}
+	}
+	m.updateViewportWithHighlight()
+}
+
+// toggleCollapse collapses or expands the comment under the cursor
+func (m *Model) toggleCollapse() {
+	span, ok := m.cursorSpan()
+	if !ok {
+		return
+	}
+	if m.collapsed[span.comment] {
+		delete(m.collapsed, span.comment)
+	} else {
+		m.collapsed[span.comment] = true
+	}
+	m.rebuildComments()
+	m.scrollToCursor()
+}
+
+// toggleCollapseAll collapses every top-level thread, or expands
+// everything if all of them are already collapsed
+func (m *Model) toggleCollapseAll() {
+	allCollapsed := true
+	for _, c := range m.comments {
+		if !m.collapsed[c] {
+			allCollapsed = false
+			break
+		}
+	}
+
+	var thread *api.Comment
+	if span, ok := m.cursorSpan(); ok {
+		thread = topLevelAncestor(m.comments, span.comment)
+	}
+
+	m.collapsed = make(map[*api.Comment]bool)
+	if !allCollapsed {
+		for _, c := range m.comments {
+			m.collapsed[c] = true
+		}
+	}
+	m.rebuildComments()
+	m.focusComment(thread)
+}
+
+// focusComment moves the cursor to c if it is visible
+func (m *Model) focusComment(c *api.Comment) {
+	for i, span := range m.commentSpans {
+		if span.comment == c {
+			m.commentCursor = i
+			break
+		}
+	}
+	m.scrollToCursor()
+}
+
+// jumpToNewComment moves the cursor to the next new comment, wrapping
+// around to the first one
+func (m *Model) jumpToNewComment() {
+	n := len(m.commentSpans)
+	for i := 1; i <= n; i++ {
+		idx := (m.commentCursor + i) % n
+		if m.newComments[m.commentSpans[idx].comment] {
+			m.commentCursor = idx
+			m.scrollToCursor()
+			return
+		}
+	}
+	m.statusMsg = "no new comments"
+}
+
+// cursorPlaceholder returns the placeholder comment under the cursor, if any
+func (m Model) cursorPlaceholder() *api.Comment {
+	if m.view != CommentsView || m.visualMode {
+		return nil
+	}
+	span, ok := m.cursorSpan()
+	if !ok || span.comment.More == nil {
+		return nil
+	}
+	return span.comment
+}
+
+// expandPlaceholder starts loading the replies a placeholder stands for
+func (m Model) expandPlaceholder(more *api.Comment) (tea.Model, tea.Cmd) {
+	if m.loadingMore != nil {
+		return m, nil
+	}
+	cmd := m.loadMoreComments(more)
+	if cmd == nil {
+		return m, nil
+	}
+	m.loadingMore = more
+	m.statusMsg = ""
+	m.rebuildComments()
+	return m, cmd
+}
+
+// spliceComments replaces a placeholder with the comments loaded for it,
+// leaving the cursor on the first of them
+func (m *Model) spliceComments(more *api.Comment, loaded []*api.Comment) {
+	comments, ok := api.ReplaceComment(m.comments, more, loaded)
+	if !ok {
+		return
+	}
+	m.comments = comments
+
+	index := m.commentCursor
+	m.rebuildComments()
+	if len(loaded) > 0 {
+		m.focusComment(loaded[0])
+		return
+	}
+	m.commentCursor = min(index, max(len(m.commentSpans)-1, 0))
+	m.scrollToCursor()
+}
+
+// topLevelAncestor returns the top-level comment whose thread contains c
+func topLevelAncestor(roots []*api.Comment, c *api.Comment) *api.Comment {
+	for _, root := range roots {
+		if root == c || containsComment(root.Children, c) {
+			return root
+		}
+	}
+	return nil
+}
+
+func containsComment(comments []*api.Comment, target *api.Comment) bool {
+	for _, c := range comments {
+		if c == target || containsComment(c.Children, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JonathanWThom/feedme/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newCommentsModel returns a model showing a small comment tree:
+//
+//	alice
+//	  bob
+//	    carol
+//	dave
+func newCommentsModel(t *testing.T) Model {
+	t.Helper()
+	comments := []*api.Comment{
+		testComment("alice", 0, testComment("bob", 1, testComment("carol", 2))),
+		testComment("dave", 0),
+	}
+	return newCommentsModelWith(t, api.NewClient(), comments)
+}
+
+func testComment(by string, depth int, children ...*api.Comment) *api.Comment {
+	return &api.Comment{
+		Item:     &api.Item{Key: by, By: by, Text: by + " says hi", Type: "comment"},
+		Depth:    depth,
+		Children: children,
+	}
+}
+
+func newCommentsModelWith(t *testing.T, source api.Source, comments []*api.Comment) Model {
+	t.Helper()
+	m := NewWithSource(source, nil)
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	m.view = CommentsView
+	m.currentItem = &api.Item{Title: "A story", Descendants: 4}
+	m.commentSource = source
+	m.loading = true
+	return update(t, m, commentsLoadedMsg{comments: comments})
+}
+
+func update(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	next, _ := updateCmd(t, m, msg)
+	return next
+}
+
+func updateCmd(t *testing.T, m Model, msg tea.Msg) (Model, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return next.(Model), cmd
+}
+
+func pressKey(t *testing.T, m Model, k string) Model {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch k {
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "space":
+		msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	}
+	return update(t, m, msg)
+}
+
+func cursorAuthor(m Model) string {
+	span, ok := m.cursorSpan()
+	if !ok {
+		return ""
+	}
+	return span.comment.By
+}
+
+func visibleAuthors(m Model) []string {
+	var authors []string
+	for _, span := range m.commentSpans {
+		authors = append(authors, span.comment.By)
+	}
+	return authors
+}
+
+func TestCommentCursorMovesByComment(t *testing.T) {
+	m := newCommentsModel(t)
+
+	want := []string{"alice", "bob", "carol", "dave", "dave"}
+	for i, author := range want {
+		if got := cursorAuthor(m); got != author {
+			t.Fatalf("step %d: cursor on %q, want %q", i, got, author)
+		}
+		m = pressKey(t, m, "down")
+	}
+
+	m = pressKey(t, m, "up")
+	if got := cursorAuthor(m); got != "carol" {
+		t.Errorf("after up: cursor on %q, want %q", got, "carol")
+	}
+}
+
+func TestCollapseHidesReplies(t *testing.T) {
+	m := newCommentsModel(t)
+
+	m = pressKey(t, m, "space")
+	if got := strings.Join(visibleAuthors(m), ","); got != "alice,dave" {
+		t.Errorf("visible after collapse = %s, want alice,dave", got)
+	}
+	content := stripAnsi(strings.Join(m.commentLines, "\n"))
+	if !strings.Contains(content, "[+2 hidden]") {
+		t.Errorf("collapsed thread missing reply count marker:\n%s", content)
+	}
+	if strings.Contains(content, "bob says hi") {
+		t.Errorf("collapsed reply still rendered:\n%s", content)
+	}
+
+	m = pressKey(t, m, "down")
+	if got := cursorAuthor(m); got != "dave" {
+		t.Errorf("cursor after collapse = %q, want next thread %q", got, "dave")
+	}
+
+	m = pressKey(t, m, "up")
+	m = pressKey(t, m, "space")
+	if got := strings.Join(visibleAuthors(m), ","); got != "alice,bob,carol,dave" {
+		t.Errorf("visible after expand = %s, want alice,bob,carol,dave", got)
+	}
+}
+
+func TestCollapseAllToggles(t *testing.T) {
+	m := newCommentsModel(t)
+	m = pressKey(t, m, "down")
+	m = pressKey(t, m, "down") // carol
+
+	m = pressKey(t, m, "C")
+	if got := strings.Join(visibleAuthors(m), ","); got != "alice,dave" {
+		t.Errorf("visible after collapse all = %s, want alice,dave", got)
+	}
+	if got := cursorAuthor(m); got != "alice" {
+		t.Errorf("cursor after collapse all = %q, want thread root %q", got, "alice")
+	}
+
+	m = pressKey(t, m, "C")
+	if got := len(m.commentSpans); got != 4 {
+		t.Errorf("visible after expand all = %d comments, want 4", got)
+	}
+}
+
+func TestCommentCursorMarker(t *testing.T) {
+	m := newCommentsModel(t)
+	m = pressKey(t, m, "down")
+
+	view := stripAnsi(m.viewport.View())
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "bob says hi") && !strings.HasPrefix(line, "▌") {
+			t.Errorf("cursor comment line missing marker: %q", line)
+		}
+		if strings.Contains(line, "alice says hi") && strings.HasPrefix(line, "▌") {
+			t.Errorf("non-cursor comment line has marker: %q", line)
+		}
+	}
+}
+
+func TestCommentBylineWithoutTime(t *testing.T) {
+	m := newCommentsModel(t)
+	missing := &api.Comment{Item: &api.Item{Type: "comment", Deleted: true, Text: "[missing comment]"}}
+	if got := stripAnsi(m.renderCommentByline(missing)); got != "[deleted]" {
+		t.Errorf("placeholder byline = %q, want no time", got)
+	}
+	dated := testComment("erin", 0)
+	dated.Time = time.Now().Add(-2 * time.Hour).Unix()
+	if got := stripAnsi(m.renderCommentByline(dated)); got != "erin 2 hours ago" {
+		t.Errorf("byline = %q", got)
+	}
+}
+
+// moreSource serves a fixed set of comments for any placeholder
+type moreSource struct {
+	api.Source
+	loaded []*api.Comment
+}
+
+func (s moreSource) LoadMoreComments(story *api.Item, more *api.Comment) ([]*api.Comment, error) {
+	return s.loaded, nil
+}
+
+func TestExpandPlaceholderSplicesComments(t *testing.T) {
+	more := &api.Comment{
+		Item:  &api.Item{Key: "more1", Type: "more"},
+		Depth: 1,
+		More:  &api.MoreComments{Count: 2, Keys: []string{"t1_b", "t1_c"}},
+	}
+	comments := []*api.Comment{testComment("alice", 0, testComment("zed", 1), more), testComment("dave", 0)}
+	source := moreSource{
+		Source: api.NewClient(),
+		loaded: []*api.Comment{testComment("bob", 1), testComment("carol", 1)},
+	}
+	m := newCommentsModelWith(t, source, comments)
+
+	content := stripAnsi(strings.Join(m.commentLines, "\n"))
+	if !strings.Contains(content, "load 2 more replies") {
+		t.Fatalf("placeholder not rendered:\n%s", content)
+	}
+
+	m = pressKey(t, m, "down")
+	m = pressKey(t, m, "down")
+	if m.cursorPlaceholder() != more {
+		t.Fatalf("cursor on %q, want the placeholder", cursorAuthor(m))
+	}
+
+	m, cmd := updateCmd(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter on a placeholder returned no command")
+	}
+	if !strings.Contains(stripAnsi(strings.Join(m.commentLines, "\n")), "loading...") {
+		t.Error("placeholder not marked as loading")
+	}
+
+	m = update(t, m, cmd())
+	if got := strings.Join(visibleAuthors(m), ","); got != "alice,zed,bob,carol,dave" {
+		t.Errorf("visible after expanding = %s, want alice,zed,bob,carol,dave", got)
+	}
+	if got := cursorAuthor(m); got != "bob" {
+		t.Errorf("cursor after expanding on %q, want first loaded comment %q", got, "bob")
+	}
+}
+
+func TestMarkdownComments(t *testing.T) {
+	comment := testComment("alice", 0)
+	comment.Text = "**Strong** opinion with `code`\n\n> quoted [source](https://example.com)"
+	comment.Format = api.FormatMarkdown
+
+	m := NewWithSource(api.NewClient(), nil)
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	m.view = CommentsView
+	m.currentItem = &api.Item{
+		Title:  "Ask: what do you use?",
+		Text:   "Looking for *recommendations*.",
+		Format: api.FormatMarkdown,
+	}
+	m.commentSource = m.source
+	m = update(t, m, commentsLoadedMsg{comments: []*api.Comment{comment}})
+
+	content := stripAnsi(strings.Join(m.commentLines, "\n"))
+	for _, want := range []string{"Looking for recommendations.", "Strong opinion with code", "│ quoted source[1]", "[1] https://example.com"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("comments missing %q:\n%s", want, content)
+		}
+	}
+	for _, raw := range []string{"**", "*recommendations*", "`", "> quoted"} {
+		if strings.Contains(content, raw) {
+			t.Errorf("comments contain raw Markdown %q:\n%s", raw, content)
+		}
+	}
+}
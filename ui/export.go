@@ -0,0 +1,59 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/export"
+)
+
+// exportFormats lists the keys of the export prompt and the export
+// formats they pick, in prompt order
+var exportFormats = []struct{ key, format string }{
+	{"m", "markdown"},
+	{"h", "html"},
+	{"j", "json"},
+}
+
+// exportHint lists the export prompt's choices for the status bar
+func exportHint() string {
+	var hints []string
+	for _, f := range exportFormats {
+		hints = append(hints, f.key+":"+f.format)
+	}
+	return joinHints(append(hints, "esc:cancel")...)
+}
+
+// startExport asks which format to export the thread in
+func (m Model) startExport() (tea.Model, tea.Cmd) {
+	if m.view != CommentsView || m.visualMode || m.loading || m.currentItem == nil {
+		return m, nil
+	}
+	m.choosingExport = true
+	return m, nil
+}
+
+// handleExportInput saves the thread in the chosen format to the export
+// directory; any other key cancels
+func (m Model) handleExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.choosingExport = false
+	var format string
+	for _, f := range exportFormats {
+		if f.key == msg.String() {
+			format = f.format
+		}
+	}
+	if format == "" {
+		return m, nil
+	}
+
+	dir, err := exportDir()
+	if err == nil {
+		thread := export.NewThread(m.activeSource(), m.currentItem, m.comments)
+		var path string
+		if path, err = export.Save(dir, format, thread); err == nil {
+			m.statusMsg = "exported to " + path
+			return m, nil
+		}
+	}
+	m.statusMsg = "export failed: " + err.Error()
+	return m, nil
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestExportThread(t *testing.T) {
+	dir := t.TempDir()
+	prev := exportDir
+	exportDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { exportDir = prev })
+
+	m := newCommentsModelWith(t, api.NewClient(), []*api.Comment{testComment("alice", 0)})
+	m.currentItem.Key = "42"
+
+	m = pressKey(t, m, "e")
+	if !m.choosingExport {
+		t.Fatal("e did not open the export prompt")
+	}
+	if view := stripAnsi(m.View()); !strings.Contains(view, "m:markdown  h:html  j:json") {
+		t.Errorf("status bar does not offer the formats:\n%s", view)
+	}
+
+	m = pressKey(t, m, "h")
+	path := filepath.Join(dir, "hn-42-a-story.html")
+	if m.choosingExport || m.statusMsg != "exported to "+path {
+		t.Errorf("prompt open = %v, statusMsg = %q", m.choosingExport, m.statusMsg)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "<summary><span class=\"author\">alice</span>") {
+		t.Errorf("exported file = %q, %v", data, err)
+	}
+
+	// Any other key cancels
+	m = pressKey(t, m, "e")
+	m = pressKey(t, m, "esc")
+	if m.choosingExport || m.view != CommentsView {
+		t.Errorf("esc left prompt open = %v, view = %v", m.choosingExport, m.view)
+	}
+}
+
+func TestExportThreadReportsErrors(t *testing.T) {
+	prev := exportDir
+	exportDir = func() (string, error) { return "", errors.New("no config dir") }
+	t.Cleanup(func() { exportDir = prev })
+
+	m := newCommentsModelWith(t, api.NewClient(), []*api.Comment{testComment("alice", 0)})
+	m = pressKey(t, m, "e")
+	m = pressKey(t, m, "m")
+	if m.statusMsg != "export failed: no config dir" {
+		t.Errorf("statusMsg = %q", m.statusMsg)
+	}
+}
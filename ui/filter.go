@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+)
+
+// startSearch opens the search prompt, which filters stories in the
+// story list and searches the thread in the comments view
+func (m Model) startSearch() (tea.Model, tea.Cmd) {
+	switch {
+	case m.view == StoriesView:
+		m.searchInput = m.storyFilter
+	case m.view == CommentsView && !m.visualMode:
+		m.searchInput = m.commentQuery
+	default:
+		return m, nil
+	}
+	m.editingSearch = true
+	return m, nil
+}
+
+// handleSearchInput handles keys while the search prompt is open. The
+// story list narrows, or comment matches update, as the query is typed.
+func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.editingSearch = false
+		if m.view == StoriesView && m.storyFilter != "" && len(m.shown) == 0 {
+			// Nothing loaded matches; look through the remaining stories
+			return m.searchMoreStories()
+		}
+		return m, nil
+	case tea.KeyEsc:
+		m.editingSearch = false
+		m.searchInput = ""
+		if m.view == CommentsView {
+			m.clearCommentQuery()
+		} else {
+			m.clearStoryFilter()
+		}
+		return m, nil
+	case tea.KeyBackspace:
+		if len(m.searchInput) > 0 {
+			runes := []rune(m.searchInput)
+			m.searchInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		m.searchInput += string(msg.Runes)
+	case tea.KeySpace:
+		m.searchInput += " "
+	default:
+		return m, nil
+	}
+
+	query := strings.TrimSpace(m.searchInput)
+	if m.view == CommentsView {
+		m.setCommentQuery(query)
+		return m, nil
+	}
+	m.storyFilter = query
+	m.refreshShown()
+	return m, nil
+}
+
+func (m *Model) clearStoryFilter() {
+	m.searchInput = ""
+	m.storyFilter = ""
+	m.searchingMore = false
+	m.refreshShown()
+}
+
+// nextMatch moves between search matches in the current view
+func (m Model) nextMatch(delta int) (tea.Model, tea.Cmd) {
+	if m.view == CommentsView {
+		m.nextCommentMatch(delta)
+		return m, nil
+	}
+	return m.nextStoryMatch(delta)
+}
+
+// nextStoryMatch moves to the next (delta 1) or previous (delta -1)
+// matching story. Past the last loaded match it searches the stories that
+// have not been loaded yet.
+func (m Model) nextStoryMatch(delta int) (tea.Model, tea.Cmd) {
+	if m.view != StoriesView || m.storyFilter == "" {
+		return m, nil
+	}
+	switch {
+	case delta > 0 && m.cursor >= len(m.shown)-1:
+		if len(m.storyIDs) > len(m.stories) {
+			return m.searchMoreStories()
+		}
+		m.cursor = 0
+	case delta < 0 && m.cursor == 0:
+		m.cursor = max(len(m.shown)-1, 0)
+	default:
+		m.cursor += delta
+	}
+	m.adjustOffset()
+	return m, nil
+}
+
+// searchMoreStories loads further batches until another story matches
+// the filter or every story has been loaded
+func (m Model) searchMoreStories() (tea.Model, tea.Cmd) {
+	if len(m.storyIDs) <= len(m.stories) {
+		m.statusMsg = "no more matches"
+		return m, nil
+	}
+	m.searchingMore = true
+	m.searchAfter = nil
+	if len(m.stories) > 0 {
+		m.searchAfter = m.stories[len(m.stories)-1]
+	}
+	return m.maybeLoadNextBatch()
+}
+
+// continueStorySearch is called after a batch loads while searching: it
+// moves to the first new match, or keeps loading if there is none yet
+func (m *Model) continueStorySearch() {
+	if !m.searchingMore {
+		return
+	}
+	if i := m.firstNewMatch(); i >= 0 {
+		m.searchingMore = false
+		m.cursor = i
+		m.adjustOffset()
+		return
+	}
+	if len(m.storyIDs) <= len(m.stories) {
+		m.searchingMore = false
+		m.statusMsg = "no more matches"
+	}
+}
+
+// firstNewMatch returns the index in the shown stories of the first match
+// loaded after searchAfter, or -1 if there is none. Matches are found by
+// story rather than position, since pinned stories are shown out of
+// load order.
+func (m Model) firstNewMatch() int {
+	start := 0
+	if m.searchAfter != nil {
+		start = slices.Index(m.stories, m.searchAfter) + 1
+	}
+	for _, story := range m.stories[start:] {
+		if i := slices.Index(m.shown, story); i >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesStoryFilter reports whether every word of the filter appears in
+// the story's title, domain, author or tags
+func matchesStoryFilter(story *api.Item, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	fields := append([]string{story.Title, story.Domain(), story.By}, story.Tags...)
+	haystack := strings.ToLower(strings.Join(fields, " "))
+	for _, term := range strings.Fields(strings.ToLower(filter)) {
+		if !strings.Contains(haystack, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/mute"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeSource serves stories from memory
+type fakeSource struct {
+	api.Source
+	items map[string]*api.Item
+}
+
+func newFakeSource(stories []*api.Item) fakeSource {
+	s := fakeSource{Source: api.NewClient(), items: make(map[string]*api.Item)}
+	for _, story := range stories {
+		s.items[story.Key] = story
+	}
+	return s
+}
+
+func (s fakeSource) FetchItems(ids []string) ([]*api.Item, error) {
+	items := make([]*api.Item, len(ids))
+	for i, id := range ids {
+		items[i] = s.items[id]
+	}
+	return items, nil
+}
+
+// newFilterModel returns a model with the first batch of 60 stories
+// loaded. Stories 3, 10 and 45 mention Go.
+func newFilterModel(t *testing.T) Model {
+	t.Helper()
+	var stories []*api.Item
+	var ids []string
+	for i := 0; i < 60; i++ {
+		story := &api.Item{Key: fmt.Sprint(i), Title: fmt.Sprintf("Story %d", i), By: "someone"}
+		switch i {
+		case 3:
+			story.Title = "Go 1.30 released"
+		case 10:
+			story.URL = "https://go.dev/blog/post"
+		case 45:
+			story.Tags = []string{"go"}
+		}
+		stories = append(stories, story)
+		ids = append(ids, story.Key)
+	}
+
+	m := NewWithSource(newFakeSource(stories), nil)
+	m = update(t, m, tea.WindowSizeMsg{Width: 100, Height: 40})
+	m, cmd := updateCmd(t, m, storyIDsLoadedMsg{ids: ids})
+	return runCmd(t, m, cmd)
+}
+
+// runCmd feeds the messages produced by cmd back into the model until no
+// more commands are returned
+func runCmd(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	for cmd != nil {
+		m, cmd = updateCmd(t, m, cmd())
+	}
+	return m
+}
+
+func typeSearch(t *testing.T, m Model, query string) Model {
+	t.Helper()
+	m = pressKey(t, m, "/")
+	for _, r := range query {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestStoryFilterNarrowsLive(t *testing.T) {
+	m := newFilterModel(t)
+	if len(m.shown) != 30 {
+		t.Fatalf("loaded %d stories, want the first batch of 30", len(m.shown))
+	}
+
+	m = typeSearch(t, m, "g")
+	if len(m.shown) != 2 {
+		t.Fatalf("after typing g: %v, want stories 3 and 10", storyKeys(m.shown))
+	}
+	if !strings.Contains(stripAnsi(m.View()), "/g_") {
+		t.Error("search prompt not shown while typing")
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o.dev")})
+	if got := storyKeys(m.shown); len(got) != 1 || got[0] != "10" {
+		t.Errorf("filter go.dev matched %v, want story 10 by domain", got)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.storyFilter != "go" || len(m.shown) != 2 {
+		t.Fatalf("after enter: filter %q with %d stories, want go with 2", m.storyFilter, len(m.shown))
+	}
+	if !strings.Contains(stripAnsi(m.View()), "[filter: go]") {
+		t.Error("status bar does not show the active filter")
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.storyFilter != "" || len(m.shown) != 30 {
+		t.Errorf("esc left filter %q with %d stories, want all 30", m.storyFilter, len(m.shown))
+	}
+}
+
+func TestStoryFilterNextPrevMatch(t *testing.T) {
+	m := newFilterModel(t)
+	m = typeSearch(t, m, "someone")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = pressKey(t, m, "N")
+	if got := m.currentStory().Key; got != "29" {
+		t.Errorf("N from the first match went to story %s, want the last loaded (29)", got)
+	}
+	m = pressKey(t, m, "g")
+	m = pressKey(t, m, "n")
+	if got := m.currentStory().Key; got != "1" {
+		t.Errorf("n went to story %s, want 1", got)
+	}
+}
+
+func TestStoryFilterSearchesUnloadedStories(t *testing.T) {
+	m := newFilterModel(t)
+	m = typeSearch(t, m, "go")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	m = pressKey(t, m, "n")
+	if got := m.currentStory().Key; got != "10" {
+		t.Fatalf("n went to story %s, want 10", got)
+	}
+
+	// Past the last loaded match, n loads the next batch to find story 45
+	m, cmd := updateCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd == nil {
+		t.Fatal("n past the last loaded match did not load more stories")
+	}
+	m = runCmd(t, m, cmd)
+	if got := m.currentStory().Key; got != "45" {
+		t.Errorf("search of unloaded stories went to %s, want 45", got)
+	}
+	if len(m.stories) != 60 {
+		t.Errorf("loaded %d stories, want 60", len(m.stories))
+	}
+
+	m = pressKey(t, m, "n")
+	if got := m.currentStory().Key; got != "3" {
+		t.Errorf("n after the last match went to %s, want to wrap to 3", got)
+	}
+}
+
+func TestStoryFilterSearchFindsPinnedMatch(t *testing.T) {
+	m := newFilterModel(t)
+	// Story 45 is watched, so it is pinned above the earlier matches once
+	// it loads
+	m.watch = mute.CompileKeywords([]string{"story 45"})
+	m = typeSearch(t, m, "go")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = pressKey(t, m, "n")
+
+	m, cmd := updateCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = runCmd(t, m, cmd)
+	if got := m.currentStory().Key; got != "45" || m.cursor != 0 {
+		t.Errorf("search of unloaded stories went to %s at %d, want pinned story 45 at 0", got, m.cursor)
+	}
+}
@@ -1,17 +1,30 @@
 package ui
 
 import (
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/export"
 	"github.com/pkg/browser"
 )
 
+// openURL, writeClipboard and exportDir reach outside the terminal;
+// tests replace them
+var (
+	openURL        = browser.OpenURL
+	writeClipboard = clipboard.WriteAll
+	exportDir      = export.Dir
+)
+
 func (m *Model) handlePageDown() {
 	if m.view == StoriesView {
-		m.cursor = min(m.cursor+10, len(m.stories)-1)
+		m.cursor = max(min(m.cursor+10, len(m.shown)-1), 0)
 		m.adjustOffset()
 	} else {
 		m.viewport.HalfViewDown()
+		if !m.visualMode {
+			m.syncCursorToViewport()
+		}
 	}
 }
 
@@ -21,6 +34,9 @@ func (m *Model) handlePageUp() {
 		m.adjustOffset()
 	} else {
 		m.viewport.HalfViewUp()
+		if !m.visualMode {
+			m.syncCursorToViewport()
+		}
 	}
 }
 
@@ -30,15 +46,23 @@ func (m *Model) handleHome() {
 		m.offset = 0
 	} else {
 		m.viewport.GotoTop()
+		if !m.visualMode {
+			m.commentCursor = 0
+			m.updateViewportWithHighlight()
+		}
 	}
 }
 
 func (m *Model) handleEnd() {
 	if m.view == StoriesView {
-		m.cursor = len(m.stories) - 1
+		m.cursor = max(len(m.shown)-1, 0)
 		m.adjustOffset()
 	} else {
 		m.viewport.GotoBottom()
+		if !m.visualMode {
+			m.commentCursor = max(len(m.commentSpans)-1, 0)
+			m.updateViewportWithHighlight()
+		}
 	}
 }
 
@@ -85,8 +109,8 @@ func (m Model) openSourcePicker() (tea.Model, tea.Cmd) {
 	}
 	m.view = SourcePickerView
 	m.sourcePickerCursor = 0
-	m.subredditInput = ""
-	m.editingSubreddit = false
+	m.pickerInput = ""
+	m.editingInput = false
 	return m, nil
 }
 
@@ -94,37 +118,39 @@ func (m Model) handleUp() (tea.Model, tea.Cmd) {
 	if m.view == StoriesView && m.cursor > 0 {
 		m.cursor--
 		m.adjustOffset()
-	} else if m.view == CommentsView {
+	} else if m.view == CommentsView && m.visualMode {
 		m.viewport.LineUp(1)
-		if m.visualMode {
-			m.visualEnd = m.viewport.YOffset
-			m.updateViewportWithHighlight()
-		}
+		m.visualEnd = m.viewport.YOffset
+		m.updateViewportWithHighlight()
+	} else if m.view == CommentsView {
+		m.moveCommentCursor(-1)
 	}
 	return m, nil
 }
 
 func (m Model) handleDown() (tea.Model, tea.Cmd) {
-	if m.view == StoriesView && m.cursor < len(m.stories)-1 {
+	if m.view == StoriesView && m.cursor < len(m.shown)-1 {
 		m.cursor++
 		m.adjustOffset()
 		return m.maybeLoadNextBatch()
 	}
-	if m.view == CommentsView {
+	if m.view == CommentsView && m.visualMode {
 		m.viewport.LineDown(1)
-		if m.visualMode {
-			m.visualEnd = m.viewport.YOffset
-			m.updateViewportWithHighlight()
-		}
+		m.visualEnd = m.viewport.YOffset
+		m.updateViewportWithHighlight()
+	} else if m.view == CommentsView {
+		m.moveCommentCursor(1)
 	}
 	return m, nil
 }
 
+// maybeLoadNextBatch loads more stories when they are wanted and some
+// remain to be loaded
 func (m Model) maybeLoadNextBatch() (tea.Model, tea.Cmd) {
-	if m.cursor < len(m.stories)-5 || len(m.storyIDs) <= len(m.stories) {
+	if !m.wantsNextBatch() || len(m.storyIDs) <= len(m.stories) {
 		return m, nil
 	}
-	end := min(len(m.stories)+30, len(m.storyIDs))
+	end := min(len(m.stories)+m.batchSize, len(m.storyIDs))
 	nextBatch := m.storyIDs[len(m.stories):end]
 	if len(nextBatch) == 0 {
 		return m, nil
@@ -133,27 +159,60 @@ func (m Model) maybeLoadNextBatch() (tea.Model, tea.Cmd) {
 	return m, m.loadStories(nextBatch)
 }
 
+// wantsNextBatch reports whether more stories should be loaded: when the
+// cursor nears the end of the list or hiding read or muted stories leaves
+// the screen underfilled. While filtering, batches only load on request.
+func (m Model) wantsNextBatch() bool {
+	if m.storyFilter != "" {
+		return m.searchingMore
+	}
+	hiding := m.hideRead || (m.mutedStories > 0 && !m.showMuted)
+	return m.cursor >= len(m.shown)-5 || (hiding && len(m.shown) < m.visibleStoryCount())
+}
+
 func (m *Model) openCurrentURL() {
 	story := m.currentStory()
 	if story == nil {
 		return
 	}
 	if story.URL != "" {
-		_ = browser.OpenURL(story.URL)
+		_ = openURL(story.URL)
 	} else {
-		_ = browser.OpenURL(m.source.StoryURL(story))
+		_ = openURL(m.activeSource().StoryURL(story))
+	}
+	m.markRead(m.activeSource(), story)
+}
+
+// activeSource returns the source of the story being shown
+func (m Model) activeSource() api.Source {
+	if m.view == CommentsView && m.commentSource != nil {
+		return m.commentSource
 	}
+	if m.view == ReaderView && m.readerSource != nil {
+		return m.readerSource
+	}
+	return m.source
 }
 
 func (m Model) openComments() (tea.Model, tea.Cmd) {
-	if m.view != StoriesView || len(m.stories) == 0 {
+	if m.view != StoriesView || len(m.shown) == 0 {
 		return m, nil
 	}
-	story := m.stories[m.cursor]
-	if story == nil || story.Descendants == 0 {
+	story := m.shown[m.cursor]
+	if story == nil || (story.Descendants == 0 && !story.HasCommentFeed()) {
 		return m, nil
 	}
+	return m.showComments(story, m.source)
+}
+
+// showComments switches to the comments view for story, loading them
+// from source
+func (m Model) showComments(story *api.Item, source api.Source) (tea.Model, tea.Cmd) {
+	m.prevView = m.view
 	m.currentItem = story
+	m.commentSource = source
+	m.seenComments = m.seenBefore(source, story)
+	m.newComments = nil
 	m.view = CommentsView
 	m.loading = true
 	m.comments = nil
@@ -161,26 +220,42 @@ func (m Model) openComments() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleBack() (tea.Model, tea.Cmd) {
+	if m.view == StoriesView && m.storyFilter != "" {
+		m.clearStoryFilter()
+		return m, nil
+	}
 	if m.visualMode {
 		m.visualMode = false
 		m.updateViewportWithHighlight()
 		return m, nil
 	}
+	if m.view == CommentsView && m.commentQuery != "" {
+		m.clearCommentQuery()
+		return m, nil
+	}
 	if m.view == CommentsView {
-		m.view = StoriesView
+		m.view = m.prevView
 		m.comments = nil
 		m.commentLines = nil
+		m.commentSpans = nil
+		m.loadingMore = nil
+		m.statusMsg = ""
+		m.commentQuery = ""
+		m.commentMatches = nil
 	}
 	return m, nil
 }
 
 func (m Model) currentStory() *api.Item {
-	if m.view == StoriesView && len(m.stories) > 0 {
-		return m.stories[m.cursor]
+	if m.view == StoriesView && len(m.shown) > 0 {
+		return m.shown[m.cursor]
 	}
 	if m.view == CommentsView {
 		return m.currentItem
 	}
+	if m.view == ReaderView {
+		return m.articleStory
+	}
 	return nil
 }
 
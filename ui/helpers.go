@@ -1,7 +1,6 @@
 package ui
 
 import (
-	"html"
 	"regexp"
 	"strings"
 )
@@ -12,20 +11,6 @@ func stripAnsi(s string) string {
 	return ansiRegex.ReplaceAllString(s, "")
 }
 
-func cleanHTML(s string) string {
-	s = html.UnescapeString(s)
-	s = regexp.MustCompile(`<p>`).ReplaceAllString(s, "\n\n")
-	s = regexp.MustCompile(`<br\s*/?\s*>`).ReplaceAllString(s, "\n")
-	s = regexp.MustCompile(`<a\s+href="([^"]*)"[^>]*>([^<]*)</a>`).ReplaceAllString(s, "$2 [$1]")
-	s = regexp.MustCompile(`<[^>]*>`).ReplaceAllString(s, "")
-	s = strings.TrimSpace(s)
-	return s
-}
-
-func wrapText(s string, width int) string {
-	return strings.Join(wrapTextLines(s, width), "\n")
-}
-
 func wrapTextLines(s string, width int) []string {
 	if width <= 0 {
 		width = 80
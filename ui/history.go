@@ -0,0 +1,128 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+// visit returns the last recorded visit to a story from the current source
+func (m Model) visit(story *api.Item) (store.Visit, bool) {
+	if m.history == nil || story == nil {
+		return store.Visit{}, false
+	}
+	return m.history.Get(m.source.Spec(), story.Key)
+}
+
+// isRead reports whether a story from the current source has been opened
+func (m Model) isRead(story *api.Item) bool {
+	_, ok := m.visit(story)
+	return ok
+}
+
+// markRead records that a story from source was opened
+func (m *Model) markRead(source api.Source, story *api.Item) {
+	if m.history == nil {
+		return
+	}
+	if err := m.history.MarkRead(source.Spec(), story); err != nil {
+		m.statusMsg = "failed to save history: " + err.Error()
+	}
+}
+
+// seenBefore returns the keys of comments loaded on earlier visits to a
+// story, or nil if its comments have never been viewed
+func (m Model) seenBefore(source api.Source, story *api.Item) map[string]bool {
+	if m.history == nil {
+		return nil
+	}
+	v, ok := m.history.Get(source.Spec(), story.Key)
+	if !ok || v.CommentsAt.IsZero() {
+		return nil
+	}
+	seen := make(map[string]bool, len(v.SeenComments))
+	for _, key := range v.SeenComments {
+		seen[key] = true
+	}
+	return seen
+}
+
+// markNewComments flags comments that were not seen on an earlier visit
+// and records them all as seen
+func (m *Model) markNewComments(comments []*api.Comment) {
+	if m.newComments == nil {
+		m.newComments = make(map[*api.Comment]bool)
+	}
+
+	var keys []string
+	var walk func([]*api.Comment)
+	walk = func(comments []*api.Comment) {
+		for _, c := range comments {
+			if c.More == nil && c.Key != "" {
+				keys = append(keys, c.Key)
+				if m.seenComments != nil && !m.seenComments[c.Key] {
+					m.newComments[c] = true
+				}
+			}
+			walk(c.Children)
+		}
+	}
+	walk(comments)
+
+	if m.history == nil {
+		return
+	}
+	if err := m.history.MarkCommentsViewed(m.commentSource.Spec(), m.currentItem, keys); err != nil {
+		m.statusMsg = "failed to save history: " + err.Error()
+	}
+}
+
+// refreshShown rebuilds the list of stories shown from the loaded
+// stories, keeping the cursor on the same story where possible
+func (m *Model) refreshShown() {
+	var current *api.Item
+	if m.cursor < len(m.shown) {
+		current = m.shown[m.cursor]
+	}
+
+	m.shown = make([]*api.Item, 0, len(m.stories))
+	m.mutedStories = 0
+	// Watched stories are pinned above the rest, in feed order
+	var watched []*api.Item
+	for _, story := range m.stories {
+		if story == nil || (m.hideRead && m.isRead(story)) || !matchesStoryFilter(story, m.storyFilter) {
+			continue
+		}
+		if m.mutedStory(story) != "" {
+			m.mutedStories++
+			if !m.showMuted {
+				continue
+			}
+		}
+		if m.isWatched(story) {
+			watched = append(watched, story)
+			continue
+		}
+		m.shown = append(m.shown, story)
+	}
+	m.shown = append(watched, m.shown...)
+
+	m.cursor = min(m.cursor, max(len(m.shown)-1, 0))
+	for i, story := range m.shown {
+		if story == current {
+			m.cursor = i
+			break
+		}
+	}
+	m.adjustOffset()
+}
+
+// toggleHideRead shows or hides stories that have already been read
+func (m Model) toggleHideRead() (tea.Model, tea.Cmd) {
+	if m.view != StoriesView {
+		return m, nil
+	}
+	m.hideRead = !m.hideRead
+	m.refreshShown()
+	return m.maybeLoadNextBatch()
+}
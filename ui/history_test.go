@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newStoriesModel returns a model showing stories from the HN source
+func newStoriesModel(t *testing.T, stories ...*api.Item) Model {
+	t.Helper()
+	m := NewWithSource(api.NewClient(), nil)
+	m = update(t, m, tea.WindowSizeMsg{Width: 100, Height: 40})
+	return update(t, m, storiesLoadedMsg{stories: stories})
+}
+
+func TestHideReadStories(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	stories := []*api.Item{
+		{Key: "1", Title: "First"},
+		{Key: "2", Title: "Second"},
+		{Key: "3", Title: "Third"},
+	}
+	m := newStoriesModel(t, stories...)
+	m.markRead(m.source, stories[1])
+
+	m = pressKey(t, m, "H")
+	if len(m.shown) != 2 || m.shown[0].Key != "1" || m.shown[1].Key != "3" {
+		t.Fatalf("shown after hiding read = %v, want stories 1 and 3", storyKeys(m.shown))
+	}
+	if !strings.Contains(stripAnsi(m.View()), "[unread only]") {
+		t.Error("status bar does not indicate read stories are hidden")
+	}
+
+	m = pressKey(t, m, "down")
+	m = pressKey(t, m, "H")
+	if len(m.shown) != 3 {
+		t.Fatalf("shown after showing read = %v, want all stories", storyKeys(m.shown))
+	}
+	if got := m.currentStory().Key; got != "3" {
+		t.Errorf("cursor on story %s after toggling, want 3", got)
+	}
+}
+
+func TestNewCommentsSinceLastView(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	story := &api.Item{Key: "1", Title: "Busy thread", Descendants: 5}
+	m := newStoriesModel(t, story)
+	m = pressKey(t, m, "c")
+	m = update(t, m, commentsLoadedMsg{comments: []*api.Comment{testComment("alice", 0)}})
+	m = pressKey(t, m, "b")
+
+	// The thread has grown by the next refresh
+	m.resetForNewFeed()
+	m = update(t, m, storiesLoadedMsg{stories: []*api.Item{{Key: "1", Title: "Busy thread", Descendants: 17}}})
+	if view := stripAnsi(m.View()); !strings.Contains(view, "+12 new comments") {
+		t.Errorf("story meta missing new comment count:\n%s", view)
+	}
+}
+
+func storyKeys(stories []*api.Item) []string {
+	var keys []string
+	for _, s := range stories {
+		keys = append(keys, s.Key)
+	}
+	return keys
+}
+
+func TestNewCommentsHighlightedOnRevisit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	story := &api.Item{Key: "1", Title: "Busy thread", Descendants: 2}
+	m := newStoriesModel(t, story)
+
+	m = pressKey(t, m, "c")
+	m = update(t, m, commentsLoadedMsg{comments: []*api.Comment{testComment("alice", 0), testComment("bob", 0)}})
+	if len(m.newComments) != 0 {
+		t.Fatalf("first visit flagged %d comments as new, want 0", len(m.newComments))
+	}
+	m = pressKey(t, m, "b")
+
+	m = pressKey(t, m, "c")
+	m = update(t, m, commentsLoadedMsg{comments: []*api.Comment{
+		testComment("alice", 0, testComment("carol", 1)),
+		testComment("bob", 0),
+		testComment("dave", 0),
+	}})
+
+	var flagged []string
+	for _, line := range m.commentLines {
+		if line := stripAnsi(line); strings.Contains(line, "NEW") {
+			flagged = append(flagged, strings.Fields(line)[1])
+		}
+	}
+	if got := strings.Join(flagged, ","); got != "carol,dave" {
+		t.Errorf("comments with NEW badge = %s, want carol,dave", got)
+	}
+
+	for _, want := range []string{"carol", "dave", "carol"} {
+		m = pressKey(t, m, "u")
+		if got := cursorAuthor(m); got != want {
+			t.Errorf("u moved cursor to %q, want %q", got, want)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package ui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 // KeyMap defines all keybindings
 type KeyMap struct {
@@ -9,7 +15,10 @@ type KeyMap struct {
 	Enter        key.Binding
 	Back         key.Binding
 	Comments     key.Binding
+	Reader       key.Binding
 	Open         key.Binding
+	Links        key.Binding
+	Export       key.Binding
 	NextTab      key.Binding
 	PrevTab      key.Binding
 	Refresh      key.Binding
@@ -23,6 +32,17 @@ type KeyMap struct {
 	SwitchSource key.Binding
 	Visual       key.Binding
 	Yank         key.Binding
+	Collapse     key.Binding
+	CollapseAll  key.Binding
+	Bookmark     key.Binding
+	Saved        key.Binding
+	HideRead     key.Binding
+	Mute         key.Binding
+	ShowMuted    key.Binding
+	NextNew      key.Binding
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -48,10 +68,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("c"),
 			key.WithHelp("c", "comments"),
 		),
+		Reader: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "read article"),
+		),
 		Open: key.NewBinding(
 			key.WithKeys("o"),
 			key.WithHelp("o", "open in browser"),
 		),
+		Links: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "links in comment/thread"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export thread"),
+		),
 		NextTab: key.NewBinding(
 			key.WithKeys("tab", "l"),
 			key.WithHelp("tab/l", "next feed"),
@@ -104,6 +136,50 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("y"),
 			key.WithHelp("y", "yank selection"),
 		),
+		Collapse: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "collapse thread"),
+		),
+		CollapseAll: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "collapse all threads"),
+		),
+		Bookmark: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "save/unsave story"),
+		),
+		Saved: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "saved stories"),
+		),
+		HideRead: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "hide/show read"),
+		),
+		Mute: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "mute domain/author"),
+		),
+		ShowMuted: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "show/hide muted"),
+		),
+		NextNew: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "next new comment"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter stories/search comments"),
+		),
+		NextMatch: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		PrevMatch: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
 	}
 }
 
@@ -116,8 +192,147 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown, k.Home, k.End},
-		{k.Enter, k.Open, k.Comments, k.Back},
+		{k.Enter, k.Open, k.Links, k.Comments, k.Reader, k.Back},
+		{k.Collapse, k.CollapseAll, k.NextNew},
 		{k.NextTab, k.PrevTab, k.Refresh, k.SwitchSource},
+		{k.Search, k.NextMatch, k.PrevMatch},
+		{k.Bookmark, k.Saved, k.HideRead, k.Mute, k.ShowMuted, k.Export},
 		{k.Visual, k.Yank, k.ToggleMouse, k.Help, k.Quit},
 	}
 }
+
+// actions maps the action names used in the config file to bindings
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":            &k.Up,
+		"down":          &k.Down,
+		"enter":         &k.Enter,
+		"back":          &k.Back,
+		"comments":      &k.Comments,
+		"reader":        &k.Reader,
+		"open":          &k.Open,
+		"links":         &k.Links,
+		"export":        &k.Export,
+		"next_tab":      &k.NextTab,
+		"prev_tab":      &k.PrevTab,
+		"refresh":       &k.Refresh,
+		"help":          &k.Help,
+		"quit":          &k.Quit,
+		"page_down":     &k.PageDown,
+		"page_up":       &k.PageUp,
+		"home":          &k.Home,
+		"end":           &k.End,
+		"toggle_mouse":  &k.ToggleMouse,
+		"switch_source": &k.SwitchSource,
+		"visual":        &k.Visual,
+		"yank":          &k.Yank,
+		"collapse":      &k.Collapse,
+		"collapse_all":  &k.CollapseAll,
+		"bookmark":      &k.Bookmark,
+		"saved":         &k.Saved,
+		"hide_read":     &k.HideRead,
+		"mute":          &k.Mute,
+		"show_muted":    &k.ShowMuted,
+		"next_new":      &k.NextNew,
+		"search":        &k.Search,
+		"next_match":    &k.NextMatch,
+		"prev_match":    &k.PrevMatch,
+	}
+}
+
+// Apply rebinds actions to the given keys, keeping each action's help
+// description, and returns a description of every override that could
+// not be applied, including keys left bound to two actions
+func (k *KeyMap) Apply(overrides map[string][]string) []string {
+	actions := k.actions()
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range sortedKeys(overrides) {
+		binding, ok := actions[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("keys.%s: unknown action (valid actions: %s)", name, strings.Join(names, ", ")))
+			continue
+		}
+		if len(overrides[name]) == 0 {
+			continue
+		}
+		keys := make([]string, len(overrides[name]))
+		for i, k := range overrides[name] {
+			if k == "space" {
+				k = " "
+			}
+			keys[i] = k
+		}
+		*binding = key.NewBinding(
+			key.WithKeys(keys...),
+			key.WithHelp(helpKeys(keys), binding.Help().Desc),
+		)
+	}
+
+	boundTo := make(map[string]string)
+	for _, name := range names {
+		for _, k := range actions[name].Keys() {
+			if other, ok := boundTo[k]; ok {
+				problems = append(problems, fmt.Sprintf("keys: %q is bound to both %s and %s", helpKeys([]string{k}), other, name))
+				continue
+			}
+			boundTo[k] = name
+		}
+	}
+	return problems
+}
+
+// helpKeys formats keys for the help view
+func helpKeys(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shortKey returns the first key bound to an action as shown in hints,
+// e.g. "↑" or "space"
+func shortKey(b key.Binding) string {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return ""
+	}
+	switch keys[0] {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case " ":
+		return "space"
+	}
+	return keys[0]
+}
+
+// keyHint describes an action for the status bar as its first key and
+// desc, e.g. "c:comments", so hints follow rebound keys
+func keyHint(b key.Binding, desc string) string {
+	return shortKey(b) + ":" + desc
+}
+
+// joinHints lays out status bar hints
+func joinHints(hints ...string) string {
+	return strings.Join(hints, "  ") + " "
+}
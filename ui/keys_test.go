@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestDefaultKeyMapHasNoConflicts(t *testing.T) {
+	keys := DefaultKeyMap()
+	if problems := keys.Apply(nil); len(problems) > 0 {
+		t.Errorf("default keymap problems: %v", problems)
+	}
+}
+
+func TestKeyMapApply(t *testing.T) {
+	keys := DefaultKeyMap()
+	problems := keys.Apply(map[string][]string{
+		"quit":     {"Q"},
+		"collapse": {"space", "z"},
+	})
+	if len(problems) > 0 {
+		t.Fatalf("Apply problems: %v", problems)
+	}
+
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Q")}, keys.Quit) {
+		t.Error("Q does not quit")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}, keys.Quit) {
+		t.Error("q still quits after rebinding")
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, keys.Collapse) {
+		t.Error("space does not collapse")
+	}
+	if got := keys.Collapse.Help(); got.Key != "space/z" || got.Desc != "collapse thread" {
+		t.Errorf("collapse help = %+v", got)
+	}
+}
+
+func TestStatusBarShowsReboundKeys(t *testing.T) {
+	opts := DefaultOptions()
+	if problems := opts.Keys.Apply(map[string][]string{
+		"comments": {"x"},
+		"quit":     {"Q"},
+	}); len(problems) > 0 {
+		t.Fatalf("Apply problems: %v", problems)
+	}
+	m := NewWithOptions(newFakeSource(nil), nil, opts)
+	m = update(t, m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = update(t, m, storiesLoadedMsg{stories: []*api.Item{{Key: "1", Title: "A story"}}})
+
+	_, right := m.statusBarContent()
+	if !strings.Contains(right, "x:comments") || !strings.Contains(right, "Q:quit") {
+		t.Errorf("status bar doesn't show rebound keys: %q", right)
+	}
+	if strings.Contains(right, "c:comments") || strings.Contains(right, "q:quit") {
+		t.Errorf("status bar still shows default keys: %q", right)
+	}
+}
+
+func TestKeyMapApplyReportsProblems(t *testing.T) {
+	keys := DefaultKeyMap()
+	problems := keys.Apply(map[string][]string{
+		"teleport": {"t"},
+		"next_tab": {"n"},
+	})
+	got := strings.Join(problems, "\n")
+	for _, want := range []string{
+		"keys.teleport: unknown action",
+		`"n" is bound to both next_match and next_tab`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("problems missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOptionsBatchSizeAndFavorites(t *testing.T) {
+	opts := DefaultOptions()
+	opts.BatchSize = 2
+	opts.Favorites = []string{"r/golang"}
+	m := NewWithOptions(newFakeSource(nil), nil, opts)
+
+	_, cmd := m.Update(storyIDsLoadedMsg{ids: []string{"1", "2", "3", "4", "5"}})
+	msg := cmd().(storiesLoadedMsg)
+	if len(msg.stories) != 2 {
+		t.Errorf("first batch = %d stories, want 2", len(msg.stories))
+	}
+
+	options := m.sourceOptions()
+	if options[0].label != "★ r/golang" || len(options) != len(defaultSourceOptions)+1 {
+		t.Errorf("source options start with %q (%d options)", options[0].label, len(options))
+	}
+}
@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// pickedLink is a link offered by the link picker
+type pickedLink struct {
+	markup.Link
+	// By is the author of the comment the link is in, if any
+	By string
+}
+
+// openLinkPicker lists the links in the comment under the cursor, or in
+// the whole thread when the comment has none
+func (m Model) openLinkPicker() (tea.Model, tea.Cmd) {
+	if m.view != CommentsView || m.visualMode || m.currentItem == nil {
+		return m, nil
+	}
+	m.links = nil
+	if span, ok := m.cursorSpan(); ok {
+		m.links = commentLinks(span.comment, nil)
+	}
+	m.linksThread = len(m.links) == 0
+	if m.linksThread {
+		m.links = m.threadLinks()
+	}
+	if len(m.links) == 0 {
+		m.statusMsg = "no links in this thread"
+		return m, nil
+	}
+	m.pickingLink = true
+	m.linkCursor = 0
+	m.linkOffset = 0
+	m.linkInput = ""
+	return m, nil
+}
+
+func (m Model) handleLinkPickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && msg.Runes[0] >= '0' && msg.Runes[0] <= '9' {
+		m.selectLinkNumber(msg.Runes[0])
+		return m, nil
+	}
+	m.linkInput = ""
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.moveLinkCursor(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.moveLinkCursor(1)
+	case key.Matches(msg, m.keys.Home):
+		m.moveLinkCursor(-len(m.links))
+	case key.Matches(msg, m.keys.End):
+		m.moveLinkCursor(len(m.links))
+	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Open):
+		link := m.links[m.linkCursor]
+		if err := openURL(link.URL); err != nil {
+			m.statusMsg = "failed to open link: " + err.Error()
+		}
+		m.pickingLink = false
+	case key.Matches(msg, m.keys.Yank):
+		link := m.links[m.linkCursor]
+		if err := writeClipboard(link.URL); err != nil {
+			m.statusMsg = "failed to copy link: " + err.Error()
+		} else {
+			m.statusMsg = "copied " + link.URL
+		}
+		m.pickingLink = false
+	case key.Matches(msg, m.keys.NextTab), key.Matches(msg, m.keys.PrevTab):
+		m.toggleLinkScope()
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Links), key.Matches(msg, m.keys.Quit):
+		m.pickingLink = false
+	}
+	return m, nil
+}
+
+// selectLinkNumber moves the cursor to the link numbered by the digits
+// typed so far, starting over when the number gets too large
+func (m *Model) selectLinkNumber(digit rune) {
+	m.linkInput += string(digit)
+	n, _ := strconv.Atoi(m.linkInput)
+	if n < 1 || n > len(m.links) {
+		m.linkInput = string(digit)
+		n, _ = strconv.Atoi(m.linkInput)
+	}
+	if n >= 1 && n <= len(m.links) {
+		m.moveLinkCursor(n - 1 - m.linkCursor)
+	}
+}
+
+func (m *Model) moveLinkCursor(delta int) {
+	m.linkCursor = max(0, min(m.linkCursor+delta, len(m.links)-1))
+
+	visibleCount := m.visibleLinkCount()
+	if m.linkCursor < m.linkOffset {
+		m.linkOffset = m.linkCursor
+	} else if m.linkCursor >= m.linkOffset+visibleCount {
+		m.linkOffset = m.linkCursor - visibleCount + 1
+	}
+}
+
+// toggleLinkScope switches between the links in the comment under the
+// cursor and those in the whole thread
+func (m *Model) toggleLinkScope() {
+	if m.linksThread {
+		span, ok := m.cursorSpan()
+		if !ok {
+			return
+		}
+		links := commentLinks(span.comment, nil)
+		if len(links) == 0 {
+			m.statusMsg = "no links in this comment"
+			return
+		}
+		m.links = links
+	} else {
+		m.links = m.threadLinks()
+	}
+	m.linksThread = !m.linksThread
+	m.linkCursor = 0
+	m.linkOffset = 0
+}
+
+// threadLinks returns the story's link and the links in its text and
+// every comment, each once
+func (m Model) threadLinks() []pickedLink {
+	var links []pickedLink
+	seen := make(map[string]bool)
+	add := func(found []pickedLink) {
+		for _, link := range found {
+			if !seen[link.URL] {
+				seen[link.URL] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	story := m.currentItem
+	if story.URL != "" {
+		add([]pickedLink{{Link: markup.Link{URL: story.URL, Text: story.Title}, By: story.By}})
+	}
+	for _, link := range markup.Links(textBlocks(story)) {
+		add([]pickedLink{{Link: link, By: story.By}})
+	}
+
+	var walk func(comments []*api.Comment)
+	walk = func(comments []*api.Comment) {
+		for _, c := range comments {
+			add(commentLinks(c, nil))
+			walk(c.Children)
+		}
+	}
+	walk(m.comments)
+	return links
+}
+
+// commentLinks appends the links in a comment's text to links
+func commentLinks(c *api.Comment, links []pickedLink) []pickedLink {
+	if c.More != nil {
+		return links
+	}
+	for _, link := range markup.Links(textBlocks(c.Item)) {
+		links = append(links, pickedLink{Link: link, By: c.By})
+	}
+	return links
+}
+
+// visibleLinkCount returns how many links fit on screen below the
+// picker's title
+func (m Model) visibleLinkCount() int {
+	return max(m.visibleStoryCount()-2, 1)
+}
+
+func (m Model) renderLinkPicker() string {
+	var b strings.Builder
+	title := "Links in this comment (tab: whole thread)"
+	if m.linksThread {
+		title = "Links in this thread (tab: this comment)"
+	}
+	b.WriteString(m.styles().Meta.Render("\n  "+title) + "\n\n")
+
+	end := min(m.linkOffset+m.visibleLinkCount(), len(m.links))
+	for i := m.linkOffset; i < end; i++ {
+		link := m.links[i]
+		selected := i == m.linkCursor
+
+		text := link.Text
+		if text == "" {
+			text = link.URL
+		}
+		text = truncate(text, m.width-20)
+		b.WriteString(m.renderStoryNumber(i, selected))
+		if selected {
+			b.WriteString(m.styles().SelectedTitle.Render(text))
+		} else {
+			b.WriteString(m.styles().Title.Render(text))
+		}
+		if u, err := url.Parse(link.URL); err == nil && u.Host != "" {
+			b.WriteString(" " + m.styles().URL.Render(fmt.Sprintf("(%s)", strings.TrimPrefix(u.Host, "www."))))
+		}
+		b.WriteString("\n")
+
+		meta := "      " + truncate(link.URL, m.width-20)
+		if link.By != "" {
+			meta += " | " + link.By
+		}
+		b.WriteString(m.styles().Meta.Render(meta) + "\n")
+	}
+	return b.String()
+}
+
+// truncate shortens s to at most width characters, marking the cut
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width < 4 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-3]) + "..."
+}
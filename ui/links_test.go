@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newLinksModel returns a comments model whose story and comments link
+// to pages, stubbing out the browser and clipboard
+func newLinksModel(t *testing.T) (Model, *[]string) {
+	t.Helper()
+	var opened []string
+	prevOpen, prevWrite := openURL, writeClipboard
+	openURL = func(u string) error {
+		opened = append(opened, u)
+		return nil
+	}
+	writeClipboard = func(string) error { return errors.New("no clipboard") }
+	t.Cleanup(func() { openURL, writeClipboard = prevOpen, prevWrite })
+
+	alice := testComment("alice", 0)
+	alice.Text = `See <a href="https://go.dev/doc">the docs</a> and <a href="https://go.dev/blog">the blog</a>`
+	bob := testComment("bob", 0)
+	bob.Text = "Also [the docs](https://go.dev/doc) and https://pkg.go.dev"
+	bob.Format = api.FormatMarkdown
+	carol := testComment("carol", 0)
+
+	m := newCommentsModelWith(t, api.NewClient(), []*api.Comment{alice, bob, carol})
+	m.currentItem.URL = "https://example.com/story"
+	return m, &opened
+}
+
+func TestLinkPickerOpensCommentLinks(t *testing.T) {
+	m, opened := newLinksModel(t)
+
+	m = pressKey(t, m, "f")
+	if !m.pickingLink || m.linksThread {
+		t.Fatalf("picker open = %v, thread = %v; want comment links", m.pickingLink, m.linksThread)
+	}
+	if got := linkURLs(m); got != "https://go.dev/doc https://go.dev/blog" {
+		t.Errorf("links = %s", got)
+	}
+	view := stripAnsi(m.View())
+	if !strings.Contains(view, "2. the blog (go.dev)") || !strings.Contains(view, "https://go.dev/blog | alice") {
+		t.Errorf("picker view:\n%s", view)
+	}
+
+	m = pressKey(t, m, "2")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.pickingLink {
+		t.Error("picker still open after opening a link")
+	}
+	if strings.Join(*opened, " ") != "https://go.dev/blog" {
+		t.Errorf("opened %v, want the second link", *opened)
+	}
+}
+
+func TestLinkPickerThreadLinks(t *testing.T) {
+	m, _ := newLinksModel(t)
+
+	m = pressKey(t, m, "f")
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.linksThread {
+		t.Fatal("tab did not switch to the thread's links")
+	}
+	want := "https://example.com/story https://go.dev/doc https://go.dev/blog https://pkg.go.dev"
+	if got := linkURLs(m); got != want {
+		t.Errorf("thread links = %s, want %s", got, want)
+	}
+
+	m = pressKey(t, m, "esc")
+	if m.pickingLink {
+		t.Error("esc did not close the picker")
+	}
+
+	// carol's comment has no links, so the picker starts with the thread's
+	m = pressKey(t, m, "down")
+	m = pressKey(t, m, "down")
+	m = pressKey(t, m, "f")
+	if !m.linksThread || len(m.links) != 4 {
+		t.Errorf("thread = %v with %d links, want the thread's 4", m.linksThread, len(m.links))
+	}
+}
+
+func TestLinkPickerYankReportsErrors(t *testing.T) {
+	m, _ := newLinksModel(t)
+
+	m = pressKey(t, m, "f")
+	m = pressKey(t, m, "y")
+	if m.pickingLink {
+		t.Error("picker still open after yanking")
+	}
+	if !strings.Contains(m.statusMsg, "failed to copy link: no clipboard") {
+		t.Errorf("statusMsg = %q", m.statusMsg)
+	}
+}
+
+func TestLinkPickerNumbers(t *testing.T) {
+	m, _ := newLinksModel(t)
+	m.links = make([]pickedLink, 12)
+	m.pickingLink = true
+
+	for _, tt := range []struct {
+		key  string
+		want int
+	}{
+		{"1", 0},
+		{"2", 11}, // 12
+		{"5", 4},  // 125 is out of range, so 5
+		{"0", 4},  // 50 is out of range and 0 is no link
+	} {
+		m = pressKey(t, m, tt.key)
+		if m.linkCursor != tt.want {
+			t.Errorf("after %q cursor = %d, want %d", tt.key, m.linkCursor, tt.want)
+		}
+	}
+}
+
+func linkURLs(m Model) string {
+	var urls []string
+	for _, link := range m.links {
+		urls = append(urls, link.URL)
+	}
+	return strings.Join(urls, " ")
+}
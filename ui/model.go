@@ -9,6 +9,9 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+	"github.com/JonathanWThom/feedme/mute"
+	"github.com/JonathanWThom/feedme/store"
 )
 
 // View represents the current view
@@ -18,6 +21,8 @@ const (
 	StoriesView View = iota
 	CommentsView
 	SourcePickerView
+	SavedView
+	ReaderView
 )
 
 // Messages
@@ -31,8 +36,21 @@ type commentsLoadedMsg struct {
 	err      error
 }
 
+type moreCommentsLoadedMsg struct {
+	story    *api.Item
+	more     *api.Comment
+	comments []*api.Comment
+	err      error
+}
+
+type articleLoadedMsg struct {
+	story *api.Item
+	doc   *markup.Document
+	err   error
+}
+
 type storyIDsLoadedMsg struct {
-	ids []int
+	ids []string
 	err error
 }
 
@@ -40,19 +58,63 @@ type updateCheckMsg struct {
 	info *api.UpdateInfo
 }
 
+// Options configures a Model beyond its source
+type Options struct {
+	// Keys are the keybindings
+	Keys KeyMap
+	// Feed is the index of the feed shown first
+	Feed int
+	// Favorites are source specs listed first in the source picker
+	Favorites []string
+	// BatchSize is how many stories are loaded at a time
+	BatchSize int
+	// Theme is the theme to render with
+	Theme *Theme
+	// SourceAccent colors the theme's accent after the active source
+	SourceAccent bool
+	// Mute holds the mute rules from the config file; rules added in the
+	// app are loaded from the store
+	Mute mute.Set
+	// Watch are keyword patterns for stories to highlight and pin to the
+	// top of the list
+	Watch []string
+	// HNComments is how sources opened in the app fetch HN comment trees
+	HNComments api.CommentLoader
+}
+
+// DefaultOptions returns the options used by NewWithSource
+func DefaultOptions() Options {
+	return Options{
+		Keys:       DefaultKeyMap(),
+		BatchSize:  30,
+		Theme:      DefaultTheme(),
+		HNComments: api.CommentLoaderFirebase,
+	}
+}
+
 // Model is the main application model
 type Model struct {
-	source   api.Source
-	keys     KeyMap
-	help     help.Model
-	spinner  spinner.Model
-	viewport viewport.Model
+	source    api.Source
+	keys      KeyMap
+	favorites []string
+	batchSize int
+	// hnComments is how sources opened in the app fetch HN comment trees
+	hnComments api.CommentLoader
+
+	// theme is the base theme; sourceThemes are its variants accented for
+	// each kind of source, when enabled
+	theme        *Theme
+	sourceThemes map[string]*Theme
+	help         help.Model
+	spinner      spinner.Model
+	viewport     viewport.Model
 
 	// State
 	view         View
 	feed         int
-	storyIDs     []int
+	storyIDs     []string
 	stories      []*api.Item
+	shown        []*api.Item // stories after hiding read ones and filtering
 	comments     []*api.Comment
 	cursor       int
 	offset       int
@@ -63,11 +125,85 @@ type Model struct {
 	width        int
 	height       int
 	currentItem  *api.Item
+	// commentSource is the source comments are loaded from, which differs
+	// from source when reading a saved story from another source
+	commentSource api.Source
+	prevView      View
+	statusMsg     string
+
+	// Comment cursor and collapsed threads
+	commentSpans  []commentSpan
+	commentCursor int
+	collapsed     map[*api.Comment]bool
+	loadingMore   *api.Comment
+
+	// Link picker
+	pickingLink bool
+	links       []pickedLink
+	linksThread bool // links are from the whole thread, not one comment
+	linkCursor  int
+	linkOffset  int
+	linkInput   string // number typed so far
+
+	// Export prompt
+	choosingExport bool
+
+	// Mute rules from the config file and added in the app, compiled
+	// into muteFilter, and the mute prompt. Muted stories are hidden and
+	// muted comments collapsed unless showMuted is set.
+	muteConfig    mute.Set
+	mutes         *store.Mutes
+	muteFilter    *mute.Filter
+	choosingMute  bool
+	showMuted     bool
+	mutedStories  int // muted stories among the loaded ones
+	mutedComments int // muted comments in the open thread
+
+	// Watchlist of stories to highlight and pin
+	watch mute.Keywords
+
+	// Comment search
+	commentQuery   string
+	commentMatches []commentMatch
+	matchIndex     int
+
+	// Comments seen on the previous visit to the story, and the comments
+	// loaded since that were not among them
+	seenComments map[string]bool
+	newComments  map[*api.Comment]bool
 
 	// Source picker state
 	sourcePickerCursor int
-	subredditInput     string
-	editingSubreddit   bool
+	pickerInput        string
+	editingInput       bool
+
+	// Search prompt and story filter
+	searchInput   string
+	editingSearch bool
+	storyFilter   string
+	searchingMore bool // loading batches to find more filter matches
+	// searchAfter is the last story loaded when searching more began;
+	// matches loaded after it are new
+	searchAfter *api.Item
+
+	// Read history
+	history  *store.History
+	hideRead bool
+
+	// Saved stories
+	bookmarks    *store.Bookmarks
+	saved        []store.Bookmark
+	savedCursor  int
+	savedOffset  int
+	savedSources map[string]api.Source
+
+	// Article reader
+	reader       viewport.Model
+	readerPrev   View
+	readerSource api.Source
+	articleStory *api.Item
+	article      *markup.Document
+	articleErr   error
 
 	// Visual mode state
 	visualMode   bool
@@ -87,25 +223,67 @@ func New() Model {
 
 // NewWithSource creates a new Model with a specific source
 func NewWithSource(source api.Source, updateChan <-chan *api.UpdateInfo) Model {
+	return NewWithOptions(source, updateChan, DefaultOptions())
+}
+
+// NewWithOptions creates a new Model with a specific source and options
+func NewWithOptions(source api.Source, updateChan <-chan *api.UpdateInfo, opts Options) Model {
+	theme := opts.Theme
+	if theme == nil {
+		theme = DefaultTheme()
+	}
+	var sourceThemes map[string]*Theme
+	if opts.SourceAccent {
+		sourceThemes = accentThemes(theme)
+	}
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = SpinnerStyle
+	s.Style = theme.Spinner
 
 	h := help.New()
-	h.Styles.ShortKey = HelpStyle
-	h.Styles.ShortDesc = HelpStyle
+	h.Styles.ShortKey = theme.Help
+	h.Styles.ShortDesc = theme.Help
 
-	return Model{
+	// Report every store that could not be opened
+	var problems []string
+	bookmarks, err := store.OpenBookmarks()
+	if err != nil {
+		problems = append(problems, "bookmarks unavailable: "+err.Error())
+	}
+	history, err := store.OpenHistory()
+	if err != nil {
+		problems = append(problems, "read history unavailable: "+err.Error())
+	}
+	mutes, err := store.OpenMutes()
+	if err != nil {
+		problems = append(problems, "mutes unavailable: "+err.Error())
+	}
+
+	m := Model{
 		source:       source,
-		keys:         DefaultKeyMap(),
+		keys:         opts.Keys,
+		favorites:    opts.Favorites,
+		hnComments:   opts.HNComments,
+		batchSize:    max(opts.BatchSize, 1),
+		theme:        theme,
+		sourceThemes: sourceThemes,
 		help:         h,
 		spinner:      s,
 		view:         StoriesView,
-		feed:         0,
+		feed:         opts.Feed,
 		loading:      true,
 		mouseEnabled: true,
 		updateChan:   updateChan,
+		bookmarks:    bookmarks,
+		history:      history,
+		muteConfig:   opts.Mute,
+		watch:        mute.CompileKeywords(opts.Watch),
+		mutes:        mutes,
+		statusMsg:    strings.Join(problems, "; "),
 	}
+	m.compileMutes()
+	return m
 }
 
 // Init initializes the model
@@ -136,7 +314,7 @@ func (m Model) loadStoryIDs() tea.Cmd {
 	}
 }
 
-func (m Model) loadStories(ids []int) tea.Cmd {
+func (m Model) loadStories(ids []string) tea.Cmd {
 	return func() tea.Msg {
 		stories, err := m.source.FetchItems(ids)
 		return storiesLoadedMsg{stories: stories, err: err}
@@ -145,17 +323,36 @@ func (m Model) loadStories(ids []int) tea.Cmd {
 
 func (m Model) loadComments(item *api.Item) tea.Cmd {
 	return func() tea.Msg {
-		comments, err := m.source.FetchCommentTree(item, 0)
+		comments, err := m.commentSource.FetchCommentTree(item, 0)
 		return commentsLoadedMsg{comments: comments, err: err}
 	}
 }
 
-// resetForNewSource resets state when switching sources
+// loadMoreComments expands a placeholder comment when the source
+// supports it
+func (m Model) loadMoreComments(more *api.Comment) tea.Cmd {
+	loader, ok := m.commentSource.(api.MoreCommentsLoader)
+	if !ok {
+		return nil
+	}
+	story := m.currentItem
+	return func() tea.Msg {
+		comments, err := loader.LoadMoreComments(story, more)
+		return moreCommentsLoadedMsg{story: story, more: more, comments: comments, err: err}
+	}
+}
+
+// resetForNewSource sets up the source switched to and resets state
 func (m *Model) resetForNewSource() {
+	api.SetCommentLoader(m.source, m.hnComments)
 	m.view = StoriesView
 	m.feed = 0
 	m.stories = nil
+	m.shown = nil
 	m.storyIDs = nil
+	m.storyFilter = ""
+	m.searchInput = ""
+	m.searchingMore = false
 	m.cursor = 0
 	m.offset = 0
 	m.err = nil
@@ -165,7 +362,11 @@ func (m *Model) resetForNewSource() {
 // resetForNewFeed resets state when switching feeds
 func (m *Model) resetForNewFeed() {
 	m.stories = nil
+	m.shown = nil
 	m.storyIDs = nil
+	m.storyFilter = ""
+	m.searchInput = ""
+	m.searchingMore = false
 	m.cursor = 0
 	m.offset = 0
 	m.err = nil
@@ -187,7 +388,7 @@ func (m Model) View() string {
 	if m.loading {
 		fmt.Fprintf(&b, "\n  %s Loading...\n", m.spinner.View())
 	} else if m.err != nil {
-		b.WriteString(ErrorStyle.Render(fmt.Sprintf("\n  Error: %v\n", m.err)))
+		b.WriteString(m.styles().Error.Render(fmt.Sprintf("\n  Error: %v\n", m.err)))
 	} else if m.showHelp {
 		b.WriteString(m.renderFullHelp())
 	} else {
@@ -195,9 +396,17 @@ func (m Model) View() string {
 		case StoriesView:
 			b.WriteString(m.renderStories())
 		case CommentsView:
-			b.WriteString(m.viewport.View())
+			if m.pickingLink {
+				b.WriteString(m.renderLinkPicker())
+			} else {
+				b.WriteString(m.viewport.View())
+			}
 		case SourcePickerView:
 			b.WriteString(m.renderSourcePicker())
+		case SavedView:
+			b.WriteString(m.renderSaved())
+		case ReaderView:
+			b.WriteString(m.reader.View())
 		}
 	}
 
@@ -206,4 +415,3 @@ func (m Model) View() string {
 
 	return b.String()
 }
-
@@ -1,37 +1,22 @@
 package ui
 
-import "testing"
+import (
+	"os"
+	"testing"
+)
 
-func TestCleanHTML(t *testing.T) {
-	tests := []struct {
-		name string
-		html string
-		want string
-	}{
-		{"plain text", "hello world", "hello world"},
-		{"paragraph tags", "first<p>second", "first\n\nsecond"},
-		{"br tags", "line1<br>line2", "line1\nline2"},
-		{"self-closing br", "line1<br/>line2", "line1\nline2"},
-		{"br with space", "line1<br />line2", "line1\nline2"},
-		{
-			"link extraction",
-			`click <a href="https://example.com">here</a> now`,
-			"click here [https://example.com] now",
-		},
-		{"html entities", "foo &amp; bar &lt; baz", "foo & bar < baz"},
-		{"strip unknown tags", "hello <b>bold</b> world", "hello bold world"},
-		{"leading/trailing whitespace", "  hello  ", "hello"},
-		{"empty string", "", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := cleanHTML(tt.html)
-			if got != tt.want {
-				t.Errorf("cleanHTML(%q) = %q, want %q", tt.html, got, tt.want)
-			}
-		})
+// TestMain points the config directory at a temporary directory so tests
+// never read or write the user's bookmarks and other state
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "feedme-ui-test")
+	if err != nil {
+		panic(err)
 	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
 }
 
 func TestWrapTextLines(t *testing.T) {
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/mute"
+)
+
+// muteTarget is something the mute prompt offers to mute
+type muteTarget struct {
+	key   string // key that picks it in the prompt
+	kind  string // domain or author
+	value string
+}
+
+// compileMutes rebuilds the mute filter from the config and app rules
+func (m *Model) compileMutes() {
+	sets := []mute.Set{m.muteConfig}
+	if m.mutes != nil {
+		sets = append(sets, m.mutes.Set())
+	}
+	m.muteFilter = mute.Compile(sets...)
+}
+
+// mutedStory returns why a story from the current source is muted, or ""
+func (m Model) mutedStory(story *api.Item) string {
+	return m.muteFilter.Story(m.source.Spec(), story)
+}
+
+// mutedComment returns why a comment in the open thread is muted, or ""
+func (m Model) mutedComment(c *api.Comment) string {
+	return m.muteFilter.Comment(m.activeSource().Spec(), c)
+}
+
+// muteTargets returns what can be muted from the story or comment under
+// the cursor: the story's domain and author, or the comment's author
+func (m Model) muteTargets() []muteTarget {
+	var targets []muteTarget
+	switch m.view {
+	case StoriesView:
+		story := m.currentStory()
+		if story == nil {
+			return nil
+		}
+		if domain := story.Domain(); domain != "" {
+			targets = append(targets, muteTarget{"d", "domain", domain})
+		}
+		if story.By != "" {
+			targets = append(targets, muteTarget{"a", "author", story.By})
+		}
+	case CommentsView:
+		span, ok := m.cursorSpan()
+		if !ok || m.visualMode || span.comment.More != nil || span.comment.By == "" {
+			return nil
+		}
+		targets = append(targets, muteTarget{"a", "author", span.comment.By})
+	}
+	return targets
+}
+
+// startMute asks what to mute from the story or comment under the cursor
+func (m Model) startMute() (tea.Model, tea.Cmd) {
+	if m.loading || len(m.muteTargets()) == 0 {
+		return m, nil
+	}
+	m.choosingMute = true
+	return m, nil
+}
+
+// mutePrompt lists the mute prompt's choices for the status bar
+func (m Model) mutePrompt() string {
+	var choices []string
+	for _, t := range m.muteTargets() {
+		verb := t.kind
+		if m.mutes != nil && m.mutes.Has(m.muteSpec(t), m.muteRules(t)) {
+			verb = "unmute " + t.kind
+		}
+		choices = append(choices, t.key+":"+verb+" "+t.value)
+	}
+	return strings.Join(append(choices, "esc:cancel "), "  ")
+}
+
+// muteSpec returns the source a target is muted for: domains link to the
+// same sites everywhere, but usernames belong to one source
+func (m Model) muteSpec(t muteTarget) string {
+	if t.kind == "author" {
+		return m.activeSource().Spec()
+	}
+	return ""
+}
+
+func (m Model) muteRules(t muteTarget) mute.Rules {
+	if t.kind == "author" {
+		return mute.Rules{Authors: []string{t.value}}
+	}
+	return mute.Rules{Domains: []string{t.value}}
+}
+
+// handleMuteInput mutes or unmutes the chosen target; any other key
+// cancels
+func (m Model) handleMuteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.choosingMute = false
+	var target muteTarget
+	for _, t := range m.muteTargets() {
+		if t.key == msg.String() {
+			target = t
+		}
+	}
+	if target.key == "" {
+		return m, nil
+	}
+	if m.mutes == nil {
+		m.statusMsg = "mutes unavailable"
+		return m, nil
+	}
+
+	muted, err := m.mutes.Toggle(m.muteSpec(target), m.muteRules(target))
+	if err != nil {
+		m.statusMsg = "failed to save mutes: " + err.Error()
+		return m, nil
+	}
+	m.compileMutes()
+	if muted {
+		m.statusMsg = "muted " + target.kind + " " + target.value
+	} else {
+		m.statusMsg = "unmuted " + target.kind + " " + target.value
+	}
+
+	if m.view == CommentsView {
+		if !muted {
+			m.expandAuthor(target.value)
+		}
+		m.collapseMuted(m.comments)
+		m.rebuildComments()
+		m.scrollToCursor()
+		return m, nil
+	}
+	m.refreshShown()
+	return m.maybeLoadNextBatch()
+}
+
+// toggleShowMuted reveals or hides muted stories, or expands or collapses
+// muted comments
+func (m Model) toggleShowMuted() (tea.Model, tea.Cmd) {
+	switch m.view {
+	case StoriesView:
+		m.showMuted = !m.showMuted
+		m.refreshShown()
+		return m.maybeLoadNextBatch()
+	case CommentsView:
+		if m.visualMode {
+			return m, nil
+		}
+		m.showMuted = !m.showMuted
+		m.collapseMuted(m.comments)
+		m.rebuildComments()
+		m.scrollToCursor()
+	}
+	return m, nil
+}
+
+// collapseMuted collapses the muted comments among comments and their
+// replies, or expands them when muted comments are shown
+func (m *Model) collapseMuted(comments []*api.Comment) {
+	walkComments(comments, func(c *api.Comment) {
+		if m.mutedComment(c) == "" {
+			return
+		}
+		if m.showMuted {
+			delete(m.collapsed, c)
+		} else {
+			m.collapsed[c] = true
+		}
+	})
+}
+
+// countMutedComments returns the number of muted comments in the thread
+func (m Model) countMutedComments() int {
+	var n int
+	walkComments(m.comments, func(c *api.Comment) {
+		if m.mutedComment(c) != "" {
+			n++
+		}
+	})
+	return n
+}
+
+// expandAuthor expands the comments by an author
+func (m *Model) expandAuthor(author string) {
+	walkComments(m.comments, func(c *api.Comment) {
+		if c.Item != nil && strings.EqualFold(c.By, author) {
+			delete(m.collapsed, c)
+		}
+	})
+}
+
+// walkComments calls fn for every comment in a tree, parents first
+func walkComments(comments []*api.Comment, fn func(*api.Comment)) {
+	for _, c := range comments {
+		fn(c)
+		walkComments(c.Children, fn)
+	}
+}
+
+// mutedStatus describes how many loaded stories or comments are muted
+func (m Model) mutedStatus(n int) string {
+	switch {
+	case n == 0:
+		return ""
+	case m.showMuted:
+		return fmt.Sprintf(" [%d muted shown]", n)
+	}
+	return fmt.Sprintf(" [%d muted]", n)
+}
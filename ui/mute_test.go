@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/mute"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+func newMutedStoriesModel(t *testing.T, rules mute.Set, stories ...*api.Item) Model {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.Mute = rules
+	m := NewWithOptions(api.NewClient(), nil, opts)
+	m = update(t, m, tea.WindowSizeMsg{Width: 100, Height: 40})
+	return update(t, m, storiesLoadedMsg{stories: stories})
+}
+
+func TestMutedStoriesHidden(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := newMutedStoriesModel(t, mute.Set{Rules: mute.Rules{Keywords: []string{"/^ask hn/"}, Domains: []string{"example.com"}}},
+		&api.Item{Key: "1", Title: "Ask HN: Anything"},
+		&api.Item{Key: "2", Title: "Kept", URL: "https://go.dev"},
+		&api.Item{Key: "3", Title: "Spam", URL: "https://www.example.com/x"},
+	)
+	if got := storyKeys(m.shown); strings.Join(got, ",") != "2" {
+		t.Fatalf("shown = %v, want only story 2", got)
+	}
+	if view := stripAnsi(m.View()); !strings.Contains(view, "[2 muted]") {
+		t.Errorf("status bar does not count muted stories:\n%s", view)
+	}
+
+	m = pressKey(t, m, "X")
+	if len(m.shown) != 3 {
+		t.Fatalf("shown after revealing = %v, want all stories", storyKeys(m.shown))
+	}
+	view := stripAnsi(m.View())
+	for _, want := range []string{"[2 muted shown]", "[muted keyword /^ask hn/]", "[muted domain example.com]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestMuteStoryDomainAndAuthor(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := newMutedStoriesModel(t, mute.Set{},
+		&api.Item{Key: "1", Title: "First", By: "troll", URL: "https://blog.example.com/a"},
+		&api.Item{Key: "2", Title: "Second", By: "alice", URL: "https://example.com/b"},
+		&api.Item{Key: "3", Title: "Third", By: "troll"},
+	)
+
+	m = pressKey(t, m, "M")
+	if !m.choosingMute {
+		t.Fatal("M did not open the mute prompt")
+	}
+	if view := stripAnsi(m.View()); !strings.Contains(view, "d:domain blog.example.com  a:author troll") {
+		t.Errorf("status bar does not offer the story's domain and author:\n%s", view)
+	}
+	m = pressKey(t, m, "a")
+	if got := strings.Join(storyKeys(m.shown), ","); got != "2" || m.statusMsg != "muted author troll" {
+		t.Fatalf("shown = %s, statusMsg = %q after muting troll", got, m.statusMsg)
+	}
+
+	// Mutes are saved, authors for the source only
+	mutes, err := store.OpenMutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := mutes.Set(); len(set.Authors) != 0 || strings.Join(set.Sources["hn"].Authors, ",") != "troll" {
+		t.Errorf("saved mutes = %+v", set)
+	}
+
+	// Revealed stories can be unmuted from the prompt
+	m = pressKey(t, m, "X")
+	m = pressKey(t, m, "up")
+	m = pressKey(t, m, "M")
+	if view := stripAnsi(m.View()); !strings.Contains(view, "a:unmute author troll") {
+		t.Errorf("prompt does not offer to unmute:\n%s", view)
+	}
+	m = pressKey(t, m, "a")
+	m = pressKey(t, m, "X")
+	if len(m.shown) != 3 || m.mutedStories != 0 {
+		t.Fatalf("shown = %v with %d muted after unmuting", storyKeys(m.shown), m.mutedStories)
+	}
+
+	m = pressKey(t, m, "M")
+	m = pressKey(t, m, "esc")
+	if m.choosingMute || len(m.shown) != 3 {
+		t.Errorf("esc left prompt open = %v, shown = %v", m.choosingMute, storyKeys(m.shown))
+	}
+}
+
+func TestMutedCommentsCollapsed(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	troll := testComment("troll", 0, testComment("alice", 1))
+	opts := DefaultOptions()
+	opts.Mute = mute.Set{Sources: map[string]mute.Rules{"hn": {Authors: []string{"troll"}}}}
+	m := NewWithOptions(api.NewClient(), nil, opts)
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	m.view = CommentsView
+	m.currentItem = &api.Item{Title: "A story", Descendants: 3}
+	m.commentSource = m.source
+	m = update(t, m, commentsLoadedMsg{comments: []*api.Comment{testComment("bob", 0), troll}})
+
+	if !m.collapsed[troll] || m.collapsed[troll.Children[0]] {
+		t.Fatalf("collapsed = %v, want only troll's comment", m.collapsed)
+	}
+	view := stripAnsi(m.View())
+	for _, want := range []string{"troll", "[muted] [+1 hidden]", "[1 muted]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(t, m, "X")
+	if m.collapsed[troll] {
+		t.Error("X did not expand the muted comment")
+	}
+	m = pressKey(t, m, "X")
+	if !m.collapsed[troll] {
+		t.Error("X did not collapse the muted comment again")
+	}
+
+	// Muting bob from the comment under the cursor collapses his comment
+	m = pressKey(t, m, "M")
+	m = pressKey(t, m, "a")
+	if m.statusMsg != "muted author bob" || !m.collapsed[m.comments[0]] || m.mutedComments != 2 {
+		t.Errorf("statusMsg = %q, collapsed = %v, %d muted", m.statusMsg, m.collapsed, m.mutedComments)
+	}
+}
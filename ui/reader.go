@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// readerMaxWidth caps the width of article text for readability
+const readerMaxWidth = 100
+
+// openReader fetches the current story's link and shows it in the reader
+func (m Model) openReader() (tea.Model, tea.Cmd) {
+	if m.view != StoriesView && m.view != CommentsView {
+		return m, nil
+	}
+	story := m.currentStory()
+	if story == nil {
+		return m, nil
+	}
+	if story.URL == "" {
+		m.statusMsg = "no article to read"
+		return m, nil
+	}
+
+	m.readerPrev = m.view
+	m.readerSource = m.activeSource()
+	m.articleStory = story
+	m.article = nil
+	m.articleErr = nil
+	m.view = ReaderView
+	m.loading = true
+	m.markRead(m.readerSource, story)
+	return m, tea.Batch(m.spinner.Tick, loadArticle(story))
+}
+
+func loadArticle(story *api.Item) tea.Cmd {
+	return func() tea.Msg {
+		doc, err := api.FetchArticle(story.URL)
+		return articleLoadedMsg{story: story, doc: doc, err: err}
+	}
+}
+
+// handleReaderInput handles keyboard input in the reader view
+func (m Model) handleReaderInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Up):
+		m.reader.LineUp(1)
+	case key.Matches(msg, m.keys.Down):
+		m.reader.LineDown(1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.reader.HalfViewUp()
+	case key.Matches(msg, m.keys.PageDown):
+		m.reader.HalfViewDown()
+	case key.Matches(msg, m.keys.Home):
+		m.reader.GotoTop()
+	case key.Matches(msg, m.keys.End):
+		m.reader.GotoBottom()
+	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Open):
+		_ = openURL(m.articleStory.URL)
+	case key.Matches(msg, m.keys.Comments):
+		return m.readerComments()
+	case key.Matches(msg, m.keys.Bookmark):
+		m.toggleBookmark()
+	case key.Matches(msg, m.keys.ToggleMouse):
+		return m.toggleMouse()
+	case key.Matches(msg, m.keys.Back):
+		m.view = m.readerPrev
+		m.loading = false
+		m.article = nil
+		m.articleErr = nil
+	}
+	return m, nil
+}
+
+// readerComments switches from the article to its comments, returning to
+// them if the article was opened from the comments
+func (m Model) readerComments() (tea.Model, tea.Cmd) {
+	if m.readerPrev == CommentsView {
+		m.view = CommentsView
+		m.loading = false
+		return m, nil
+	}
+	return m.showComments(m.articleStory, m.readerSource)
+}
+
+// renderArticle lays out the article, or the error loading it, in the
+// reader viewport
+func (m *Model) renderArticle() {
+	width := min(m.width-4, readerMaxWidth)
+	styles := m.styles()
+	story := m.articleStory
+
+	var lines []string
+	title := story.Title
+	if m.article != nil && m.article.Title != "" {
+		title = m.article.Title
+	}
+	for _, line := range wrapTextLines(title, width) {
+		lines = append(lines, styles.SelectedTitle.Render(line))
+	}
+	meta := story.Domain()
+	if m.article != nil && m.article.Byline != "" {
+		meta = m.article.Byline + " · " + meta
+	}
+	lines = append(lines, styles.Meta.Render(meta), "")
+
+	if m.articleErr != nil {
+		lines = append(lines,
+			styles.Error.Render(fmt.Sprintf("Couldn't load the article: %v", m.articleErr)),
+			"",
+			styles.Meta.Render(fmt.Sprintf("Press %s to open it in the browser or %s for comments.",
+				shortKey(m.keys.Open), shortKey(m.keys.Comments))))
+	} else if m.article != nil {
+		lines = append(lines, markup.Render(m.article.Blocks, width, styles.markup())...)
+	}
+
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	m.reader.SetContent(strings.Join(lines, "\n"))
+}
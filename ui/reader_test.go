@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const testArticle = `<html><head><title>Bike lanes approved</title></head><body>
+<nav><a href="/">Home</a></nav>
+<article>
+<p>The city council voted on Tuesday to approve a network of protected bike lanes downtown.</p>
+<h2>What happens next</h2>
+<p>Construction begins in the spring, according to <a href="/plans">the plans</a>.</p>
+</article></body></html>`
+
+func newArticleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/article" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testArticle))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReaderShowsArticle(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	server := newArticleServer(t)
+	story := &api.Item{Key: "1", Title: "Council approves bike lanes", URL: server.URL + "/article", Descendants: 3}
+	m := newStoriesModel(t, story)
+
+	m = pressKey(t, m, "a")
+	if m.view != ReaderView || !m.loading {
+		t.Fatalf("view = %v, loading = %v after a; want loading reader", m.view, m.loading)
+	}
+	if !m.isRead(story) {
+		t.Error("story not marked read when opening the article")
+	}
+
+	m = update(t, m, loadArticle(story)())
+	view := stripAnsi(m.reader.View())
+	for _, want := range []string{"Bike lanes approved", "The city council voted", "What happens next", "the plans[1]"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("reader missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Home") {
+		t.Errorf("reader shows navigation:\n%s", view)
+	}
+
+	m = pressKey(t, m, "c")
+	if m.view != CommentsView || m.prevView != ReaderView || m.currentItem != story {
+		t.Fatalf("after c: view = %v, prevView = %v; want comments over the reader", m.view, m.prevView)
+	}
+	m = update(t, m, commentsLoadedMsg{})
+	m = pressKey(t, m, "b")
+	if m.view != ReaderView {
+		t.Fatalf("back from comments went to %v, want the reader", m.view)
+	}
+	m = pressKey(t, m, "b")
+	if m.view != StoriesView {
+		t.Errorf("back from reader went to %v, want stories", m.view)
+	}
+}
+
+func TestReaderFromCommentsReturnsToThem(t *testing.T) {
+	m := newCommentsModel(t)
+	m.currentItem.URL = "https://example.com/article"
+	m = pressKey(t, m, "down")
+
+	m = pressKey(t, m, "a")
+	if m.view != ReaderView || m.readerPrev != CommentsView {
+		t.Fatalf("view = %v after a, want the reader", m.view)
+	}
+	m, cmd := updateCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if m.view != CommentsView || cmd != nil {
+		t.Fatalf("c from the reader: view = %v, cmd = %v; want the loaded comments", m.view, cmd)
+	}
+	if got := cursorAuthor(m); got != "bob" {
+		t.Errorf("comment cursor = %q after returning, want it kept on %q", got, "bob")
+	}
+}
+
+func TestReaderShowsErrors(t *testing.T) {
+	server := newArticleServer(t)
+	story := &api.Item{Key: "1", Title: "Gone", URL: server.URL + "/missing"}
+	m := newStoriesModel(t, story)
+
+	m = pressKey(t, m, "a")
+	m = update(t, m, loadArticle(story)())
+	if view := stripAnsi(m.reader.View()); !strings.Contains(view, "Couldn't load the article: HTTP 404") {
+		t.Errorf("reader does not show the error:\n%s", view)
+	}
+	if m.err != nil {
+		t.Errorf("article error replaced the whole view: %v", m.err)
+	}
+}
+
+func TestReaderNeedsALink(t *testing.T) {
+	m := newStoriesModel(t, &api.Item{Key: "1", Title: "Ask HN: Anything?"})
+	m = pressKey(t, m, "a")
+	if m.view != StoriesView || m.statusMsg != "no article to read" {
+		t.Errorf("view = %v, status = %q; want to stay on stories with a message", m.view, m.statusMsg)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/markup"
 )
 
 // visibleStoryCount returns how many stories fit on screen
@@ -19,19 +20,22 @@ func (m Model) visibleStoryCount() int {
 }
 
 func (m Model) renderHeader() string {
-	title := HeaderStyle.Render(" " + m.source.Name() + " ")
+	title := m.styles().Header.Render(" " + m.activeSource().Name() + " ")
 
-	if m.view == CommentsView {
+	if m.view == CommentsView || m.view == ReaderView {
 		return title
 	}
+	if m.view == SavedView {
+		return m.styles().Header.Render(" Saved ")
+	}
 
 	var tabs []string
 	feedLabels := m.source.FeedLabels()
 	for i, label := range feedLabels {
 		if i == m.feed {
-			tabs = append(tabs, ActiveTabStyle.Render(label))
+			tabs = append(tabs, m.styles().ActiveTab.Render(label))
 		} else {
-			tabs = append(tabs, TabStyle.Render(label))
+			tabs = append(tabs, m.styles().Tab.Render(label))
 		}
 	}
 
@@ -40,20 +44,27 @@ func (m Model) renderHeader() string {
 }
 
 func (m Model) renderStories() string {
-	if len(m.stories) == 0 {
+	if len(m.shown) == 0 {
+		if m.storyFilter != "" && len(m.stories) > 0 {
+			return fmt.Sprintf("\n  No loaded stories match (press %s to search the rest, %s to clear)\n",
+				shortKey(m.keys.NextMatch), shortKey(m.keys.Back))
+		}
+		if m.hideRead && len(m.stories) > 0 {
+			return fmt.Sprintf("\n  No unread stories (press %s to show read stories)\n", shortKey(m.keys.HideRead))
+		}
+		if m.mutedStories > 0 {
+			return fmt.Sprintf("\n  Every loaded story is muted (press %s to show them)\n", shortKey(m.keys.ShowMuted))
+		}
 		return "\n  No stories to display\n"
 	}
 
 	var b strings.Builder
 	visibleCount := m.visibleStoryCount()
 	start := m.offset
-	end := min(start+visibleCount, len(m.stories))
+	end := min(start+visibleCount, len(m.shown))
 
 	for i := start; i < end; i++ {
-		story := m.stories[i]
-		if story == nil {
-			continue
-		}
+		story := m.shown[i]
 
 		isSelected := i == m.cursor
 		b.WriteString(m.renderStory(i, story, isSelected))
@@ -64,11 +75,22 @@ func (m Model) renderStories() string {
 
 func (m Model) renderStory(idx int, story *api.Item, selected bool) string {
 	var b strings.Builder
+	visit, read := m.visit(story)
+	muted := m.mutedStory(story)
 	b.WriteString(m.renderStoryNumber(idx, selected))
-	b.WriteString(m.renderStoryTitle(story, selected))
-	b.WriteString(renderStoryDomain(story))
+	b.WriteString(m.renderStoryTitle(story, selected, read || muted != ""))
+	b.WriteString(m.renderStoryDomain(story))
+	if m.isBookmarked(story) {
+		b.WriteString(" " + m.styles().Bookmark.Render("★"))
+	}
 	b.WriteString("\n")
-	b.WriteString(MetaStyle.Render(storyMeta(story)))
+	b.WriteString(m.styles().Meta.Render(storyMeta(story)))
+	if n := visit.NewComments(story); n > 0 {
+		b.WriteString(" " + m.styles().NewComments.Render(fmt.Sprintf("+%d new comments", n)))
+	}
+	if muted != "" {
+		b.WriteString(" " + m.styles().Meta.Render("[muted "+muted+"]"))
+	}
 	b.WriteString("\n")
 	return b.String()
 }
@@ -76,25 +98,30 @@ func (m Model) renderStory(idx int, story *api.Item, selected bool) string {
 func (m Model) renderStoryNumber(idx int, selected bool) string {
 	num := fmt.Sprintf("%3d. ", idx+1)
 	if selected {
-		return ScoreStyle.Render(num)
+		return m.styles().Score.Render(num)
 	}
-	return MetaStyle.Render(num)
+	return m.styles().Meta.Render(num)
 }
 
-func (m Model) renderStoryTitle(story *api.Item, selected bool) string {
+func (m Model) renderStoryTitle(story *api.Item, selected, read bool) string {
 	title := story.Title
 	if len(title) > m.width-20 {
 		title = title[:m.width-23] + "..."
 	}
-	if selected {
-		return SelectedTitleStyle.Render(title)
+	switch {
+	case selected:
+		return m.styles().SelectedTitle.Render(title)
+	case m.isWatched(story):
+		return m.styles().WatchedTitle.Render(title)
+	case read:
+		return m.styles().ReadTitle.Render(title)
 	}
-	return TitleStyle.Render(title)
+	return m.styles().Title.Render(title)
 }
 
-func renderStoryDomain(story *api.Item) string {
+func (m Model) renderStoryDomain(story *api.Item) string {
 	if domain := story.Domain(); domain != "" {
-		return " " + URLStyle.Render(fmt.Sprintf("(%s)", domain))
+		return " " + m.styles().URL.Render(fmt.Sprintf("(%s)", domain))
 	}
 	return ""
 }
@@ -102,95 +129,147 @@ func renderStoryDomain(story *api.Item) string {
 func storyMeta(story *api.Item) string {
 	meta := fmt.Sprintf("      %d points by %s %s | %d comments",
 		story.Score, story.By, story.TimeAgo(), story.Descendants)
-	if story.Text != "" && strings.HasPrefix(story.Text, "[") {
-		meta += " " + story.Text
+	if len(story.Tags) > 0 {
+		meta += " [" + strings.Join(story.Tags, ", ") + "]"
 	}
 	return meta
 }
 
-func (m Model) renderComments() string {
-	if m.currentItem == nil {
-		return ""
-	}
-
-	var b strings.Builder
-	b.WriteString(m.renderCommentHeader())
-	b.WriteString(MetaStyle.Render(fmt.Sprintf("─── %d comments ───", m.currentItem.Descendants)))
-	b.WriteString("\n\n")
-	for _, comment := range m.comments {
-		b.WriteString(m.renderComment(comment))
-	}
-	return b.String()
-}
-
 func (m Model) renderCommentHeader() string {
 	var b strings.Builder
-	b.WriteString(SelectedTitleStyle.Render(m.currentItem.Title) + "\n")
-	b.WriteString(renderStoryDomain(m.currentItem))
+	b.WriteString(m.styles().SelectedTitle.Render(m.currentItem.Title) + "\n")
+	b.WriteString(m.renderStoryDomain(m.currentItem))
 	if domain := m.currentItem.Domain(); domain != "" {
 		b.WriteString("\n")
 	}
 	meta := fmt.Sprintf("%d points by %s %s",
 		m.currentItem.Score, m.currentItem.By, m.currentItem.TimeAgo())
-	b.WriteString(MetaStyle.Render(meta) + "\n\n")
+	b.WriteString(m.styles().Meta.Render(meta) + "\n\n")
 	if m.currentItem.Text != "" {
-		text := cleanHTML(m.currentItem.Text)
-		b.WriteString(CommentTextStyle.Render(wrapText(text, m.width-4)) + "\n\n")
+		b.WriteString(strings.Join(m.renderText(m.currentItem, m.width-4), "\n") + "\n\n")
 	}
 	return b.String()
 }
 
-func (m Model) renderComment(c *api.Comment) string {
-	var b strings.Builder
-
-	indent := strings.Repeat("  ", c.Depth)
-	prefix := IndentStyle(c.Depth).Render("│ ")
-
-	author := CommentAuthorStyle.Render(c.By)
-	time := CommentMetaStyle.Render(c.TimeAgo())
-	b.WriteString(indent + prefix + author + " " + time + "\n")
-
-	text := cleanHTML(c.Text)
-	lines := wrapTextLines(text, m.width-len(indent)-4)
-	for _, line := range lines {
-		b.WriteString(indent + prefix + CommentTextStyle.Render(line) + "\n")
+// renderText lays out an item's text in its format as styled lines at
+// most width columns wide
+func (m Model) renderText(item *api.Item, width int) []string {
+	if item.Format == api.FormatPlain {
+		lines := wrapTextLines(item.Text, width)
+		for i, line := range lines {
+			lines[i] = m.styles().CommentText.Render(line)
+		}
+		return lines
 	}
-	b.WriteString(indent + prefix + "\n")
+	return markup.Render(textBlocks(item), width, m.styles().markup())
+}
 
-	for _, child := range c.Children {
-		b.WriteString(m.renderComment(child))
+// textBlocks parses an item's text in its format. Plain text has no
+// blocks.
+func textBlocks(item *api.Item) []markup.Block {
+	switch item.Format {
+	case api.FormatMarkdown:
+		return markup.FromMarkdown(item.Text)
+	case api.FormatPlain:
+		return nil
 	}
+	return markup.FromHTMLFragment(item.Text)
+}
 
-	return b.String()
+func (m Model) renderCommentByline(c *api.Comment) string {
+	author := c.By
+	if author == "" {
+		author = "[deleted]"
+	}
+	byline := m.styles().CommentAuthor.Render(author)
+	if c.OP {
+		byline += " " + m.styles().OPBadge.Render("OP")
+	}
+	// Placeholders for missing comments have no time
+	if c.Time != 0 {
+		byline += " " + m.styles().CommentMeta.Render(c.TimeAgo())
+	}
+	if m.mutedComment(c) != "" {
+		byline += " " + m.styles().CommentMeta.Render("[muted]")
+	}
+	return byline
 }
 
 func (m Model) renderStatusBar() string {
 	left, right := m.statusBarContent()
 	gap := max(0, m.width-lipgloss.Width(left)-lipgloss.Width(right))
-	return StatusBarStyle.Width(m.width).Render(left + strings.Repeat(" ", gap) + right)
+	return m.styles().StatusBar.Width(m.width).Render(left + strings.Repeat(" ", gap) + right)
 }
 
 func (m Model) statusBarContent() (string, string) {
 	suffix := m.statusBarSuffix()
 	switch m.view {
 	case StoriesView:
-		return fmt.Sprintf(" %d/%d stories%s", m.cursor+1, len(m.stories), suffix),
-			"↑↓:nav  enter:open  c:comments  tab:feed  s:source  ?:help  q:quit "
+		if m.editingSearch {
+			return " /" + m.searchInput + "_", "enter:apply  esc:clear "
+		}
+		if m.choosingMute {
+			return " Mute" + suffix, m.mutePrompt()
+		}
+		left := fmt.Sprintf(" %d/%d stories%s%s", min(m.cursor+1, len(m.shown)), len(m.shown), m.storiesFilterStatus(), suffix)
+		if m.storyFilter != "" {
+			return left, joinHints(m.matchHint(), keyHint(m.keys.Back, "clear filter"), keyHint(m.keys.Help, "help"))
+		}
+		return left, joinHints(m.navHint("nav"), keyHint(m.keys.Enter, "open"), keyHint(m.keys.Comments, "comments"),
+			keyHint(m.keys.NextTab, "feed"), keyHint(m.keys.SwitchSource, "source"), keyHint(m.keys.Help, "help"),
+			keyHint(m.keys.Quit, "quit"))
 	case CommentsView:
+		if m.pickingLink {
+			return fmt.Sprintf(" link %d/%d%s", m.linkCursor+1, len(m.links), suffix),
+				joinHints(shortKey(m.keys.Up)+shortKey(m.keys.Down)+"/1-9:select", keyHint(m.keys.Enter, "open"),
+					keyHint(m.keys.Yank, "yank"), keyHint(m.keys.NextTab, "comment/thread"), keyHint(m.keys.Back, "close"))
+		}
+		if m.choosingExport {
+			return " Export thread as" + suffix, exportHint()
+		}
+		if m.choosingMute {
+			return " Mute" + suffix, m.mutePrompt()
+		}
 		return m.commentsStatusLeft(suffix),
 			m.commentsStatusRight()
 	case SourcePickerView:
 		return " Select a source",
-			"↑↓:nav  enter:select  esc:cancel  q:quit "
+			joinHints(m.navHint("nav"), "enter:select", "esc:cancel", keyHint(m.keys.Quit, "quit"))
+	case ReaderView:
+		return fmt.Sprintf(" article %.0f%%%s", m.reader.ScrollPercent()*100, suffix),
+			joinHints(m.navHint("scroll"), keyHint(m.keys.Open, "browser"), keyHint(m.keys.Comments, "comments"),
+				keyHint(m.keys.Back, "back"), keyHint(m.keys.Help, "help"))
+	case SavedView:
+		return fmt.Sprintf(" %d/%d saved%s", min(m.savedCursor+1, len(m.saved)), len(m.saved), suffix),
+			joinHints(m.navHint("nav"), keyHint(m.keys.Enter, "open"), keyHint(m.keys.Comments, "comments"),
+				keyHint(m.keys.Bookmark, "remove"), keyHint(m.keys.Back, "back"), keyHint(m.keys.Quit, "quit"))
 	}
 	return "", ""
 }
 
+// storiesFilterStatus describes filters that hide stories
+func (m Model) storiesFilterStatus() string {
+	var s string
+	if m.storyFilter != "" {
+		s += " [filter: " + m.storyFilter + "]"
+	}
+	if m.searchingMore {
+		s += " [searching...]"
+	}
+	if m.hideRead {
+		s += " [unread only]"
+	}
+	return s + m.mutedStatus(m.mutedStories)
+}
+
 func (m Model) statusBarSuffix() string {
 	var s string
 	if !m.mouseEnabled {
 		s += " [SELECT MODE - m to exit]"
 	}
+	if m.statusMsg != "" {
+		s += " [" + m.statusMsg + "]"
+	}
 	if m.updateInfo != nil && m.updateInfo.HasUpdate() {
 		s += " [" + m.updateInfo.FormatUpdateMessage() + "]"
 	}
@@ -198,17 +277,57 @@ func (m Model) statusBarSuffix() string {
 }
 
 func (m Model) commentsStatusLeft(suffix string) string {
+	if m.editingSearch {
+		return " /" + m.searchInput + "_"
+	}
 	if m.visualMode {
 		return fmt.Sprintf(" -- VISUAL -- lines %d-%d%s", m.visualStart+1, m.visualEnd+1, suffix)
 	}
-	return fmt.Sprintf(" %d comments%s", len(m.comments), suffix)
+	if len(m.commentSpans) == 0 {
+		return fmt.Sprintf(" %d comments%s", len(m.comments), suffix)
+	}
+	var fresh string
+	if n := len(m.newComments); n > 0 {
+		fresh = fmt.Sprintf(" (%d new)", n)
+	}
+	fresh += m.mutedStatus(m.mutedComments)
+	return fmt.Sprintf(" comment %d/%d%s%s", m.commentCursor+1, len(m.commentSpans), fresh, suffix)
 }
 
 func (m Model) commentsStatusRight() string {
+	if m.editingSearch {
+		return m.matchStatus() + "enter:done  esc:clear "
+	}
 	if m.visualMode {
-		return "↑↓:select  y:yank  esc:cancel "
+		return m.matchStatus() + joinHints(m.navHint("select"), keyHint(m.keys.Yank, "yank"), keyHint(m.keys.Back, "cancel"))
+	}
+	if m.commentQuery != "" {
+		return m.matchStatus() + joinHints(m.matchHint(), keyHint(m.keys.Back, "clear search"))
+	}
+	return joinHints(m.navHint("comments"), keyHint(m.keys.Collapse, "collapse"), keyHint(m.keys.CollapseAll, "collapse all"),
+		keyHint(m.keys.NextNew, "next new"), keyHint(m.keys.Links, "links"), keyHint(m.keys.Export, "export"),
+		keyHint(m.keys.Visual, "visual"), keyHint(m.keys.Back, "back"), keyHint(m.keys.Help, "help"))
+}
+
+// navHint describes the up and down keys, e.g. "↑↓:nav"
+func (m Model) navHint(desc string) string {
+	return shortKey(m.keys.Up) + shortKey(m.keys.Down) + ":" + desc
+}
+
+// matchHint describes the keys that move between search matches
+func (m Model) matchHint() string {
+	return shortKey(m.keys.NextMatch) + "/" + shortKey(m.keys.PrevMatch) + ":next/prev match"
+}
+
+// matchStatus reports the position of the current comment search match
+func (m Model) matchStatus() string {
+	switch {
+	case m.commentQuery == "":
+		return ""
+	case len(m.commentMatches) == 0:
+		return "no matches  "
 	}
-	return "↑↓:scroll  v:visual  o:open link  b:back  ?:help  q:quit "
+	return fmt.Sprintf("match %d/%d  ", m.matchIndex+1, len(m.commentMatches))
 }
 
 func (m Model) renderFullHelp() string {
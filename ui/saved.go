@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/store"
+)
+
+// toggleBookmark saves or unsaves the current story
+func (m *Model) toggleBookmark() {
+	story := m.currentStory()
+	if story == nil {
+		return
+	}
+	if m.bookmarks == nil {
+		m.statusMsg = "bookmarks unavailable"
+		return
+	}
+
+	saved, err := m.bookmarks.Toggle(m.activeSource(), story)
+	switch {
+	case err != nil:
+		m.statusMsg = "failed to save bookmarks: " + err.Error()
+	case saved:
+		m.statusMsg = "saved"
+	default:
+		m.statusMsg = "removed from saved"
+	}
+}
+
+// isBookmarked reports whether a story from the current source is saved
+func (m Model) isBookmarked(story *api.Item) bool {
+	return m.bookmarks != nil && m.bookmarks.Has(m.source.Spec(), story.Key)
+}
+
+// openSaved switches to the list of saved stories
+func (m Model) openSaved() (tea.Model, tea.Cmd) {
+	if m.view != StoriesView {
+		return m, nil
+	}
+	if m.bookmarks == nil {
+		m.statusMsg = "bookmarks unavailable"
+		return m, nil
+	}
+	m.view = SavedView
+	m.saved = m.bookmarks.List()
+	m.savedCursor = 0
+	m.savedOffset = 0
+	m.statusMsg = ""
+	return m, nil
+}
+
+// handleSavedInput handles keyboard input in the saved stories view
+func (m Model) handleSavedInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Up):
+		m.moveSavedCursor(-1)
+	case key.Matches(msg, m.keys.Down):
+		m.moveSavedCursor(1)
+	case key.Matches(msg, m.keys.Home):
+		m.moveSavedCursor(-len(m.saved))
+	case key.Matches(msg, m.keys.End):
+		m.moveSavedCursor(len(m.saved))
+	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Open):
+		m.openSavedURL()
+	case key.Matches(msg, m.keys.Comments):
+		return m.openSavedComments()
+	case key.Matches(msg, m.keys.Bookmark):
+		m.removeSaved()
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Saved):
+		m.view = StoriesView
+		m.statusMsg = ""
+	}
+	return m, nil
+}
+
+func (m *Model) moveSavedCursor(delta int) {
+	if len(m.saved) == 0 {
+		return
+	}
+	m.savedCursor = max(0, min(m.savedCursor+delta, len(m.saved)-1))
+
+	visibleCount := m.visibleStoryCount()
+	if m.savedCursor < m.savedOffset {
+		m.savedOffset = m.savedCursor
+	} else if m.savedCursor >= m.savedOffset+visibleCount {
+		m.savedOffset = m.savedCursor - visibleCount + 1
+	}
+}
+
+func (m Model) currentSaved() (store.Bookmark, bool) {
+	if m.savedCursor >= len(m.saved) {
+		return store.Bookmark{}, false
+	}
+	return m.saved[m.savedCursor], true
+}
+
+// savedSource returns the source a bookmark came from, reusing sources
+// already built for other bookmarks so their caches are shared
+func (m *Model) savedSource(bm store.Bookmark) (api.Source, error) {
+	if bm.Source == m.source.Spec() {
+		return m.source, nil
+	}
+	if source, ok := m.savedSources[bm.Source]; ok {
+		return source, nil
+	}
+	source, err := api.ParseSource(bm.Source)
+	if err != nil {
+		return nil, err
+	}
+	api.SetCommentLoader(source, m.hnComments)
+	if m.savedSources == nil {
+		m.savedSources = make(map[string]api.Source)
+	}
+	m.savedSources[bm.Source] = source
+	return source, nil
+}
+
+func (m *Model) openSavedURL() {
+	bm, ok := m.currentSaved()
+	if !ok {
+		return
+	}
+	source, err := m.savedSource(bm)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return
+	}
+	if bm.Item.URL != "" {
+		_ = openURL(bm.Item.URL)
+	} else {
+		_ = openURL(source.StoryURL(bm.Item))
+	}
+	m.markRead(source, bm.Item)
+}
+
+// openSavedComments loads a saved story's comments from its own source
+func (m Model) openSavedComments() (tea.Model, tea.Cmd) {
+	bm, ok := m.currentSaved()
+	if !ok {
+		return m, nil
+	}
+	source, err := m.savedSource(bm)
+	if err != nil {
+		m.statusMsg = err.Error()
+		return m, nil
+	}
+	m.statusMsg = ""
+	return m.showComments(bm.Item, source)
+}
+
+func (m *Model) removeSaved() {
+	bm, ok := m.currentSaved()
+	if !ok {
+		return
+	}
+	if err := m.bookmarks.Remove(bm.Source, bm.Item.Key); err != nil {
+		m.statusMsg = "failed to save bookmarks: " + err.Error()
+		return
+	}
+	m.saved = m.bookmarks.List()
+	m.moveSavedCursor(0)
+	if m.savedCursor >= len(m.saved) {
+		m.savedCursor = max(len(m.saved)-1, 0)
+	}
+	m.statusMsg = "removed from saved"
+}
+
+// renderSaved renders the saved stories list
+func (m Model) renderSaved() string {
+	if len(m.saved) == 0 {
+		return fmt.Sprintf("\n  No saved stories. Press %s on a story to save it.\n", shortKey(m.keys.Bookmark))
+	}
+
+	var b strings.Builder
+	end := min(m.savedOffset+m.visibleStoryCount(), len(m.saved))
+	for i := m.savedOffset; i < end; i++ {
+		bm := m.saved[i]
+		selected := i == m.savedCursor
+		b.WriteString(m.renderStoryNumber(i, selected))
+		b.WriteString(m.renderStoryTitle(bm.Item, selected, false))
+		b.WriteString(m.renderStoryDomain(bm.Item))
+		b.WriteString("\n")
+		meta := fmt.Sprintf("      %s | %d points by %s | saved %s",
+			bm.SourceName, bm.Item.Score, bm.Item.By, bm.SavedAt.Format("Jan 2, 2006"))
+		b.WriteString(m.styles().Meta.Render(meta))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
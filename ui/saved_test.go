@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/store"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSavedStoriesAcrossSources(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := NewWithSource(api.NewClient(), nil)
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 40})
+	m = update(t, m, storiesLoadedMsg{stories: []*api.Item{
+		{Key: "1", Title: "An HN story", Descendants: 3},
+		{Key: "2", Title: "Another HN story"},
+	}})
+
+	m = pressKey(t, m, "B")
+	if !m.isBookmarked(m.shown[0]) {
+		t.Fatal("story not bookmarked after pressing B")
+	}
+	if m.isBookmarked(m.shown[1]) {
+		t.Error("unrelated story bookmarked")
+	}
+
+	// Saved stories stay available after switching to another source
+	m.source = api.NewLobstersClient()
+	m = pressKey(t, m, "S")
+	if m.view != SavedView {
+		t.Fatalf("view = %v, want SavedView", m.view)
+	}
+	view := stripAnsi(m.View())
+	if !strings.Contains(view, "An HN story") || !strings.Contains(view, "HN |") {
+		t.Errorf("saved view missing story or its source:\n%s", view)
+	}
+
+	m, cmd := updateCmd(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd == nil || m.view != CommentsView {
+		t.Fatalf("c in saved view did not start loading comments (view %v)", m.view)
+	}
+	if m.commentSource.Spec() != "hn" {
+		t.Errorf("comments loaded from %q, want the bookmark's source hn", m.commentSource.Spec())
+	}
+
+	m = pressKey(t, m, "b")
+	if m.view != SavedView {
+		t.Errorf("back from saved comments went to %v, want SavedView", m.view)
+	}
+
+	m = pressKey(t, m, "B")
+	if len(m.saved) != 0 {
+		t.Errorf("saved list has %d entries after removing, want 0", len(m.saved))
+	}
+}
+
+func TestStoreProblemsAllReported(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{store.BookmarksFile, store.HistoryFile} {
+		path, err := store.Path(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewWithSource(api.NewClient(), nil)
+	for _, want := range []string{"bookmarks unavailable", "read history unavailable"} {
+		if !strings.Contains(m.statusMsg, want) {
+			t.Errorf("status %q missing %q", m.statusMsg, want)
+		}
+	}
+}
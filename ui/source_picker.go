@@ -8,41 +8,74 @@ import (
 	"github.com/JonathanWThom/feedme/api"
 )
 
-// Source picker options
-var sourceOptions = []string{"Hacker News", "Lobste.rs", "Reddit"}
+// sourceOption is an entry in the source picker. Options with a prompt
+// ask for input (subreddit, search query, ...) before building the source.
+type sourceOption struct {
+	label  string
+	prompt string
+	build  func(input string) api.Source
+}
+
+// Built-in source picker options, listed after the favorites
+var defaultSourceOptions = []sourceOption{
+	{label: "Hacker News", build: func(string) api.Source { return api.NewClient() }},
+	{label: "HN Search", prompt: "Search HN: ", build: func(q string) api.Source { return api.NewHNSearchSource(q) }},
+	{label: "Lobste.rs", build: func(string) api.Source { return api.NewLobstersClient() }},
+	{label: "Reddit", prompt: "Enter subreddit: r/", build: func(s string) api.Source { return api.NewRedditClient(s) }},
+}
+
+// sourceOptions returns the favorite sources followed by the built-in
+// options
+func (m Model) sourceOptions() []sourceOption {
+	options := make([]sourceOption, 0, len(m.favorites)+len(defaultSourceOptions))
+	for _, spec := range m.favorites {
+		source, err := api.ParseSource(spec)
+		if err != nil {
+			continue
+		}
+		options = append(options, sourceOption{
+			label: "★ " + spec,
+			build: func(string) api.Source { return source },
+		})
+	}
+	return append(options, defaultSourceOptions...)
+}
 
 // handleSourcePickerInput handles keyboard input in the source picker
 func (m Model) handleSourcePickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	if m.editingSubreddit {
-		return m.handleSubredditInput(msg)
+	if m.editingInput {
+		return m.handlePickerTextInput(msg)
 	}
 	return m.handleSourcePickerNav(msg)
 }
 
-func (m Model) handleSubredditInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (m Model) handlePickerTextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEnter:
-		return m.confirmSubreddit()
+		return m.confirmPickerInput()
 	case tea.KeyEsc:
-		m.editingSubreddit = false
-		m.subredditInput = ""
+		m.editingInput = false
+		m.pickerInput = ""
 	case tea.KeyBackspace:
-		if len(m.subredditInput) > 0 {
-			m.subredditInput = m.subredditInput[:len(m.subredditInput)-1]
+		if len(m.pickerInput) > 0 {
+			m.pickerInput = m.pickerInput[:len(m.pickerInput)-1]
 		}
 	case tea.KeyRunes:
-		m.subredditInput += string(msg.Runes)
+		m.pickerInput += string(msg.Runes)
+	case tea.KeySpace:
+		m.pickerInput += " "
 	}
 	return m, nil
 }
 
-func (m Model) confirmSubreddit() (tea.Model, tea.Cmd) {
-	if m.subredditInput == "" {
+func (m Model) confirmPickerInput() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(m.pickerInput)
+	if input == "" {
 		return m, nil
 	}
-	m.source = api.NewRedditClient(m.subredditInput)
+	m.source = m.sourceOptions()[m.sourcePickerCursor].build(input)
 	m.resetForNewSource()
-	m.editingSubreddit = false
+	m.editingInput = false
 	return m, tea.Batch(m.spinner.Tick, m.loadStoryIDs())
 }
 
@@ -55,7 +88,7 @@ func (m Model) handleSourcePickerNav(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.sourcePickerCursor--
 		}
 	case key.Matches(msg, m.keys.Down):
-		if m.sourcePickerCursor < len(sourceOptions)-1 {
+		if m.sourcePickerCursor < len(m.sourceOptions())-1 {
 			m.sourcePickerCursor++
 		}
 	case key.Matches(msg, m.keys.Enter):
@@ -67,16 +100,13 @@ func (m Model) handleSourcePickerNav(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) selectSource() (tea.Model, tea.Cmd) {
-	switch m.sourcePickerCursor {
-	case 0:
-		m.source = api.NewClient()
-	case 1:
-		m.source = api.NewLobstersClient()
-	case 2:
-		m.editingSubreddit = true
-		m.subredditInput = ""
+	option := m.sourceOptions()[m.sourcePickerCursor]
+	if option.prompt != "" {
+		m.editingInput = true
+		m.pickerInput = ""
 		return m, nil
 	}
+	m.source = option.build("")
 	m.resetForNewSource()
 	return m, tea.Batch(m.spinner.Tick, m.loadStoryIDs())
 }
@@ -85,7 +115,7 @@ func (m Model) selectSource() (tea.Model, tea.Cmd) {
 func (m Model) renderSourcePicker() string {
 	var b strings.Builder
 	b.WriteString("\n")
-	b.WriteString(HeaderStyle.Render(" Switch Source "))
+	b.WriteString(m.styles().Header.Render(" Switch Source "))
 	b.WriteString("\n\n")
 	b.WriteString(m.renderSourceOptions())
 	b.WriteString("\n")
@@ -96,16 +126,16 @@ func (m Model) renderSourcePicker() string {
 
 func (m Model) renderSourceOptions() string {
 	var b strings.Builder
-	for i, option := range sourceOptions {
+	for i, option := range m.sourceOptions() {
 		selected := i == m.sourcePickerCursor
 		cursor := "  "
 		if selected {
 			cursor = "> "
 		}
 		if selected {
-			b.WriteString(SelectedTitleStyle.Render(cursor + option))
+			b.WriteString(m.styles().SelectedTitle.Render(cursor + option.label))
 		} else {
-			b.WriteString(TitleStyle.Render(cursor + option))
+			b.WriteString(m.styles().Title.Render(cursor + option.label))
 		}
 		b.WriteString("\n")
 	}
@@ -113,12 +143,12 @@ func (m Model) renderSourceOptions() string {
 }
 
 func (m Model) renderSourcePickerFooter() string {
-	if !m.editingSubreddit {
-		return MetaStyle.Render("  ↑↓: navigate  Enter: select  Esc: cancel")
+	if !m.editingInput {
+		return m.styles().Meta.Render("  ↑↓: navigate  Enter: select  Esc: cancel")
 	}
-	return MetaStyle.Render("  Enter subreddit: r/") +
-		SelectedTitleStyle.Render(m.subredditInput) +
-		SelectedTitleStyle.Render("_") +
+	return m.styles().Meta.Render("  "+m.sourceOptions()[m.sourcePickerCursor].prompt) +
+		m.styles().SelectedTitle.Render(m.pickerInput) +
+		m.styles().SelectedTitle.Render("_") +
 		"\n\n" +
-		MetaStyle.Render("  Press Enter to confirm, Esc to cancel")
+		m.styles().Meta.Render("  Press Enter to confirm, Esc to cancel")
 }
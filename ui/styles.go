@@ -1,96 +1,280 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/JonathanWThom/feedme/markup"
+)
+
+// Palette is the set of colors a Theme is built from
+type Palette struct {
+	Accent      lipgloss.TerminalColor // header, selection, scores, authors
+	AccentDim   lipgloss.TerminalColor // OP badge
+	OnAccent    lipgloss.TerminalColor // text on accent backgrounds
+	Text        lipgloss.TerminalColor // story titles
+	CommentText lipgloss.TerminalColor
+	Muted       lipgloss.TerminalColor // metadata and help
+	Subtle      lipgloss.TerminalColor // domains and read stories
+	StatusBar   lipgloss.TerminalColor // status bar background
+	SelectBg    lipgloss.TerminalColor // visual mode selection
+	SelectFg    lipgloss.TerminalColor
+	Error       lipgloss.TerminalColor
+	Indent      []lipgloss.TerminalColor // thread lines, by depth
+}
 
+// Built-in palettes
 var (
-	// Colors
-	orange     = lipgloss.Color("#FF6600")
-	dimOrange  = lipgloss.Color("#CC5500")
-	subtle     = lipgloss.Color("#666666")
-	highlight  = lipgloss.Color("#FFFFFF")
-	dimText    = lipgloss.Color("#888888")
-	commentBg  = lipgloss.Color("#1a1a1a")
+	DarkPalette = Palette{
+		Accent:      lipgloss.Color("#FF6600"),
+		AccentDim:   lipgloss.Color("#CC5500"),
+		OnAccent:    lipgloss.Color("#000000"),
+		Text:        lipgloss.Color("#FFFFFF"),
+		CommentText: lipgloss.Color("#CCCCCC"),
+		Muted:       lipgloss.Color("#888888"),
+		Subtle:      lipgloss.Color("#666666"),
+		StatusBar:   lipgloss.Color("#333333"),
+		SelectBg:    lipgloss.Color("#264F78"),
+		SelectFg:    lipgloss.Color("#FFFFFF"),
+		Error:       lipgloss.Color("#FF0000"),
+		Indent: []lipgloss.TerminalColor{
+			lipgloss.Color("#FF6600"),
+			lipgloss.Color("#4A9EFF"),
+			lipgloss.Color("#50C878"),
+			lipgloss.Color("#FFD700"),
+			lipgloss.Color("#FF69B4"),
+			lipgloss.Color("#9370DB"),
+		},
+	}
 
-	// Header
-	HeaderStyle = lipgloss.NewStyle().
-			Background(orange).
-			Foreground(lipgloss.Color("#000000")).
-			Bold(true).
-			Padding(0, 1)
+	LightPalette = Palette{
+		Accent:      lipgloss.Color("#D75F00"),
+		AccentDim:   lipgloss.Color("#FFAF87"),
+		OnAccent:    lipgloss.Color("#FFFFFF"),
+		Text:        lipgloss.Color("#1A1A1A"),
+		CommentText: lipgloss.Color("#303030"),
+		Muted:       lipgloss.Color("#6C6C6C"),
+		Subtle:      lipgloss.Color("#8A8A8A"),
+		StatusBar:   lipgloss.Color("#E4E4E4"),
+		SelectBg:    lipgloss.Color("#B3D7FF"),
+		SelectFg:    lipgloss.Color("#000000"),
+		Error:       lipgloss.Color("#D70000"),
+		Indent: []lipgloss.TerminalColor{
+			lipgloss.Color("#D75F00"),
+			lipgloss.Color("#005FD7"),
+			lipgloss.Color("#008700"),
+			lipgloss.Color("#AF8700"),
+			lipgloss.Color("#D7005F"),
+			lipgloss.Color("#5F00AF"),
+		},
+	}
 
-	TabStyle = lipgloss.NewStyle().
-			Foreground(dimText).
-			Padding(0, 1)
+	HighContrastPalette = Palette{
+		Accent:      lipgloss.Color("#FFFF00"),
+		AccentDim:   lipgloss.Color("#FFFF00"),
+		OnAccent:    lipgloss.Color("#000000"),
+		Text:        lipgloss.Color("#FFFFFF"),
+		CommentText: lipgloss.Color("#FFFFFF"),
+		Muted:       lipgloss.Color("#D0D0D0"),
+		Subtle:      lipgloss.Color("#B2B2B2"),
+		StatusBar:   lipgloss.Color("#000000"),
+		SelectBg:    lipgloss.Color("#FFFF00"),
+		SelectFg:    lipgloss.Color("#000000"),
+		Error:       lipgloss.Color("#FF5F5F"),
+		Indent: []lipgloss.TerminalColor{
+			lipgloss.Color("#FFFF00"),
+			lipgloss.Color("#00FFFF"),
+			lipgloss.Color("#00FF00"),
+			lipgloss.Color("#FF00FF"),
+			lipgloss.Color("#FFFFFF"),
+			lipgloss.Color("#FF8700"),
+		},
+	}
+)
 
-	ActiveTabStyle = lipgloss.NewStyle().
-			Foreground(highlight).
-			Bold(true).
-			Padding(0, 1).
-			Underline(true)
+// Source accents, used in place of the palette accent when a theme
+// follows the active source
+var sourceAccents = map[string]lipgloss.Color{
+	"hn":       lipgloss.Color("#FF6600"),
+	"lobsters": lipgloss.Color("#AC130D"),
+	"reddit":   lipgloss.Color("#FF4500"),
+	"lemmy":    lipgloss.Color("#14854F"),
+	"rss":      lipgloss.Color("#EE802F"),
+}
+
+// Theme holds the styles the UI renders with
+type Theme struct {
+	Palette Palette
+
+	// Header
+	Header    lipgloss.Style
+	Tab       lipgloss.Style
+	ActiveTab lipgloss.Style
 
 	// Story list
-	TitleStyle = lipgloss.NewStyle().
-			Foreground(highlight).
-			Bold(true)
+	Title         lipgloss.Style
+	ReadTitle     lipgloss.Style
+	SelectedTitle lipgloss.Style
+	WatchedTitle  lipgloss.Style // titles matching the watchlist
+	URL           lipgloss.Style
+	Meta          lipgloss.Style
+	Score         lipgloss.Style
+	NewComments   lipgloss.Style
+	Bookmark      lipgloss.Style
 
-	SelectedTitleStyle = lipgloss.NewStyle().
-				Foreground(orange).
-				Bold(true)
+	// Comments
+	CommentAuthor lipgloss.Style
+	CommentText   lipgloss.Style
+	CommentMeta   lipgloss.Style
+	MoreComments  lipgloss.Style
+	CommentCursor lipgloss.Style
+	NewBadge      lipgloss.Style
+	OPBadge       lipgloss.Style
 
-	URLStyle = lipgloss.NewStyle().
-			Foreground(subtle)
+	// General
+	Help      lipgloss.Style
+	Error     lipgloss.Style
+	Spinner   lipgloss.Style
+	StatusBar lipgloss.Style
 
-	MetaStyle = lipgloss.NewStyle().
-			Foreground(dimText)
+	// Visual mode selection - bright highlight
+	VisualSelect lipgloss.Style
 
-	ScoreStyle = lipgloss.NewStyle().
-			Foreground(orange)
+	indent []lipgloss.Style
+}
 
-	// Comments
-	CommentAuthorStyle = lipgloss.NewStyle().
-				Foreground(orange).
-				Bold(true)
+// NewTheme builds a theme from a palette
+func NewTheme(p Palette) *Theme {
+	t := &Theme{
+		Palette: p,
 
-	CommentTextStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#CCCCCC"))
+		Header: lipgloss.NewStyle().
+			Background(p.Accent).
+			Foreground(p.OnAccent).
+			Bold(true).
+			Padding(0, 1),
+		Tab: lipgloss.NewStyle().
+			Foreground(p.Muted).
+			Padding(0, 1),
+		ActiveTab: lipgloss.NewStyle().
+			Foreground(p.Text).
+			Bold(true).
+			Padding(0, 1).
+			Underline(true),
 
-	CommentMetaStyle = lipgloss.NewStyle().
-				Foreground(dimText)
+		Title: lipgloss.NewStyle().
+			Foreground(p.Text).
+			Bold(true),
+		ReadTitle: lipgloss.NewStyle().
+			Foreground(p.Subtle),
+		SelectedTitle: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Bold(true),
+		WatchedTitle: lipgloss.NewStyle().
+			Background(p.AccentDim).
+			Foreground(p.OnAccent).
+			Bold(true),
+		URL: lipgloss.NewStyle().
+			Foreground(p.Subtle),
+		Meta: lipgloss.NewStyle().
+			Foreground(p.Muted),
+		Score: lipgloss.NewStyle().
+			Foreground(p.Accent),
+		NewComments: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Bold(true),
+		Bookmark: lipgloss.NewStyle().
+			Foreground(p.Accent),
 
-	// General
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(dimText)
+		CommentAuthor: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Bold(true),
+		CommentText: lipgloss.NewStyle().
+			Foreground(p.CommentText),
+		CommentMeta: lipgloss.NewStyle().
+			Foreground(p.Muted),
+		MoreComments: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Italic(true),
+		CommentCursor: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Bold(true),
+		NewBadge: lipgloss.NewStyle().
+			Foreground(p.Accent).
+			Bold(true),
+		OPBadge: lipgloss.NewStyle().
+			Background(p.AccentDim).
+			Foreground(p.OnAccent).
+			Padding(0, 1),
 
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000"))
+		Help: lipgloss.NewStyle().
+			Foreground(p.Muted),
+		Error: lipgloss.NewStyle().
+			Foreground(p.Error),
+		Spinner: lipgloss.NewStyle().
+			Foreground(p.Accent),
+		StatusBar: lipgloss.NewStyle().
+			Background(p.StatusBar).
+			Foreground(p.Muted).
+			Padding(0, 1),
+
+		VisualSelect: lipgloss.NewStyle().
+			Background(p.SelectBg).
+			Foreground(p.SelectFg),
+	}
+	for _, color := range p.Indent {
+		t.indent = append(t.indent, lipgloss.NewStyle().Foreground(color).Bold(true))
+	}
+	if len(t.indent) == 0 {
+		t.indent = []lipgloss.Style{lipgloss.NewStyle().Bold(true)}
+	}
+	return t
+}
 
-	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(orange)
+// NoColorTheme returns a theme without colors, for NO_COLOR, which marks
+// headers and selections with reverse video instead
+func NoColorTheme() *Theme {
+	none := lipgloss.NoColor{}
+	t := NewTheme(Palette{
+		Accent: none, AccentDim: none, OnAccent: none, Text: none,
+		CommentText: none, Muted: none, Subtle: none, StatusBar: none,
+		SelectBg: none, SelectFg: none, Error: none,
+	})
+	t.Header = t.Header.Reverse(true)
+	t.OPBadge = t.OPBadge.Reverse(true)
+	t.WatchedTitle = t.WatchedTitle.Reverse(true)
+	t.StatusBar = t.StatusBar.Reverse(true)
+	t.VisualSelect = t.VisualSelect.Reverse(true)
+	t.SelectedTitle = t.SelectedTitle.Underline(true)
+	return t
+}
 
-	// Status bar
-	StatusBarStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("#333333")).
-			Foreground(dimText).
-			Padding(0, 1)
+// DefaultTheme is the theme used unless another is configured
+func DefaultTheme() *Theme {
+	return NewTheme(DarkPalette)
+}
 
-	// Visual mode selection - bright highlight
-	VisualSelectStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color("#264F78")).
-				Foreground(lipgloss.Color("#FFFFFF"))
-)
+// withAccent returns a copy of the theme with a different accent color
+func (t *Theme) withAccent(accent lipgloss.TerminalColor) *Theme {
+	p := t.Palette
+	if len(p.Indent) > 0 && p.Indent[0] == p.Accent {
+		p.Indent = append([]lipgloss.TerminalColor{accent}, p.Indent[1:]...)
+	}
+	p.Accent = accent
+	return NewTheme(p)
+}
 
-// IndentStyle returns a style for comment indentation
-func IndentStyle(depth int) lipgloss.Style {
-	colors := []lipgloss.Color{
-		orange,
-		lipgloss.Color("#4A9EFF"),
-		lipgloss.Color("#50C878"),
-		lipgloss.Color("#FFD700"),
-		lipgloss.Color("#FF69B4"),
-		lipgloss.Color("#9370DB"),
+// markup returns the styles for rendering articles and rich text
+func (t *Theme) markup() markup.Styles {
+	return markup.Styles{
+		Text:    t.CommentText,
+		Heading: t.SelectedTitle,
+		Code:    lipgloss.NewStyle().Foreground(t.Palette.Text).Background(t.Palette.StatusBar),
+		Link:    lipgloss.NewStyle().Foreground(t.Palette.Accent).Underline(true),
+		Quote:   t.Meta,
+		Muted:   t.Meta,
 	}
-	color := colors[depth%len(colors)]
-	return lipgloss.NewStyle().
-		Foreground(color).
-		Bold(true)
+}
+
+// Indent returns the style for thread lines at a comment depth
+func (t *Theme) Indent(depth int) lipgloss.Style {
+	return t.indent[depth%len(t.indent)]
 }
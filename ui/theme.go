@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+)
+
+// styles returns the theme to render with: the source's accented variant
+// when the theme follows the active source
+func (m Model) styles() *Theme {
+	if t, ok := m.sourceThemes[sourceKind(m.activeSource())]; ok {
+		return t
+	}
+	return m.theme
+}
+
+// sourceKind names the kind of a source, for its accent color
+func sourceKind(source api.Source) string {
+	switch source.(type) {
+	case *api.Client, *api.HNSearchSource:
+		return "hn"
+	case *api.LobstersClient:
+		return "lobsters"
+	case *api.RedditClient:
+		return "reddit"
+	case *api.LemmyClient:
+		return "lemmy"
+	case *api.RSSClient:
+		return "rss"
+	}
+	return ""
+}
+
+// accentThemes returns a variant of t for each source accent
+func accentThemes(t *Theme) map[string]*Theme {
+	themes := make(map[string]*Theme, len(sourceAccents))
+	for kind, accent := range sourceAccents {
+		themes[kind] = t.withAccent(accent)
+	}
+	return themes
+}
+
+// ConfigTheme builds the theme selected in the config, falling back to the
+// default theme for unknown names. NO_COLOR in the environment disables
+// colors regardless of the config. hasDarkBackground is only consulted
+// for themes based on auto.
+func ConfigTheme(cfg config.Config, hasDarkBackground func() bool) *Theme {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColorTheme()
+	}
+
+	name := cfg.Theme.Name
+	if user, ok := cfg.Themes[name]; ok {
+		return NewTheme(userPalette(user, hasDarkBackground))
+	}
+	if !slices.Contains(config.BuiltinThemes, name) {
+		return DefaultTheme()
+	}
+	return NewTheme(builtinPalette(name, hasDarkBackground))
+}
+
+// builtinPalette returns a built-in palette by theme name
+func builtinPalette(name string, hasDarkBackground func() bool) Palette {
+	switch name {
+	case "light":
+		return LightPalette
+	case "high-contrast":
+		return HighContrastPalette
+	case "auto", "":
+		if !hasDarkBackground() {
+			return LightPalette
+		}
+	}
+	return DarkPalette
+}
+
+// userPalette applies a user theme's colors to its base palette
+func userPalette(user config.Palette, hasDarkBackground func() bool) Palette {
+	p := builtinPalette(user.Base, hasDarkBackground)
+	for _, c := range []struct {
+		color string
+		dst   *lipgloss.TerminalColor
+	}{
+		{user.Accent, &p.Accent},
+		{user.AccentDim, &p.AccentDim},
+		{user.OnAccent, &p.OnAccent},
+		{user.Text, &p.Text},
+		{user.CommentText, &p.CommentText},
+		{user.Muted, &p.Muted},
+		{user.Subtle, &p.Subtle},
+		{user.StatusBar, &p.StatusBar},
+		{user.SelectBg, &p.SelectBg},
+		{user.SelectFg, &p.SelectFg},
+		{user.Error, &p.Error},
+	} {
+		if c.color != "" {
+			*c.dst = lipgloss.Color(strings.ToLower(c.color))
+		}
+	}
+	if len(user.Indent) > 0 {
+		p.Indent = nil
+		for _, color := range user.Indent {
+			p.Indent = append(p.Indent, lipgloss.Color(color))
+		}
+	}
+	return p
+}
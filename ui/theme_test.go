@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/JonathanWThom/feedme/api"
+	"github.com/JonathanWThom/feedme/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func dark() bool  { return true }
+func light() bool { return false }
+
+func TestConfigThemeBuiltins(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	cfg := config.Default()
+
+	if got := ConfigTheme(cfg, dark).Palette.Accent; got != DarkPalette.Accent {
+		t.Errorf("auto on dark background: accent %v, want dark palette", got)
+	}
+	if got := ConfigTheme(cfg, light).Palette.Accent; got != LightPalette.Accent {
+		t.Errorf("auto on light background: accent %v, want light palette", got)
+	}
+
+	cfg.Theme.Name = "high-contrast"
+	background := func() bool {
+		t.Error("background queried for a theme that is not auto")
+		return true
+	}
+	if got := ConfigTheme(cfg, background).Palette.Accent; got != HighContrastPalette.Accent {
+		t.Errorf("high-contrast accent %v", got)
+	}
+}
+
+func TestConfigThemeUserTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	cfg := config.Default()
+	cfg.Theme.Name = "paper"
+	cfg.Themes = map[string]config.Palette{
+		"paper": {Base: "light", Accent: "#0055AA", Indent: []string{"27"}},
+	}
+
+	theme := ConfigTheme(cfg, dark)
+	if theme.Palette.Accent != lipgloss.Color("#0055aa") {
+		t.Errorf("accent = %v, want #0055aa", theme.Palette.Accent)
+	}
+	if theme.Palette.Text != LightPalette.Text {
+		t.Errorf("text = %v, want the light base's", theme.Palette.Text)
+	}
+	if got := theme.Indent(3).GetForeground(); got != lipgloss.Color("27") {
+		t.Errorf("indent color = %v, want 27", got)
+	}
+}
+
+func TestConfigThemeNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	cfg := config.Default()
+	cfg.Theme.Name = "light"
+
+	theme := ConfigTheme(cfg, dark)
+	if _, ok := theme.Title.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("title has color %v under NO_COLOR", theme.Title.GetForeground())
+	}
+	if !theme.VisualSelect.GetReverse() {
+		t.Error("visual selection not shown in reverse video under NO_COLOR")
+	}
+}
+
+func TestSourceAccent(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SourceAccent = true
+	m := NewWithOptions(api.NewLobstersClient(), nil, opts)
+
+	if got := m.styles().Header.GetBackground(); got != sourceAccents["lobsters"] {
+		t.Errorf("lobsters header background = %v, want %v", got, sourceAccents["lobsters"])
+	}
+
+	m.view = CommentsView
+	m.commentSource = api.NewRedditClient("r/golang")
+	if got := m.styles().Header.GetBackground(); got != sourceAccents["reddit"] {
+		t.Errorf("header background reading reddit comments = %v, want %v", got, sourceAccents["reddit"])
+	}
+
+	m = NewWithOptions(api.NewLobstersClient(), nil, DefaultOptions())
+	if got := m.styles().Header.GetBackground(); got != DarkPalette.Accent {
+		t.Errorf("header background without source accents = %v, want %v", got, DarkPalette.Accent)
+	}
+}
@@ -1,13 +1,12 @@
 package ui
 
 import (
-	"strings"
-
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/JonathanWThom/feedme/api"
 )
 
 // Update handles messages
@@ -23,7 +22,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.viewport = viewport.New(msg.Width, msg.Height-4)
 		m.viewport.Style = lipgloss.NewStyle()
+		m.reader = viewport.New(msg.Width, msg.Height-4)
+		m.reader.Style = lipgloss.NewStyle()
 		m.help.Width = msg.Width
+		if m.view == CommentsView && m.comments != nil {
+			m.rebuildComments()
+			m.scrollToCursor()
+		}
+		if m.articleStory != nil {
+			m.renderArticle()
+		}
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -36,7 +44,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.loading = false
 		} else {
 			m.storyIDs = msg.ids
-			batchSize := min(30, len(msg.ids))
+			batchSize := min(m.batchSize, len(msg.ids))
 			return m, m.loadStories(msg.ids[:batchSize])
 		}
 
@@ -50,6 +58,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.stories = append(m.stories, s)
 				}
 			}
+			m.refreshShown()
+			m.continueStorySearch()
+			return m.maybeLoadNextBatch()
 		}
 
 	case commentsLoadedMsg:
@@ -58,12 +69,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.err = msg.err
 		} else {
 			m.comments = msg.comments
-			content := m.renderComments()
-			m.commentLines = strings.Split(content, "\n")
-			m.viewport.SetContent(content)
+			m.collapsed = make(map[*api.Comment]bool)
+			m.loadingMore = nil
+			m.statusMsg = ""
+			m.commentCursor = 0
+			m.commentSpans = nil
 			m.viewport.GotoTop()
+			m.markNewComments(msg.comments)
+			m.collapseMuted(msg.comments)
+			m.rebuildComments()
 		}
 
+	case moreCommentsLoadedMsg:
+		if m.view != CommentsView || msg.story != m.currentItem {
+			break
+		}
+		m.loadingMore = nil
+		if msg.err != nil {
+			m.statusMsg = "failed to load comments: " + msg.err.Error()
+			m.rebuildComments()
+			break
+		}
+		m.statusMsg = ""
+		m.markNewComments(msg.comments)
+		m.collapseMuted(msg.comments)
+		m.spliceComments(msg.more, msg.comments)
+
+	case articleLoadedMsg:
+		if m.view != ReaderView || msg.story != m.articleStory {
+			break
+		}
+		m.loading = false
+		m.article = msg.doc
+		m.articleErr = msg.err
+		m.renderArticle()
+		m.reader.GotoTop()
+
 	case updateCheckMsg:
 		if msg.info != nil && msg.info.HasUpdate() {
 			m.updateInfo = msg.info
@@ -79,6 +120,33 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleSourcePickerInput(msg)
 	}
 
+	// Status messages last until the next key press
+	m.statusMsg = ""
+
+	if m.editingSearch {
+		return m.handleSearchInput(msg)
+	}
+
+	if m.pickingLink {
+		return m.handleLinkPickerInput(msg)
+	}
+
+	if m.choosingExport {
+		return m.handleExportInput(msg)
+	}
+
+	if m.choosingMute {
+		return m.handleMuteInput(msg)
+	}
+
+	if m.view == SavedView && !key.Matches(msg, m.keys.Help) && !m.showHelp {
+		return m.handleSavedInput(msg)
+	}
+
+	if m.view == ReaderView && !key.Matches(msg, m.keys.Help) && !m.showHelp {
+		return m.handleReaderInput(msg)
+	}
+
 	if key.Matches(msg, m.keys.Help) {
 		m.showHelp = !m.showHelp
 		return m, nil
@@ -112,14 +180,39 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.handleEnd()
 
 	case key.Matches(msg, m.keys.Enter), key.Matches(msg, m.keys.Open):
+		if more := m.cursorPlaceholder(); more != nil {
+			return m.expandPlaceholder(more)
+		}
 		m.openCurrentURL()
 
 	case key.Matches(msg, m.keys.Comments):
 		return m.openComments()
 
+	case key.Matches(msg, m.keys.Reader):
+		return m.openReader()
+
+	case key.Matches(msg, m.keys.Links):
+		return m.openLinkPicker()
+
+	case key.Matches(msg, m.keys.Export):
+		return m.startExport()
+
 	case key.Matches(msg, m.keys.Back):
 		return m.handleBack()
 
+	case key.Matches(msg, m.keys.Collapse):
+		if more := m.cursorPlaceholder(); more != nil {
+			return m.expandPlaceholder(more)
+		}
+		if m.view == CommentsView && !m.visualMode {
+			m.toggleCollapse()
+		}
+
+	case key.Matches(msg, m.keys.CollapseAll):
+		if m.view == CommentsView && !m.visualMode {
+			m.toggleCollapseAll()
+		}
+
 	case key.Matches(msg, m.keys.Visual):
 		m.startVisualMode()
 
@@ -141,6 +234,35 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.SwitchSource):
 		return m.openSourcePicker()
+
+	case key.Matches(msg, m.keys.Bookmark):
+		m.toggleBookmark()
+
+	case key.Matches(msg, m.keys.Saved):
+		return m.openSaved()
+
+	case key.Matches(msg, m.keys.HideRead):
+		return m.toggleHideRead()
+
+	case key.Matches(msg, m.keys.Mute):
+		return m.startMute()
+
+	case key.Matches(msg, m.keys.ShowMuted):
+		return m.toggleShowMuted()
+
+	case key.Matches(msg, m.keys.Search):
+		return m.startSearch()
+
+	case key.Matches(msg, m.keys.NextMatch):
+		return m.nextMatch(1)
+
+	case key.Matches(msg, m.keys.PrevMatch):
+		return m.nextMatch(-1)
+
+	case key.Matches(msg, m.keys.NextNew):
+		if m.view == CommentsView && !m.visualMode {
+			m.jumpToNewComment()
+		}
 	}
 
 	return m, nil
@@ -1,12 +1,9 @@
 package ui
 
-import (
-	"strings"
+import "strings"
 
-	"github.com/atotto/clipboard"
-)
-
-// updateViewportWithHighlight re-renders the viewport with visual selection
+// updateViewportWithHighlight re-renders the viewport with the comment
+// cursor and visual selection
 func (m *Model) updateViewportWithHighlight() {
 	if len(m.commentLines) == 0 {
 		return
@@ -16,11 +13,23 @@ func (m *Model) updateViewportWithHighlight() {
 
 	var lines []string
 	start, end := m.normalizedSelection()
+	cursor, hasCursor := m.cursorSpan()
+	matches := m.lineMatches()
+	var current commentMatch
+	if len(m.commentMatches) > 0 {
+		current = m.commentMatches[m.matchIndex]
+	}
 
 	for i, line := range m.commentLines {
-		if m.visualMode && i >= start && i <= end {
-			lines = append(lines, VisualSelectStyle.Render(line))
-		} else {
+		if lineMatches, ok := matches[i]; ok {
+			line = highlightMatches(line, lineMatches, current)
+		}
+		switch {
+		case m.visualMode && i >= start && i <= end:
+			lines = append(lines, m.styles().VisualSelect.Render(line))
+		case !m.visualMode && hasCursor && i >= cursor.start && i <= cursor.end:
+			lines = append(lines, m.styles().CommentCursor.Render("▌")+strings.TrimPrefix(line, commentGutter))
+		default:
 			lines = append(lines, line)
 		}
 	}
@@ -43,7 +52,7 @@ func (m *Model) yankSelection() {
 	text := strings.Join(selected, "\n")
 	text = stripAnsi(text)
 
-	clipboard.WriteAll(text)
+	writeClipboard(text)
 }
 
 // normalizedSelection returns start/end with start <= end
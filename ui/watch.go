@@ -0,0 +1,8 @@
+package ui
+
+import "github.com/JonathanWThom/feedme/api"
+
+// isWatched reports whether a story's title matches the watchlist
+func (m Model) isWatched(story *api.Item) bool {
+	return m.watch.Match(story) != ""
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/JonathanWThom/feedme/api"
+)
+
+func TestWatchedStoriesPinned(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	opts := DefaultOptions()
+	opts.Watch = []string{"golang", "/\\bzig\\b/"}
+	m := NewWithOptions(api.NewClient(), nil, opts)
+	m = update(t, m, tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = update(t, m, storiesLoadedMsg{stories: []*api.Item{
+		{Key: "1", Title: "First"},
+		{Key: "2", Title: "Golang tips"},
+		{Key: "3", Title: "Zigzag"},
+		{Key: "4", Title: "Learning Zig"},
+	}})
+
+	if got := storyKeys(m.shown); len(got) != 4 || got[0] != "2" || got[1] != "4" || got[2] != "1" || got[3] != "3" {
+		t.Fatalf("shown = %v, want watched stories 2 and 4 first", got)
+	}
+
+	// Stories loaded later are pinned above the rest too
+	m = update(t, m, storiesLoadedMsg{stories: []*api.Item{{Key: "5", Title: "golang 2"}}})
+	if got := storyKeys(m.shown); got[2] != "5" || got[3] != "1" {
+		t.Errorf("shown after next batch = %v, want 5 pinned after 2 and 4", got)
+	}
+
+	if !m.isWatched(m.shown[1]) || m.isWatched(m.shown[3]) {
+		t.Error("isWatched should match titles against the watchlist")
+	}
+}